@@ -21,6 +21,7 @@ CREATE TABLE public.core_congregaciones (
   direccion text, -- Dirección física del Salón del Reino
   numero_congregacion text, -- Código identificador oficial
   zona_horaria text NOT NULL DEFAULT 'America/Argentina/Buenos_Aires'::text, -- Crítico para UI adaptativa
+  codigo_pais text NOT NULL DEFAULT 'AR'::text, -- ISO 3166-1 alfa-2: país por defecto para normalizar teléfonos
   region text CHECK (region = ANY (ARRAY['Asia'::text, 'África'::text, 'Europa'::text, 'América del Norte'::text, 'América Central'::text, 'América del Sur'::text, 'Oceanía'::text])),
   creado_at timestamp with time zone DEFAULT now(),
   CONSTRAINT core_congregaciones_pkey PRIMARY KEY (id)
@@ -48,7 +49,10 @@ CREATE TABLE public.core_personas (
   congregacion_id uuid REFERENCES public.core_congregaciones(id),
   apellido_nombre text NOT NULL, -- Formato 'Apellido, Nombre' para ordenamiento
   estado text CHECK (estado = ANY (ARRAY['ALTA'::text, 'BAJA'::text])), -- ALTA: Activo / BAJA: Inactivo o fallecido
-  contacto text, -- Teléfono o celular
+  contacto text, -- Teléfono o celular (tal como lo escribió el miembro)
+  telefono_e164 text, -- Contacto normalizado a E.164 (ej: +5491112345678); NULL si no es válido
+  canal_preferido text NOT NULL DEFAULT 'email'::text CHECK (canal_preferido = ANY (ARRAY['email'::text, 'sms'::text, 'whatsapp'::text])), -- Medio para PINs y recuperación
+  idioma text NOT NULL DEFAULT 'es'::text, -- Idioma de los correos (mismos códigos que el frontend)
   email text,
   grupo integer, -- Número de grupo de servicio
   situacion_1 text, -- Etiquetas de cargo (ej: 'Anciano', 'Siervo')
@@ -66,6 +70,9 @@ CREATE TABLE public.core_personas (
   CONSTRAINT core_personas_pkey PRIMARY KEY (id)
 );
 
+-- Recuperación por teléfono: búsqueda por igualdad sobre el número normalizado
+CREATE INDEX core_personas_telefono_e164_idx ON public.core_personas (telefono_e164) WHERE telefono_e164 IS NOT NULL;
+
 -- Usuarios con acceso web (Vinculados a Supabase Auth y core_personas)
 CREATE TABLE public.core_usuarios (
   id uuid NOT NULL REFERENCES auth.users(id), -- Vínculo con el motor de Auth de Supabase
@@ -76,11 +83,73 @@ CREATE TABLE public.core_usuarios (
   estado_cuenta text DEFAULT 'activa'::text CHECK (estado_cuenta = ANY (ARRAY['activa'::text, 'suspendida'::text])),
   password_hash text,
   clave_temporal text, -- Clave generada por el admin para el primer ingreso
-  security_updated_at timestamp with time zone DEFAULT now(),
+  password_changed_at timestamp with time zone DEFAULT now(), -- Llaves JWT emitidas antes de esta fecha son rechazadas
+  security_updated_at timestamp with time zone DEFAULT now(), -- Último evento de seguridad (suspensión, cambio de clave)
   creado_at timestamp with time zone DEFAULT now(),
   CONSTRAINT core_usuarios_pkey PRIMARY KEY (id)
 );
 
+-- Sesiones activas por dispositivo (una fila por login exitoso)
+CREATE TABLE public.core_sesiones (
+  id uuid NOT NULL DEFAULT gen_random_uuid(), -- Viaja en el claim 'sid' de ambos JWT
+  persona_id integer NOT NULL REFERENCES public.core_personas(id),
+  usuario_id uuid REFERENCES public.core_usuarios(id), -- NULL si la persona no tiene cuenta en core_usuarios
+  user_agent text, -- Navegador / dispositivo reportado en el login
+  ip text, -- Última IP conocida
+  creado_at timestamp with time zone DEFAULT now(),
+  ultimo_uso_at timestamp with time zone DEFAULT now(), -- Se actualiza en cada /api/refresh
+  expira_at timestamp with time zone NOT NULL, -- Coincide con la expiración del refresh_token
+  revocada_at timestamp with time zone, -- NULL mientras la sesión siga activa
+  revocada_por integer REFERENCES public.core_personas(id), -- Quién cerró la sesión (el propio usuario o un admin)
+  CONSTRAINT core_sesiones_pkey PRIMARY KEY (id)
+);
+CREATE INDEX core_sesiones_persona_activa_idx ON public.core_sesiones (persona_id) WHERE revocada_at IS NULL;
+
+-- Rastro de auditoría de acciones sobre cuentas (suspensiones, reactivaciones)
+CREATE TABLE public.core_auditoria (
+  id bigint GENERATED ALWAYS AS IDENTITY,
+  actor_persona_id integer REFERENCES public.core_personas(id), -- Quién ejecutó la acción
+  accion text NOT NULL, -- Ej: 'SUSPENSION_CUENTA', 'REACTIVACION_CUENTA'
+  persona_id integer REFERENCES public.core_personas(id), -- Miembro afectado
+  congregacion_id uuid REFERENCES public.core_congregaciones(id),
+  detalle text, -- Motivo indicado por el administrador
+  creado_at timestamp with time zone DEFAULT now(),
+  CONSTRAINT core_auditoria_pkey PRIMARY KEY (id)
+);
+CREATE INDEX core_auditoria_persona_idx ON public.core_auditoria (persona_id, creado_at DESC);
+
+-- Bandeja de salida de correos (outbox): se escribe en la misma transacción que el cambio de negocio
+CREATE TABLE public.core_email_outbox (
+  id bigint GENERATED ALWAYS AS IDENTITY,
+  destinatario text NOT NULL,
+  asunto text NOT NULL,
+  html text,
+  texto text, -- Alternativa en texto plano (opcional)
+  sensible boolean NOT NULL DEFAULT false, -- PINs: asunto y cuerpo se borran al enviarse o fallar
+  estado text NOT NULL DEFAULT 'pendiente'::text CHECK (estado = ANY (ARRAY['pendiente'::text, 'enviado'::text, 'fallido'::text])), -- 'fallido' = dead-letter
+  intentos integer NOT NULL DEFAULT 0,
+  proximo_intento_at timestamp with time zone NOT NULL DEFAULT now(), -- Espera exponencial entre reintentos
+  ultimo_error text,
+  creado_at timestamp with time zone DEFAULT now(),
+  enviado_at timestamp with time zone,
+  difusion_id bigint, -- NULL: correo transaccional (PIN, avisos de cuenta)
+  persona_id integer REFERENCES public.core_personas(id), -- Destinatario (solo en difusiones)
+  baja_url text, -- Enlace firmado para List-Unsubscribe (vacío en transaccionales)
+  CONSTRAINT core_email_outbox_pkey PRIMARY KEY (id)
+);
+CREATE INDEX core_email_outbox_pendiente_idx ON public.core_email_outbox (proximo_intento_at) WHERE estado = 'pendiente';
+CREATE INDEX core_email_outbox_difusion_idx ON public.core_email_outbox (difusion_id, estado) WHERE difusion_id IS NOT NULL;
+
+-- Preferencias de notificación: solo se guardan los cambios (sin fila = activo)
+CREATE TABLE public.core_preferencias_notificacion (
+  persona_id integer NOT NULL REFERENCES public.core_personas(id),
+  categoria text NOT NULL CHECK (categoria = ANY (ARRAY['seguridad'::text, 'anuncios'::text, 'literatura'::text])),
+  canal text NOT NULL CHECK (canal = ANY (ARRAY['email'::text, 'push'::text, 'sms'::text, 'whatsapp'::text])),
+  activo boolean NOT NULL DEFAULT true,
+  updated_at timestamp with time zone DEFAULT now(),
+  CONSTRAINT core_preferencias_notificacion_pkey PRIMARY KEY (persona_id, categoria, canal)
+);
+
 -- Gestión de PIN para recuperación y seguridad
 CREATE TABLE public.core_verificaciones (
   id integer NOT NULL DEFAULT nextval('core_verificaciones_id_seq'::regclass),
@@ -98,7 +167,7 @@ CREATE TABLE public.core_verificaciones (
 -- ----------------------------------------------------------
 
 CREATE TABLE public.core_modulos (
-  id text PRIMARY KEY, -- Identificador único (ej: 'pubs', 'reuniones')
+  id text PRIMARY KEY, -- Identificador único (ej: 'pubs', 'reuniones', 'informes')
   nombre text NOT NULL,
   descripcion text
 );
@@ -128,11 +197,58 @@ CREATE TABLE public.core_anuncios (
 CREATE TABLE public.core_seguridad_info (
   id integer NOT NULL DEFAULT nextval('core_seguridad_info_id_seq'::regclass),
   contenido text, -- Título o resumen breve
-  descripcion_larga text, -- Contenido extendido en formato HTML o texto plano
+  descripcion_larga text, -- Contenido extendido en Markdown (tal como lo escribió el admin)
+  descripcion_html text, -- Markdown renderizado y saneado (internal/markdown)
   updated_at timestamp with time zone DEFAULT now(),
+  estado text NOT NULL DEFAULT 'publicado'::text CHECK (estado = ANY (ARRAY['borrador'::text, 'publicado'::text])),
+  version integer NOT NULL DEFAULT 1, -- Sube con cada edición
+  creado_por integer REFERENCES public.core_personas(id),
+  congregacion_id uuid REFERENCES public.core_congregaciones(id), -- Congregación del admin que lo creó; NULL en boletines anteriores
+  publicado_at timestamp with time zone, -- NULL mientras es borrador
+  eliminado_at timestamp with time zone, -- Baja lógica
+  eliminado_por integer REFERENCES public.core_personas(id),
   CONSTRAINT core_seguridad_info_pkey PRIMARY KEY (id)
 );
 
+-- Historial de versiones de cada boletín (una fila por guardado)
+CREATE TABLE public.core_seguridad_info_historial (
+  id bigint GENERATED ALWAYS AS IDENTITY,
+  boletin_id integer NOT NULL REFERENCES public.core_seguridad_info(id),
+  version integer NOT NULL,
+  contenido text,
+  descripcion_larga text,
+  descripcion_html text,
+  estado text NOT NULL,
+  editado_por integer REFERENCES public.core_personas(id),
+  editado_at timestamp with time zone DEFAULT now(),
+  CONSTRAINT core_seguridad_info_historial_pkey PRIMARY KEY (id)
+);
+CREATE INDEX idx_seguridad_historial_boletin ON public.core_seguridad_info_historial (boletin_id, version);
+
+-- Acuses de lectura de cada boletín (una fila por persona)
+CREATE TABLE public.core_seguridad_acuses (
+  boletin_id integer NOT NULL REFERENCES public.core_seguridad_info(id),
+  persona_id integer NOT NULL REFERENCES public.core_personas(id),
+  leido_at timestamp with time zone, -- Primera vez que lo abrió
+  confirmado_at timestamp with time zone, -- Pulsó "Entendido"
+  recordado_at timestamp with time zone, -- Último recordatorio enviado
+  CONSTRAINT core_seguridad_acuses_pkey PRIMARY KEY (boletin_id, persona_id)
+);
+
+-- Difusión masiva de un boletín: un correo por destinatario en core_email_outbox
+CREATE TABLE public.core_difusiones (
+  id bigint GENERATED ALWAYS AS IDENTITY,
+  boletin_id integer NOT NULL UNIQUE REFERENCES public.core_seguridad_info(id),
+  titulo text NOT NULL,
+  total integer NOT NULL DEFAULT 0, -- Destinatarios al momento de publicar
+  estado text NOT NULL DEFAULT 'en_curso'::text CHECK (estado = ANY (ARRAY['en_curso'::text, 'completada'::text])),
+  creado_por integer REFERENCES public.core_personas(id),
+  creado_at timestamp with time zone DEFAULT now(),
+  completado_at timestamp with time zone, -- Sin correos pendientes (enviados o en dead-letter)
+  CONSTRAINT core_difusiones_pkey PRIMARY KEY (id)
+);
+ALTER TABLE public.core_email_outbox ADD CONSTRAINT core_email_outbox_difusion_fkey FOREIGN KEY (difusion_id) REFERENCES public.core_difusiones(id);
+
 -- ----------------------------------------------------------
 -- 4. PUBLICACIONES (LITERATURA)
 -- ----------------------------------------------------------
@@ -156,7 +272,8 @@ CREATE TABLE public.pub_stock_local (
   publicacion_id text REFERENCES public.pub_catalogo(id),
   cantidad_disponible integer DEFAULT 0,
   estante_ubicacion text, -- Lugar físico en el mostrador
-  CONSTRAINT pub_stock_local_pkey PRIMARY KEY (id)
+  CONSTRAINT pub_stock_local_pkey PRIMARY KEY (id),
+  CONSTRAINT pub_stock_local_publicacion_key UNIQUE (congregacion_id, publicacion_id) -- Una fila por publicación: la llegada de stock hace upsert
 );
 
 -- Registro de pedidos realizados por hermanos
@@ -166,9 +283,10 @@ CREATE TABLE public.pub_pedidos (
   persona_id integer REFERENCES public.core_personas(id),
   publicacion_id text REFERENCES public.pub_catalogo(id),
   cantidad integer,
-  estado text CHECK (estado = ANY (ARRAY['pendiente'::text, 'sin stock'::text, 'cancelado'::text, 'entregado'::text])),
+  estado text CHECK (estado = ANY (ARRAY['pendiente'::text, 'sin stock'::text, 'listo'::text, 'cancelado'::text, 'entregado'::text])), -- 'listo': apartado, se avisó al hermano
   fecha_pedido date,
   notas character varying,
+  listo_at timestamp with time zone, -- Momento en que se apartó (llegada de stock o a mano)
   CONSTRAINT pub_pedidos_pkey PRIMARY KEY (id)
 );
 
@@ -194,4 +312,68 @@ CREATE TABLE public.pub_suscripciones (
   fecha_inicio date NOT NULL,
   activa boolean DEFAULT true,
   CONSTRAINT pub_suscripciones_pkey PRIMARY KEY (id)
-);
\ No newline at end of file
+);
+-- ----------------------------------------------------------
+-- 5. REUNIONES
+-- ----------------------------------------------------------
+
+-- Reuniones de la congregación: mientras están abiertas, los miembros conectados
+-- que entran a la sala en tiempo real cuentan como presentes
+CREATE TABLE public.reu_reuniones (
+  id integer GENERATED ALWAYS AS IDENTITY,
+  congregacion_id uuid NOT NULL REFERENCES public.core_congregaciones(id),
+  tipo text NOT NULL CHECK (tipo = ANY (ARRAY['entre_semana'::text, 'fin_de_semana'::text])),
+  iniciada_at timestamp with time zone NOT NULL DEFAULT now(),
+  iniciada_por integer REFERENCES public.core_personas(id),
+  finalizada_at timestamp with time zone, -- NULL: en curso
+  finalizada_por integer REFERENCES public.core_personas(id),
+  presentes integer, -- Conectados al finalizar (foto de la presencia en vivo)
+  CONSTRAINT reu_reuniones_pkey PRIMARY KEY (id)
+);
+
+-- Una sola reunión abierta por congregación
+CREATE UNIQUE INDEX reu_reuniones_abierta_idx ON public.reu_reuniones (congregacion_id) WHERE finalizada_at IS NULL;
+
+-- Presentes registrados al finalizar cada reunión
+CREATE TABLE public.reu_presentes (
+  reunion_id integer NOT NULL REFERENCES public.reu_reuniones(id),
+  persona_id integer NOT NULL REFERENCES public.core_personas(id),
+  CONSTRAINT reu_presentes_pkey PRIMARY KEY (reunion_id, persona_id)
+);
+
+-- Concurrencia que informa el secretario: una fila por congregación, fecha y tipo
+CREATE TABLE public.reu_asistencia (
+  id integer GENERATED ALWAYS AS IDENTITY,
+  congregacion_id uuid NOT NULL REFERENCES public.core_congregaciones(id),
+  fecha date NOT NULL,
+  tipo text NOT NULL CHECK (tipo = ANY (ARRAY['entre_semana'::text, 'fin_de_semana'::text])),
+  presencial integer NOT NULL DEFAULT 0 CHECK (presencial >= 0), -- En el Salón del Reino
+  video integer NOT NULL DEFAULT 0 CHECK (video >= 0), -- Conectados por videoconferencia
+  registrado_por integer REFERENCES public.core_personas(id), -- Última persona que la cargó o corrigió
+  updated_at timestamp with time zone NOT NULL DEFAULT now(),
+  CONSTRAINT reu_asistencia_pkey PRIMARY KEY (id),
+  CONSTRAINT reu_asistencia_reunion_key UNIQUE (congregacion_id, fecha, tipo)
+);
+
+-- ----------------------------------------------------------
+-- 6. INFORMES DE SERVICIO
+-- ----------------------------------------------------------
+
+-- Informe mensual de cada miembro; grupo y categoría quedan como estaban al entregarlo
+CREATE TABLE public.serv_informes (
+  id integer GENERATED ALWAYS AS IDENTITY,
+  congregacion_id uuid NOT NULL REFERENCES public.core_congregaciones(id),
+  persona_id integer NOT NULL REFERENCES public.core_personas(id),
+  mes date NOT NULL CHECK (mes = date_trunc('month', mes)::date), -- Primer día del mes informado
+  grupo integer, -- core_personas.grupo al entregar
+  categoria text NOT NULL CHECK (categoria = ANY (ARRAY['publicador'::text, 'precursor_auxiliar'::text, 'precursor_regular'::text, 'precursor_especial'::text])),
+  participo boolean NOT NULL DEFAULT false,
+  estudios_biblicos integer NOT NULL DEFAULT 0 CHECK (estudios_biblicos >= 0),
+  horas integer CHECK (horas >= 0), -- Solo precursores
+  comentarios text,
+  enviado_at timestamp with time zone NOT NULL DEFAULT now(),
+  updated_at timestamp with time zone NOT NULL DEFAULT now(),
+  CONSTRAINT serv_informes_pkey PRIMARY KEY (id),
+  CONSTRAINT serv_informes_persona_mes_key UNIQUE (persona_id, mes)
+);
+CREATE INDEX serv_informes_congregacion_mes_idx ON public.serv_informes (congregacion_id, mes);
@@ -0,0 +1,87 @@
+/**
+ * ARCHIVO: main.go
+ * UBICACIÓN: Backend/cmd/backfill-telefonos/main.go
+ * DESCRIPCIÓN: Comando de una sola ejecución que completa core_personas.telefono_e164
+ * a partir del campo libre 'contacto', usando el país por defecto de cada congregación.
+ * Es idempotente: puede volver a ejecutarse sin efectos secundarios.
+ *
+ * USO: go run ./cmd/backfill-telefonos [-dry-run] [-lote 500]
+ */
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gestion-congregacion/backend/internal/repository"
+	"gestion-congregacion/backend/internal/telefono"
+
+	"github.com/joho/godotenv"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func main() {
+	dryRun := flag.Bool("dry-run", false, "Solo informa los cambios, sin escribir en la base")
+	lote := flag.Int("lote", 500, "Cantidad de personas procesadas por consulta")
+	flag.Parse()
+
+	godotenv.Load()
+
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=require TimeZone=UTC",
+		os.Getenv("DB_HOST"),
+		os.Getenv("DB_USER"),
+		os.Getenv("DB_PASSWORD"),
+		os.Getenv("DB_NAME"),
+		os.Getenv("DB_PORT"))
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		PrepareStmt: false, // Requerido por el Pooler de Supabase
+		Logger:      logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		log.Fatal("❌ Error DB:", err)
+	}
+	repo := repository.NewRepository(db)
+
+	var normalizados, invalidos, ultimoID int
+	for {
+		filas, err := repo.ListContactosParaNormalizar(ultimoID, *lote)
+		if err != nil {
+			log.Fatal("❌ Error leyendo contactos:", err)
+		}
+		if len(filas) == 0 {
+			break
+		}
+
+		for _, f := range filas {
+			ultimoID = f.ID
+			pais := f.CodigoPais
+			if pais == "" {
+				pais = telefono.PaisRecuperacion()
+			}
+
+			e164, err := telefono.Normalizar(f.Contacto, pais)
+			if err != nil {
+				// Se deja en NULL: el miembro deberá corregirlo desde su perfil
+				invalidos++
+				log.Printf("⚠️ Persona %d: contacto %q no normalizable (%v)", f.ID, f.Contacto, err)
+			} else {
+				normalizados++
+			}
+
+			if *dryRun {
+				continue
+			}
+			if err := repo.SetTelefonoE164(f.ID, e164); err != nil {
+				log.Fatalf("❌ Error guardando persona %d: %v", f.ID, err)
+			}
+		}
+	}
+
+	fmt.Printf("✅ Backfill terminado: %d normalizados, %d sin formato válido (dry-run: %v)\n", normalizados, invalidos, *dryRun)
+}
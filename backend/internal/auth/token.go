@@ -3,41 +3,180 @@
  * UBICACIÓN: Backend/internal/auth/token.go
  * DESCRIPCIÓN: Utilidades para la generación y validación de JSON Web Tokens (JWT).
  * Proporciona seguridad en las sesiones mediante firmas digitales.
+ * Las llaves usan claims tipados (typ, iss, aud, persona_id, congregacion_id),
+ * algoritmo fijo HS256 y un llavero identificado por 'kid' para rotar JWT_SECRET.
  */
 
 package auth
 
 import (
+	"errors"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// GenerarAccessToken crea una llave que dura solo 15 minutos (Seguridad Proactiva)
-func GenerarAccessToken(usuarioID string) (string, error) {
-	secret := []byte(os.Getenv("JWT_SECRET"))
-	claims := jwt.MapClaims{
-		"sub": usuarioID,
-		"exp": time.Now().Add(time.Minute * 15).Unix(), // 15 minutos
-		"iat": time.Now().Unix(),
+// Duraciones de las llaves (compartidas con las cookies y la tabla core_sesiones)
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// Tipos de llave: una llave de refresco nunca sirve como auth_token y viceversa
+const (
+	TipoAccess  = "access"
+	TipoRefresh = "refresh"
+)
+
+// Emisor y audiencia esperados en todas las llaves
+const (
+	Issuer   = "gestion-congregacion"
+	Audience = "gestion-congregacion-api"
+)
+
+// kidPorDefecto identifica a JWT_SECRET cuando no se define JWT_KEY_ID
+const kidPorDefecto = "principal"
+
+var (
+	ErrTokenInvalido  = errors.New("llave inválida")
+	ErrTipoIncorrecto = errors.New("tipo de llave incorrecto")
+	ErrKidDesconocido = errors.New("llave firmada con un 'kid' desconocido")
+)
+
+// Claims: Contenido tipado de nuestras llaves
+type Claims struct {
+	Tipo           string `json:"typ"`
+	SesionID       string `json:"sid"`
+	PersonaID      int    `json:"persona_id"`
+	CongregacionID string `json:"congregacion_id,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// Identidad: Datos del usuario que viajan dentro de la llave
+type Identidad struct {
+	UsuarioID      string
+	SesionID       string
+	PersonaID      int
+	CongregacionID string
+}
+
+// llavero devuelve el 'kid' activo y todas las llaves aceptadas.
+// JWT_SECRET (identificada por JWT_KEY_ID) firma; JWT_PREVIOUS_KEYS ("kid:secreto,kid:secreto")
+// solo valida, permitiendo rotar el secreto sin cerrar la sesión de todos.
+func llavero() (string, map[string][]byte) {
+	activo := os.Getenv("JWT_KEY_ID")
+	if activo == "" {
+		activo = kidPorDefecto
+	}
+
+	llaves := map[string][]byte{}
+	for _, par := range strings.Split(os.Getenv("JWT_PREVIOUS_KEYS"), ",") {
+		kid, secreto, ok := strings.Cut(strings.TrimSpace(par), ":")
+		if ok && kid != "" && secreto != "" {
+			llaves[kid] = []byte(secreto)
+		}
+	}
+	llaves[activo] = []byte(os.Getenv("JWT_SECRET"))
+	return activo, llaves
+}
+
+// Secretos devuelve todos los secretos del llavero (el activo y los anteriores)
+func Secretos() [][]byte {
+	_, llaves := llavero()
+	lista := make([][]byte, 0, len(llaves))
+	for _, s := range llaves {
+		if len(s) > 0 {
+			lista = append(lista, s)
+		}
 	}
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+	return lista
+}
+
+// firmar emite una llave del tipo indicado con la llave activa del llavero
+func firmar(id Identidad, tipo string, ttl time.Duration) (string, error) {
+	kid, llaves := llavero()
+	if len(llaves[kid]) == 0 {
+		return "", errors.New("JWT_SECRET no definido")
+	}
+
+	now := time.Now()
+	claims := Claims{
+		Tipo:           tipo,
+		SesionID:       id.SesionID,
+		PersonaID:      id.PersonaID,
+		CongregacionID: id.CongregacionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   id.UsuarioID,
+			Issuer:    Issuer,
+			Audience:  jwt.ClaimStrings{Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	token.Header["kid"] = kid
+	return token.SignedString(llaves[kid])
+}
+
+// GenerarAccessToken crea una llave que dura solo 15 minutos (Seguridad Proactiva).
+// 'sid' vincula la llave con su fila en core_sesiones para poder revocarla.
+func GenerarAccessToken(id Identidad) (string, error) {
+	return firmar(id, TipoAccess, AccessTokenTTL)
 }
 
 // GenerarRefreshToken crea una llave larga para renovar la corta (7 días)
-func GenerarRefreshToken(usuarioID string) (string, error) {
-	secret := []byte(os.Getenv("JWT_SECRET"))
-	claims := jwt.MapClaims{
-		"sub": usuarioID,
-		"exp": time.Now().Add(time.Hour * 24 * 7).Unix(), // 7 días
+func GenerarRefreshToken(id Identidad) (string, error) {
+	return firmar(id, TipoRefresh, RefreshTokenTTL)
+}
+
+// validar verifica firma (solo HS256), kid, emisor, audiencia, expiración y tipo
+func validar(tokenString, tipo string) (*Claims, error) {
+	_, llaves := llavero()
+
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		kid, _ := token.Header["kid"].(string)
+		secreto, ok := llaves[kid]
+		if !ok || len(secreto) == 0 {
+			return nil, ErrKidDesconocido
+		}
+		return secreto, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(Issuer),
+		jwt.WithAudience(Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+	)
+	if err != nil || !token.Valid {
+		return nil, ErrTokenInvalido
 	}
-	return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(secret)
+
+	if claims.Tipo != tipo {
+		return nil, ErrTipoIncorrecto
+	}
+	return claims, nil
 }
 
-func ValidarJWT(tokenString string) (*jwt.Token, error) {
-	secret := []byte(os.Getenv("JWT_SECRET"))
-	return jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-		return secret, nil
-	})
+// ValidarAccessToken acepta únicamente llaves de acceso (cookie auth_token)
+func ValidarAccessToken(tokenString string) (*Claims, error) {
+	return validar(tokenString, TipoAccess)
+}
+
+// ValidarRefreshToken acepta únicamente llaves de refresco (cookie refresh_token)
+func ValidarRefreshToken(tokenString string) (*Claims, error) {
+	return validar(tokenString, TipoRefresh)
+}
+
+// Identidad reconstruye los datos del usuario a partir de una llave ya validada
+func (c *Claims) Identidad() Identidad {
+	return Identidad{
+		UsuarioID:      c.Subject,
+		SesionID:       c.SesionID,
+		PersonaID:      c.PersonaID,
+		CongregacionID: c.CongregacionID,
+	}
 }
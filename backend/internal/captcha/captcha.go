@@ -0,0 +1,218 @@
+/**
+ * ARCHIVO: captcha.go
+ * UBICACIÓN: internal/captcha/captcha.go
+ * DESCRIPCIÓN: Verificadores de CAPTCHA intercambiables (Turnstile, hCaptcha y un
+ * verificador falso para pruebas y desarrollo local). El servicio solo conoce la
+ * interfaz Verifier, por lo que el login puede probarse sin salir a Internet.
+ */
+
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// URLs oficiales de verificación (sobrescribibles con CAPTCHA_VERIFY_URL)
+const (
+	TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	HCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+)
+
+// TokenDePrueba es el token que emiten las claves de prueba de Cloudflare; el verificador falso lo acepta
+const TokenDePrueba = "XXXX.DUMMY.TOKEN.XXXX"
+
+var (
+	ErrTokenVacio       = errors.New("CAPTCHA vacío")
+	ErrSinSecreto       = errors.New("secreto de CAPTCHA no configurado")
+	ErrRechazado        = errors.New("CAPTCHA rechazado por el proveedor")
+	ErrHostnameInvalido = errors.New("CAPTCHA resuelto en un dominio no permitido")
+)
+
+// Verifier valida un token de CAPTCHA resuelto por el usuario
+type Verifier interface {
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// siteVerify implementa el protocolo común de Turnstile y hCaptcha (POST secret/response/remoteip)
+type siteVerify struct {
+	nombre    string
+	secret    string
+	verifyURL string
+	hostnames []string // Si no está vacío, el hostname devuelto debe estar en la lista
+	client    *http.Client
+}
+
+// Turnstile: Verificador de Cloudflare Turnstile
+type Turnstile struct{ siteVerify }
+
+// HCaptcha: Verificador de hCaptcha
+type HCaptcha struct{ siteVerify }
+
+// NewTurnstile crea el verificador de Cloudflare. verifyURL vacío usa la URL oficial.
+func NewTurnstile(secret, verifyURL string, hostnames []string) *Turnstile {
+	if verifyURL == "" {
+		verifyURL = TurnstileVerifyURL
+	}
+	return &Turnstile{newSiteVerify("Cloudflare", secret, verifyURL, hostnames)}
+}
+
+// NewHCaptcha crea el verificador de hCaptcha. verifyURL vacío usa la URL oficial.
+func NewHCaptcha(secret, verifyURL string, hostnames []string) *HCaptcha {
+	if verifyURL == "" {
+		verifyURL = HCaptchaVerifyURL
+	}
+	return &HCaptcha{newSiteVerify("hCaptcha", secret, verifyURL, hostnames)}
+}
+
+func newSiteVerify(nombre, secret, verifyURL string, hostnames []string) siteVerify {
+	return siteVerify{
+		nombre:    nombre,
+		secret:    secret,
+		verifyURL: verifyURL,
+		hostnames: hostnames,
+		client:    &http.Client{Timeout: 5 * time.Second},
+	}
+}
+
+// Verify envía el token al proveedor junto con la IP del usuario y valida el hostname
+func (v *siteVerify) Verify(ctx context.Context, token, remoteIP string) error {
+	if v.secret == "" {
+		log.Printf("⚠️  ALERTA SEGURIDAD: secreto de %s no configurado", v.nombre)
+		return ErrSinSecreto
+	}
+	if token == "" {
+		return ErrTokenVacio
+	}
+
+	// Usamos url.Values para codificar correctamente los parámetros
+	data := url.Values{}
+	data.Set("secret", v.secret)
+	data.Set("response", token)
+	if remoteIP != "" {
+		data.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		log.Printf("❌ ERROR de red al validar con %s: %v", v.nombre, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Success    bool     `json:"success"`
+		ErrorCodes []string `json:"error-codes"`
+		Hostname   string   `json:"hostname"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		log.Printf("❌ ERROR al leer respuesta de %s: %v", v.nombre, err)
+		return err
+	}
+
+	if !result.Success {
+		log.Printf("🚫 Captcha RECHAZADO por %s. Errores: %v | Hostname: %s", v.nombre, result.ErrorCodes, result.Hostname)
+		return fmt.Errorf("%w: %v", ErrRechazado, result.ErrorCodes)
+	}
+
+	if !v.hostnamePermitido(result.Hostname) {
+		log.Printf("🚫 Captcha RECHAZADO: hostname '%s' no permitido", result.Hostname)
+		return ErrHostnameInvalido
+	}
+
+	return nil
+}
+
+func (v *siteVerify) hostnamePermitido(hostname string) bool {
+	if len(v.hostnames) == 0 {
+		return true
+	}
+	for _, h := range v.hostnames {
+		if strings.EqualFold(h, hostname) {
+			return true
+		}
+	}
+	return false
+}
+
+// Llamada registra una verificación recibida por el verificador falso
+type Llamada struct {
+	Token    string
+	RemoteIP string
+}
+
+// Fake: Verificador local sin red. Acepta únicamente el token configurado.
+type Fake struct {
+	TokenValido string
+
+	mu       sync.Mutex
+	llamadas []Llamada
+}
+
+// NewFake crea un verificador falso; tokenValido vacío acepta TokenDePrueba
+func NewFake(tokenValido string) *Fake {
+	if tokenValido == "" {
+		tokenValido = TokenDePrueba
+	}
+	return &Fake{TokenValido: tokenValido}
+}
+
+// Verify registra la llamada y compara el token con el esperado
+func (f *Fake) Verify(_ context.Context, token, remoteIP string) error {
+	f.mu.Lock()
+	f.llamadas = append(f.llamadas, Llamada{Token: token, RemoteIP: remoteIP})
+	f.mu.Unlock()
+
+	if token == "" {
+		return ErrTokenVacio
+	}
+	if token != f.TokenValido {
+		return ErrRechazado
+	}
+	return nil
+}
+
+// Llamadas devuelve una copia de las verificaciones recibidas
+func (f *Fake) Llamadas() []Llamada {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Llamada(nil), f.llamadas...)
+}
+
+// NewFromEnv elige el verificador según CAPTCHA_PROVIDER (turnstile por defecto, hcaptcha o fake).
+// CAPTCHA_VERIFY_URL sobrescribe la URL y CAPTCHA_ALLOWED_HOSTNAMES ("a.com,b.com") activa el control de dominio.
+func NewFromEnv() Verifier {
+	verifyURL := os.Getenv("CAPTCHA_VERIFY_URL")
+
+	var hostnames []string
+	for _, h := range strings.Split(os.Getenv("CAPTCHA_ALLOWED_HOSTNAMES"), ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			hostnames = append(hostnames, h)
+		}
+	}
+
+	switch strings.ToLower(os.Getenv("CAPTCHA_PROVIDER")) {
+	case "hcaptcha":
+		return NewHCaptcha(os.Getenv("HCAPTCHA_SECRET_KEY"), verifyURL, hostnames)
+	case "fake":
+		log.Println("⚠️  CAPTCHA en modo FAKE: usar solo en desarrollo local")
+		return NewFake(os.Getenv("CAPTCHA_FAKE_TOKEN"))
+	default:
+		return NewTurnstile(os.Getenv("TURNSTILE_SECRET_KEY"), verifyURL, hostnames)
+	}
+}
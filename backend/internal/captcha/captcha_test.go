@@ -0,0 +1,82 @@
+/**
+ * ARCHIVO: captcha_test.go
+ * UBICACIÓN: backend/internal/captcha/captcha_test.go
+ * DESCRIPCIÓN: Pruebas de los verificadores de CAPTCHA contra un servidor local (sin Internet).
+ */
+
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// servidorSiteVerify simula el endpoint siteverify y registra los parámetros recibidos
+func servidorSiteVerify(t *testing.T, hostname string, recibido *map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		*recibido = map[string]string{
+			"secret":   r.PostForm.Get("secret"),
+			"response": r.PostForm.Get("response"),
+			"remoteip": r.PostForm.Get("remoteip"),
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"success":  r.PostForm.Get("response") == "token-ok",
+			"hostname": hostname,
+		})
+	}))
+}
+
+func TestTurnstileEnviaRemoteIP(t *testing.T) {
+	var recibido map[string]string
+	srv := servidorSiteVerify(t, "app.example.org", &recibido)
+	defer srv.Close()
+
+	v := NewTurnstile("secreto", srv.URL, nil)
+	if err := v.Verify(context.Background(), "token-ok", "203.0.113.7"); err != nil {
+		t.Fatalf("FALLO: Token válido rechazado: %v", err)
+	}
+	if recibido["remoteip"] != "203.0.113.7" || recibido["secret"] != "secreto" {
+		t.Errorf("FALLO DE CONTRATO: Parámetros enviados al proveedor: %v", recibido)
+	}
+
+	if err := v.Verify(context.Background(), "token-malo", "203.0.113.7"); !errors.Is(err, ErrRechazado) {
+		t.Errorf("FALLO DE SEGURIDAD: Se esperaba ErrRechazado, se obtuvo: %v", err)
+	}
+}
+
+func TestHCaptchaValidaHostname(t *testing.T) {
+	var recibido map[string]string
+	srv := servidorSiteVerify(t, "sitio-ajeno.com", &recibido)
+	defer srv.Close()
+
+	v := NewHCaptcha("secreto", srv.URL, []string{"app.example.org"})
+	if err := v.Verify(context.Background(), "token-ok", ""); !errors.Is(err, ErrHostnameInvalido) {
+		t.Errorf("FALLO DE SEGURIDAD: Se aceptó un CAPTCHA resuelto en otro dominio: %v", err)
+	}
+}
+
+func TestSinSecretoRechaza(t *testing.T) {
+	v := NewTurnstile("", "http://127.0.0.1:0", nil)
+	if err := v.Verify(context.Background(), "token-ok", ""); !errors.Is(err, ErrSinSecreto) {
+		t.Errorf("FALLO DE SEGURIDAD: Sin secreto configurado el CAPTCHA debe rechazarse: %v", err)
+	}
+}
+
+func TestFakeRegistraLlamadas(t *testing.T) {
+	f := NewFake("")
+	if err := f.Verify(context.Background(), TokenDePrueba, "10.0.0.1"); err != nil {
+		t.Errorf("FALLO: El verificador falso debe aceptar el token de prueba: %v", err)
+	}
+	if err := f.Verify(context.Background(), "otro", "10.0.0.1"); err == nil {
+		t.Errorf("FALLO: El verificador falso aceptó un token desconocido")
+	}
+	if n := len(f.Llamadas()); n != 2 {
+		t.Errorf("FALLO DE CONTRATO: Se esperaban 2 llamadas registradas, hay %d", n)
+	}
+}
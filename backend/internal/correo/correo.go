@@ -0,0 +1,256 @@
+/**
+ * ARCHIVO: correo.go
+ * UBICACIÓN: internal/correo/correo.go
+ * DESCRIPCIÓN: Envío de correos intercambiable. El servicio nunca llama a un proveedor
+ * directamente: encola en core_email_outbox y el worker entrega con un EmailSender
+ * (Resend en producción, SMTP contra un buzón local como Mailpit, o Fake en pruebas).
+ */
+
+package correo
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/resend/resend-go/v2"
+)
+
+// RemitentePorDefecto se usa si EMAIL_FROM no está definido
+const RemitentePorDefecto = "Seguridad <onboarding@resend.dev>"
+
+var (
+	ErrSinDestinatario = errors.New("el correo no tiene destinatario")
+	// ErrPermanente marca fallos que no se resuelven reintentando (ej: dirección inválida)
+	ErrPermanente = errors.New("fallo permanente de entrega")
+)
+
+// Mensaje: Correo listo para entregar. Texto es la alternativa en texto plano (opcional).
+type Mensaje struct {
+	De     string
+	Para   []string
+	Asunto string
+	HTML   string
+	Texto  string
+	// BajaURL activa la baja en un clic (RFC 8058) en los clientes de correo que la soportan
+	BajaURL string
+}
+
+// cabecerasBaja: List-Unsubscribe y List-Unsubscribe-Post para la baja en un clic
+func cabecerasBaja(url string) map[string]string {
+	if url == "" {
+		return nil
+	}
+	return map[string]string{
+		"List-Unsubscribe":      "<" + url + ">",
+		"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+	}
+}
+
+// EmailSender entrega un correo; devolver un error envuelto en ErrPermanente evita reintentos
+type EmailSender interface {
+	Send(ctx context.Context, m Mensaje) error
+}
+
+// Resend: Proveedor de producción
+type Resend struct {
+	client    *resend.Client
+	remitente string
+}
+
+// NewResend crea el proveedor Resend con la API key y el remitente por defecto
+func NewResend(apiKey, remitente string) *Resend {
+	return &Resend{client: resend.NewClient(apiKey), remitente: remitente}
+}
+
+// Send entrega el mensaje a través de la API de Resend
+func (r *Resend) Send(ctx context.Context, m Mensaje) error {
+	if len(m.Para) == 0 {
+		return fmt.Errorf("%w: %v", ErrPermanente, ErrSinDestinatario)
+	}
+	params := &resend.SendEmailRequest{
+		From:    remitente(m.De, r.remitente),
+		To:      m.Para,
+		Subject: m.Asunto,
+		Html:    m.HTML,
+		Text:    m.Texto,
+		Headers: cabecerasBaja(m.BajaURL),
+	}
+	_, err := r.client.Emails.SendWithContext(ctx, params)
+	return err
+}
+
+// SMTP: Entrega por SMTP. Sin usuario no autentica (útil con Mailpit/MailHog en desarrollo).
+type SMTP struct {
+	addr      string
+	host      string
+	usuario   string
+	clave     string
+	remitente string
+}
+
+// NewSMTP crea el proveedor SMTP (host:puerto)
+func NewSMTP(host, puerto, usuario, clave, remitente string) *SMTP {
+	return &SMTP{addr: net.JoinHostPort(host, puerto), host: host, usuario: usuario, clave: clave, remitente: remitente}
+}
+
+// Send arma un MIME multipart/alternative (texto + HTML) y lo entrega con net/smtp
+func (s *SMTP) Send(ctx context.Context, m Mensaje) error {
+	if len(m.Para) == 0 {
+		return fmt.Errorf("%w: %v", ErrPermanente, ErrSinDestinatario)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if s.usuario != "" {
+		auth = smtp.PlainAuth("", s.usuario, s.clave, s.host)
+	}
+
+	de := remitente(m.De, s.remitente)
+	err := smtp.SendMail(s.addr, auth, direccion(de), m.Para, ArmarMIME(de, m))
+	var respuesta *textproto.Error
+	if errors.As(err, &respuesta) && respuesta.Code >= 500 {
+		// Respuestas 5xx del servidor (ej: 550 buzón inexistente) no mejoran reintentando
+		return fmt.Errorf("%w: %v", ErrPermanente, err)
+	}
+	return err
+}
+
+// ArmarMIME construye el mensaje RFC 5322 con partes texto/HTML en UTF-8
+func ArmarMIME(de string, m Mensaje) []byte {
+	limite := "limite-" + aleatorio()
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", de)
+	fmt.Fprintf(&b, "To: %s\r\n", strings.Join(m.Para, ", "))
+	fmt.Fprintf(&b, "Subject: =?UTF-8?B?%s?=\r\n", base64Encode(m.Asunto))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	if m.BajaURL != "" {
+		fmt.Fprintf(&b, "List-Unsubscribe: <%s>\r\nList-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n", m.BajaURL)
+	}
+	b.WriteString("MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&b, "Content-Type: multipart/alternative; boundary=%q\r\n\r\n", limite)
+
+	if m.Texto != "" {
+		fmt.Fprintf(&b, "--%s\r\nContent-Type: text/plain; charset=UTF-8\r\nContent-Transfer-Encoding: base64\r\n\r\n%s\r\n", limite, base64Lineas(m.Texto))
+	}
+	fmt.Fprintf(&b, "--%s\r\nContent-Type: text/html; charset=UTF-8\r\nContent-Transfer-Encoding: base64\r\n\r\n%s\r\n", limite, base64Lineas(m.HTML))
+	fmt.Fprintf(&b, "--%s--\r\n", limite)
+	return []byte(b.String())
+}
+
+// Fake: Proveedor local sin red. Registra los correos y puede simular fallos.
+type Fake struct {
+	mu       sync.Mutex
+	enviados []Mensaje
+	fallos   []error // Errores a devolver en los próximos envíos (en orden)
+}
+
+// NewFake crea un proveedor falso
+func NewFake() *Fake {
+	return &Fake{}
+}
+
+// FallarCon hace que los próximos envíos devuelvan estos errores antes de volver a funcionar
+func (f *Fake) FallarCon(errs ...error) {
+	f.mu.Lock()
+	f.fallos = append(f.fallos, errs...)
+	f.mu.Unlock()
+}
+
+// Send registra el correo o devuelve el próximo fallo programado
+func (f *Fake) Send(_ context.Context, m Mensaje) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.fallos) > 0 {
+		err := f.fallos[0]
+		f.fallos = f.fallos[1:]
+		return err
+	}
+	if len(m.Para) == 0 {
+		return fmt.Errorf("%w: %v", ErrPermanente, ErrSinDestinatario)
+	}
+	f.enviados = append(f.enviados, m)
+	return nil
+}
+
+// Enviados devuelve una copia de los correos registrados
+func (f *Fake) Enviados() []Mensaje {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Mensaje(nil), f.enviados...)
+}
+
+// NewFromEnv elige el proveedor según EMAIL_PROVIDER (resend por defecto, smtp o fake).
+// SMTP usa SMTP_HOST, SMTP_PORT (1025 por defecto, el de Mailpit), SMTP_USER y SMTP_PASSWORD.
+func NewFromEnv() EmailSender {
+	de := os.Getenv("EMAIL_FROM")
+	if de == "" {
+		de = RemitentePorDefecto
+	}
+
+	switch strings.ToLower(os.Getenv("EMAIL_PROVIDER")) {
+	case "smtp":
+		puerto := os.Getenv("SMTP_PORT")
+		if puerto == "" {
+			puerto = "1025"
+		}
+		return NewSMTP(os.Getenv("SMTP_HOST"), puerto, os.Getenv("SMTP_USER"), os.Getenv("SMTP_PASSWORD"), de)
+	case "fake":
+		log.Println("⚠️  EMAIL en modo FAKE: usar solo en desarrollo local")
+		return NewFake()
+	default:
+		return NewResend(os.Getenv("RESEND_API_KEY"), de)
+	}
+}
+
+// remitente prioriza el del mensaje sobre el configurado
+func remitente(delMensaje, porDefecto string) string {
+	if delMensaje != "" {
+		return delMensaje
+	}
+	return porDefecto
+}
+
+// direccion extrae "a@b.com" de "Nombre <a@b.com>"
+func direccion(de string) string {
+	if i := strings.LastIndex(de, "<"); i >= 0 {
+		return strings.TrimSuffix(de[i+1:], ">")
+	}
+	return de
+}
+
+func aleatorio() string {
+	b := make([]byte, 12)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func base64Encode(s string) string {
+	return base64.StdEncoding.EncodeToString([]byte(s))
+}
+
+// base64Lineas codifica el cuerpo en líneas de 76 caracteres (RFC 2045)
+func base64Lineas(s string) string {
+	enc := base64Encode(s)
+	var b strings.Builder
+	for len(enc) > 76 {
+		b.WriteString(enc[:76])
+		b.WriteString("\r\n")
+		enc = enc[76:]
+	}
+	b.WriteString(enc)
+	return b.String()
+}
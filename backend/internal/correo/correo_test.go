@@ -0,0 +1,138 @@
+/**
+ * ARCHIVO: correo_test.go
+ * UBICACIÓN: backend/internal/correo/correo_test.go
+ * DESCRIPCIÓN: Pruebas del proveedor SMTP contra un buzón local mínimo (sin Internet).
+ */
+
+package correo
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+// buzonSMTP acepta una conexión, responde con 'rcptCode' al RCPT y devuelve el DATA recibido
+func buzonSMTP(t *testing.T, rcptCode string) (string, chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	recibido := make(chan string, 1)
+	go func() {
+		defer ln.Close()
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		escribir := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+		escribir("220 buzon listo")
+		var data strings.Builder
+		for {
+			linea, err := r.ReadString('\n')
+			if err != nil {
+				recibido <- data.String()
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(linea))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				escribir("250 buzon")
+			case strings.HasPrefix(cmd, "MAIL"):
+				escribir("250 ok")
+			case strings.HasPrefix(cmd, "RCPT"):
+				escribir(rcptCode)
+			case cmd == "DATA":
+				escribir("354 adelante")
+				for {
+					l, _ := r.ReadString('\n')
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				escribir("250 recibido")
+			case cmd == "QUIT":
+				escribir("221 chau")
+				recibido <- data.String()
+				return
+			default:
+				escribir("250 ok")
+			}
+		}
+	}()
+	return ln.Addr().String(), recibido
+}
+
+func TestSMTPEntregaTextoYHTML(t *testing.T) {
+	addr, recibido := buzonSMTP(t, "250 ok")
+	host, puerto, _ := net.SplitHostPort(addr)
+
+	s := NewSMTP(host, puerto, "", "", "Seguridad <seguridad@example.org>")
+	err := s.Send(context.Background(), Mensaje{
+		Para:   []string{"miembro@example.org"},
+		Asunto: "Código: 123456",
+		HTML:   "<b>123456</b>",
+		Texto:  "Su código es 123456",
+	})
+	if err != nil {
+		t.Fatalf("Se esperaba entrega exitosa: %v", err)
+	}
+
+	data := <-recibido
+	if !strings.Contains(data, "multipart/alternative") || !strings.Contains(data, "text/plain") || !strings.Contains(data, "text/html") {
+		t.Errorf("El mensaje debe incluir las partes texto y HTML:\n%s", data)
+	}
+	if !strings.Contains(data, base64.StdEncoding.EncodeToString([]byte("Su código es 123456"))) {
+		t.Errorf("No se encontró el cuerpo de texto codificado")
+	}
+}
+
+func TestSMTPRechazo5xxEsPermanente(t *testing.T) {
+	addr, _ := buzonSMTP(t, "550 buzon inexistente")
+	host, puerto, _ := net.SplitHostPort(addr)
+
+	err := NewSMTP(host, puerto, "", "", "a@example.org").Send(context.Background(), Mensaje{Para: []string{"nadie@example.org"}, HTML: "x"})
+	if !errors.Is(err, ErrPermanente) {
+		t.Errorf("Un 550 no debe reintentarse, se obtuvo: %v", err)
+	}
+}
+
+func TestFakeSimulaFallos(t *testing.T) {
+	f := NewFake()
+	f.FallarCon(errors.New("timeout"))
+
+	m := Mensaje{Para: []string{"a@example.org"}, HTML: "hola"}
+	if err := f.Send(context.Background(), m); err == nil {
+		t.Errorf("El primer envío debía fallar")
+	}
+	if err := f.Send(context.Background(), m); err != nil || len(f.Enviados()) != 1 {
+		t.Errorf("El segundo envío debía registrarse: %v", err)
+	}
+}
+
+func TestArmarMIMEIncluyeBajaEnUnClic(t *testing.T) {
+	mime := string(ArmarMIME("a@example.org", Mensaje{
+		Para:    []string{"miembro@example.org"},
+		HTML:    "x",
+		BajaURL: "https://example.org/api/notificaciones/baja?t=abc",
+	}))
+	if !strings.Contains(mime, "List-Unsubscribe: <https://example.org/api/notificaciones/baja?t=abc>\r\n") {
+		t.Errorf("Falta la cabecera List-Unsubscribe:\n%s", mime)
+	}
+	if !strings.Contains(mime, "List-Unsubscribe-Post: List-Unsubscribe=One-Click\r\n") {
+		t.Errorf("Falta la cabecera List-Unsubscribe-Post (RFC 8058)")
+	}
+
+	if strings.Contains(string(ArmarMIME("a@example.org", Mensaje{Para: []string{"b@example.org"}, HTML: "x"})), "List-Unsubscribe") {
+		t.Errorf("Los correos transaccionales no llevan enlace de baja")
+	}
+}
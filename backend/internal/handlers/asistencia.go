@@ -0,0 +1,120 @@
+/**
+ * ARCHIVO: asistencia.go
+ * UBICACIÓN: internal/handlers/asistencia.go
+ * DESCRIPCIÓN: Endpoints de asistencia a las reuniones: carga por reunión, listado,
+ * baja y el informe de promedios mensuales (JSON o CSV para el archivo de la congregación).
+ */
+
+package handlers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/service"
+)
+
+// writeAsistenciaError traduce los errores propios de asistencia y delega el resto
+func writeAsistenciaError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrAsistenciaInvalida), errors.Is(err, service.ErrPeriodoInvalido):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrAsistenciaNoEncontrada):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		writeServiceError(w, err)
+	}
+}
+
+// RegistrarAsistenciaHandler: Carga o corrige la asistencia de una reunión
+func RegistrarAsistenciaHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Fecha      string `json:"fecha"`
+			Tipo       string `json:"tipo"`
+			Presencial int    `json:"presencial"`
+			Video      int    `json:"video"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		a, err := s.SaveAttendance(SesionFromContext(r).PersonaID, req.Fecha, req.Tipo, req.Presencial, req.Video)
+		if err != nil {
+			writeAsistenciaError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(a)
+	}
+}
+
+// ListAsistenciaHandler: Registros del período (?desde=AAAA-MM&hasta=AAAA-MM)
+func ListAsistenciaHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		lista, err := s.ListAttendance(SesionFromContext(r).PersonaID, q.Get("desde"), q.Get("hasta"))
+		if err != nil {
+			writeAsistenciaError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(lista)
+	}
+}
+
+// DeleteAsistenciaHandler: Borra un registro cargado por error
+func DeleteAsistenciaHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil || id <= 0 {
+			http.Error(w, "id de asistencia inválido", http.StatusBadRequest)
+			return
+		}
+		if err := s.DeleteAttendance(SesionFromContext(r).PersonaID, id); err != nil {
+			writeAsistenciaError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// InformeAsistenciaHandler: Promedios mensuales del período; con ?formato=csv se descarga
+func InformeAsistenciaHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		inf, err := s.GetAttendanceReport(SesionFromContext(r).PersonaID, q.Get("desde"), q.Get("hasta"))
+		if err != nil {
+			writeAsistenciaError(w, err)
+			return
+		}
+		if q.Get("formato") == "csv" {
+			escribirInformeCSV(w, inf)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(inf)
+	}
+}
+
+// escribirInformeCSV: Una fila por mes y tipo de reunión, con encabezado
+func escribirInformeCSV(w http.ResponseWriter, inf *models.InformeAsistencia) {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="asistencia_%s_%s.csv"`, inf.Desde, inf.Hasta))
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"mes", "tipo", "reuniones", "total_presencial", "total_video", "total",
+		"promedio_presencial", "promedio_video", "promedio"})
+	for _, m := range inf.Meses {
+		cw.Write([]string{m.Mes, m.Tipo, strconv.Itoa(m.Reuniones),
+			strconv.Itoa(m.TotalPresencial), strconv.Itoa(m.TotalVideo), strconv.Itoa(m.Total),
+			strconv.Itoa(m.PromedioPresencial), strconv.Itoa(m.PromedioVideo), strconv.Itoa(m.Promedio)})
+	}
+	cw.Flush()
+}
@@ -0,0 +1,240 @@
+/**
+ * ARCHIVO: boletines.go
+ * UBICACIÓN: internal/handlers/boletines.go
+ * DESCRIPCIÓN: CRUD de boletines de seguridad con borradores e historial de versiones.
+ * Los miembros leen los publicados; crear, editar, borrar y publicar es del admin local.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gestion-congregacion/backend/internal/service"
+)
+
+// boletinReq: Cuerpo de alta y edición
+type boletinReq struct {
+	Titulo           string `json:"titulo"`
+	DescripcionLarga string `json:"descripcion_larga"`
+	Publicar         bool   `json:"publicar"`
+}
+
+// writeBoletinError traduce los errores propios de boletines y delega el resto
+func writeBoletinError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrBoletinInvalido), errors.Is(err, service.ErrDiasInvalidos):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrBoletinNoEncontrado):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrBoletinPublicado):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeServiceError(w, err)
+	}
+}
+
+// boletinID lee el {id} de la ruta
+func boletinID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		http.Error(w, "id de boletín inválido", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// ListBoletinesHandler: Página de boletines (?pagina=&por_pagina=)
+func ListBoletinesHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pagina, _ := strconv.Atoi(r.URL.Query().Get("pagina"))
+		porPagina, _ := strconv.Atoi(r.URL.Query().Get("por_pagina"))
+
+		p, err := s.ListBulletins(SesionFromContext(r).PersonaID, pagina, porPagina)
+		if err != nil {
+			writeBoletinError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	}
+}
+
+// GetBoletinHandler: Un boletín puntual
+func GetBoletinHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := boletinID(w, r)
+		if !ok {
+			return
+		}
+		b, err := s.GetBulletin(SesionFromContext(r).PersonaID, id)
+		if err != nil {
+			writeBoletinError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(b)
+	}
+}
+
+// CreateBoletinHandler: Alta como borrador, o publicado (con difusión) si "publicar" es true
+func CreateBoletinHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 102400)
+		var req boletinReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		b, d, err := s.CreateBulletin(SesionFromContext(r).PersonaID, req.Titulo, req.DescripcionLarga, req.Publicar)
+		if err != nil {
+			writeBoletinError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{"boletin": b, "difusion": d})
+	}
+}
+
+// UpdateBoletinHandler: Edita título y descripción (guarda una versión nueva)
+func UpdateBoletinHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := boletinID(w, r)
+		if !ok {
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, 102400)
+		var req boletinReq
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		b, err := s.UpdateBulletin(SesionFromContext(r).PersonaID, id, req.Titulo, req.DescripcionLarga)
+		if err != nil {
+			writeBoletinError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(b)
+	}
+}
+
+// DeleteBoletinHandler: Baja lógica
+func DeleteBoletinHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := boletinID(w, r)
+		if !ok {
+			return
+		}
+		if err := s.DeleteBulletin(SesionFromContext(r).PersonaID, id); err != nil {
+			writeBoletinError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// HistorialBoletinHandler: Versiones guardadas de un boletín
+func HistorialBoletinHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := boletinID(w, r)
+		if !ok {
+			return
+		}
+		h, err := s.GetBulletinHistory(SesionFromContext(r).PersonaID, id)
+		if err != nil {
+			writeBoletinError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(h)
+	}
+}
+
+// PublicarBoletinHandler: Publica un borrador y lanza su difusión
+func PublicarBoletinHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := boletinID(w, r)
+		if !ok {
+			return
+		}
+		d, err := s.PublishBulletin(SesionFromContext(r).PersonaID, id)
+		if err != nil {
+			writeBoletinError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(d)
+	}
+}
+
+// AcuseBoletinHandler: El miembro marca el boletín como leído o lo confirma ({"confirmado": true})
+func AcuseBoletinHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := boletinID(w, r)
+		if !ok {
+			return
+		}
+		var req struct {
+			Confirmado bool `json:"confirmado"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+		if err := s.AcknowledgeBulletin(SesionFromContext(r).PersonaID, id, req.Confirmado); err != nil {
+			writeBoletinError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// LecturasBoletinHandler: Porcentaje de lectura y confirmación por congregación y grupo
+func LecturasBoletinHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := boletinID(w, r)
+		if !ok {
+			return
+		}
+		l, err := s.GetAcknowledgementStats(SesionFromContext(r).PersonaID, id)
+		if err != nil {
+			writeBoletinError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(l)
+	}
+}
+
+// RecordatorioBoletinHandler: Vuelve a avisar a quienes no confirmaron tras N días ({"dias": 7})
+func RecordatorioBoletinHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := boletinID(w, r)
+		if !ok {
+			return
+		}
+		var req struct {
+			Dias int `json:"dias"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+		n, err := s.RemindUnacknowledged(SesionFromContext(r).PersonaID, id, req.Dias)
+		if err != nil {
+			writeBoletinError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(map[string]int{"recordados": n})
+	}
+}
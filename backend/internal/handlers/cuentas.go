@@ -0,0 +1,74 @@
+/**
+ * ARCHIVO: cuentas.go
+ * UBICACIÓN: internal/handlers/cuentas.go
+ * DESCRIPCIÓN: Endpoints de administración de cuentas (reactivación, desbloqueo y auditoría).
+ * Solo disponibles para el admin local de la congregación del miembro.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"gestion-congregacion/backend/internal/service"
+	"net/http"
+	"strconv"
+)
+
+// AdminReactivarCuentaHandler: Devuelve el acceso a un miembro suspendido, indicando el motivo
+func AdminReactivarCuentaHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		r.Body = http.MaxBytesReader(w, r.Body, 10240)
+		var req struct {
+			PersonaID int    `json:"persona_id"`
+			Motivo    string `json:"motivo"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PersonaID == 0 {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.ReactivateAccount(SesionFromContext(r).PersonaID, req.PersonaID, req.Motivo); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// AdminAuditoriaHandler: Historial de suspensiones y reactivaciones de un miembro (?persona_id=)
+func AdminAuditoriaHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		miembroID, err := strconv.Atoi(r.URL.Query().Get("persona_id"))
+		if err != nil {
+			http.Error(w, "persona_id inválido", http.StatusBadRequest)
+			return
+		}
+
+		lista, err := s.GetAuditTrail(SesionFromContext(r).PersonaID, miembroID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(lista)
+	}
+}
+
+// AdminDesbloquearCuentaHandler: Levanta el bloqueo por intentos fallidos de un miembro
+func AdminDesbloquearCuentaHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			PersonaID int `json:"persona_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PersonaID == 0 {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.UnlockAccount(SesionFromContext(r).PersonaID, req.PersonaID); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -8,14 +8,15 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"gestion-congregacion/backend/internal/auth"
 	"gestion-congregacion/backend/internal/service"
 	"net"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/microcosm-cc/bluemonday"
 )
 
@@ -39,25 +40,21 @@ func LoginFinalHandler(s *service.Service) http.HandlerFunc {
 			return
 		}
 
-		// Obtener la IP real del usuario (considerando Cloudflare)
-		ip := r.Header.Get("CF-Connecting-IP")
-		if ip == "" {
-			ip = r.Header.Get("X-Forwarded-For")
-		}
-		if ip == "" {
-			var err error
-			ip, _, err = net.SplitHostPort(r.RemoteAddr)
-			if err != nil {
-				ip = r.RemoteAddr
-			}
-		}
-
-		// Llamamos al nuevo Authenticate con IP y Token
-		user, accessToken, refreshToken, err := s.Authenticate(req.Username, req.Password, req.TurnstileToken, ip)
+		// Llamamos al nuevo Authenticate con IP, Token y dispositivo
+		user, accessToken, refreshToken, err := s.Authenticate(req.Username, req.Password, req.TurnstileToken, clientIP(r), r.UserAgent())
 
+		var espera *service.ErrEspera
+		if errors.As(err, &espera) {
+			// Bloqueo por cuenta: indicamos cuándo reintentar (igual exista o no el usuario)
+			w.Header().Set("Retry-After", strconv.Itoa(int(espera.Espera.Seconds())+1))
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
 		if err != nil {
-			// Si el error contiene la palabra "SISTEMA", es un error de seguridad/captcha
-			if strings.Contains(err.Error(), "SISTEMA") {
+			// Si el error contiene la palabra "SISTEMA", es un error de seguridad/captcha.
+			// Una cuenta suspendida también es 403 (solo se informa con la contraseña correcta).
+			if strings.Contains(err.Error(), "SISTEMA") || errors.Is(err, service.ErrCuentaSuspendida) {
 				w.WriteHeader(http.StatusForbidden)
 			} else {
 				w.WriteHeader(http.StatusUnauthorized)
@@ -66,49 +63,84 @@ func LoginFinalHandler(s *service.Service) http.HandlerFunc {
 			return
 		}
 
-		// Seteamos la Cookie de Acceso (Corta)
-		http.SetCookie(w, &http.Cookie{
-			Name:     "auth_token",
-			Value:    accessToken,
-			Expires:  time.Now().Add(15 * time.Minute),
-			HttpOnly: true,
-			Secure:   true,
-			SameSite: http.SameSiteNoneMode,
-			Path:     "/",
-		})
-
-		// Seteamos la Cookie de Refresco (Larga)
-		http.SetCookie(w, &http.Cookie{
-			Name:     "refresh_token",
-			Value:    refreshToken,
-			Expires:  time.Now().Add(7 * 24 * time.Hour),
-			HttpOnly: true,
-			Secure:   true,
-			SameSite: http.SameSiteNoneMode,
-			Path:     "/",
-		})
+		setSessionCookies(w, accessToken, refreshToken)
 
 		json.NewEncoder(w).Encode(map[string]interface{}{"user": user})
 	}
 }
 
-func IdentifyUserHandler(s *service.Service) http.HandlerFunc {
+// clientIP obtiene la IP real del usuario (considerando Cloudflare)
+func clientIP(r *http.Request) string {
+	ip := r.Header.Get("CF-Connecting-IP")
+	if ip == "" {
+		ip = r.Header.Get("X-Forwarded-For")
+	}
+	if ip == "" {
+		var err error
+		ip, _, err = net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+	}
+	return ip
+}
+
+// RecoveryStartHandler: Primer paso de la recuperación. La respuesta es idéntica
+// exista o no la cuenta; solo se limita la cantidad de solicitudes por cuenta objetivo.
+func RecoveryStartHandler(s *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req struct{ Username string }
-		json.NewDecoder(r.Body).Decode(&req)
-		if err := s.IdentifyUser(req.Username); err != nil {
-			w.WriteHeader(http.StatusNotFound)
+		var req service.SolicitudRecuperacion
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
 			return
 		}
-		w.WriteHeader(http.StatusOK)
+
+		res, err := s.StartRecovery(req)
+		if errors.Is(err, service.ErrDemasiadasSolicitudes) {
+			w.Header().Set("Retry-After", strconv.Itoa(int(service.VentanaSolicitudes.Seconds())))
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		if err != nil {
+			http.Error(w, "Error interno", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(res)
+	}
+}
+
+// RecoveryVerifyHandler: Valida el PIN del ticket de recuperación
+func RecoveryVerifyHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			RecoveryID string `json:"recovery_id"`
+			Pin        string `json:"pin"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		tipo, err := s.VerifyRecovery(req.RecoveryID, req.Pin)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"tipo": tipo})
 	}
 }
 
+// RequestPinHandler: Envía un PIN al correo registrado del usuario con sesión activa
 func RequestPinHandler(s *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req struct{ Email, Username, Congregacion string }
-		json.NewDecoder(r.Body).Decode(&req)
-		s.ProcessPinRequest(req.Email, req.Username, req.Congregacion)
+		sesion := SesionFromContext(r)
+		if err := s.ProcessPinRequest(sesion.PersonaID); err != nil {
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 	}
 }
@@ -133,12 +165,29 @@ func VerifyPinHandler(s *service.Service) http.HandlerFunc {
 	}
 }
 
+// UpdateProfileDataHandler: El miembro edita un dato de su propio perfil (la persona sale de la sesión)
 func UpdateProfileDataHandler(s *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req struct{ PersonaID, Campo, Valor string }
-		json.NewDecoder(r.Body).Decode(&req)
-		s.UpdateProfile(req.PersonaID, req.Campo, req.Valor)
-		w.WriteHeader(http.StatusOK)
+		var req struct {
+			Campo string `json:"campo"`
+			Valor string `json:"valor"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		err := s.UpdateProfile(SesionFromContext(r).PersonaID, req.Campo, req.Valor)
+		switch {
+		case errors.Is(err, service.ErrTelefonoInvalido), errors.Is(err, service.ErrCanalInvalido),
+			errors.Is(err, service.ErrIdiomaInvalido), errors.Is(err, service.ErrCampoNoEditable),
+			errors.Is(err, service.ErrValorInvalido):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case err != nil:
+			writeServiceError(w, err)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
 	}
 }
 
@@ -162,11 +211,34 @@ func UploadFotoHandler(s *service.Service) http.HandlerFunc {
 	}
 }
 
+// SuspenderCuentaHandler: Baja de la propia cuenta o, con persona_id, la de un miembro (admin local)
 func SuspenderCuentaHandler(s *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req struct{ PersonaID, UsuarioID string }
-		json.NewDecoder(r.Body).Decode(&req)
-		s.SuspendUser(req.PersonaID, req.UsuarioID)
+		var req struct {
+			PersonaID string `json:"persona_id"`
+		}
+		if r.ContentLength != 0 {
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, "JSON inválido", http.StatusBadRequest)
+				return
+			}
+		}
+
+		actor := SesionFromContext(r).PersonaID
+		personaID := actor
+		if req.PersonaID != "" {
+			id, err := strconv.Atoi(req.PersonaID)
+			if err != nil {
+				http.Error(w, "persona_id inválido", http.StatusBadRequest)
+				return
+			}
+			personaID = id
+		}
+
+		if err := s.SuspendUser(actor, personaID); err != nil {
+			writeServiceError(w, err)
+			return
+		}
 		w.WriteHeader(http.StatusOK)
 	}
 }
@@ -183,15 +255,37 @@ func BroadcastSeguridadUpdateHandler(s *service.Service) http.HandlerFunc {
 			return
 		}
 
-		if err := s.BroadcastSecurity(req.Titulo, req.DescripcionLarga); err != nil {
-			http.Error(w, "Error en la difusión", 500)
+		d, err := s.BroadcastSecurity(SesionFromContext(r).PersonaID, req.Titulo, req.DescripcionLarga)
+		if err != nil {
+			writeBoletinError(w, err)
 			return
 		}
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusAccepted)
+		json.NewEncoder(w).Encode(d)
 	}
 }
 
-// GetSeguridadInfoHandler: Devuelve el boletín al frontend
+// DifusionProgresoHandler: Avance de la difusión de un boletín (enviados, pendientes y fallos)
+func DifusionProgresoHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		boletinID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "id de boletín inválido", http.StatusBadRequest)
+			return
+		}
+
+		p, err := s.GetBroadcastProgress(SesionFromContext(r).PersonaID, boletinID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	}
+}
+
+// GetSeguridadInfoHandler: Devuelve al frontend el último boletín publicado (título y descripción larga)
 func GetSeguridadInfoHandler(s *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		info, err := s.GetSecurityBulletin()
@@ -212,7 +306,8 @@ func SaveSeguridadInfoHandler(s *service.Service) http.HandlerFunc {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		r.Body = http.MaxBytesReader(w, r.Body, 102400)
 		var req struct {
-			Contenido string `json:"contenido"`
+			Contenido        string `json:"contenido"`
+			DescripcionLarga string `json:"descripcion_larga"` // Markdown: se sanea al renderizarlo
 		}
 
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -220,13 +315,13 @@ func SaveSeguridadInfoHandler(s *service.Service) http.HandlerFunc {
 			return
 		}
 
-		// Sanitización estricta.
-		// Solo permite formato de texto seguro, elimina <script>, <iframe> y eventos JS.
+		// Sanitización estricta del título: es texto plano, sin etiquetas.
+		// Elimina <script>, <iframe> y eventos JS. El formato va en la descripción (Markdown).
 		p := bluemonday.StrictPolicy()
 		cleanContenido := p.Sanitize(req.Contenido)
 
-		if err := s.AddSecurityInfo(cleanContenido); err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+		if err := s.AddSecurityInfo(SesionFromContext(r).PersonaID, cleanContenido, req.DescripcionLarga); err != nil {
+			writeBoletinError(w, err)
 			return
 		}
 
@@ -235,51 +330,58 @@ func SaveSeguridadInfoHandler(s *service.Service) http.HandlerFunc {
 	})
 }
 
-// RecoverByPersonaIDHandler: Recupera cuenta por ID o Teléfono
-func RecoverByPersonaIDHandler(s *service.Service) http.HandlerFunc {
+// HandleFileUpload: Firma actualizada para ser consistente con el resto de la arquitectura
+func HandleFileUpload(s *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req struct {
-			PersonaID string `json:"persona_id"`
-			NumCong   string `json:"numero_congregacion"`
-			Telefono  string `json:"telefono"`
-			Metodo    string `json:"metodo"`
-		}
-		json.NewDecoder(r.Body).Decode(&req)
-
-		// Delegamos al servicio (Implementaremos la lógica en el siguiente paso)
-		email, err := s.RecoverAccount(req.PersonaID, req.NumCong, req.Telefono, req.Metodo)
-		if err != nil {
-			w.WriteHeader(http.StatusNotFound)
-			return
-		}
-		json.NewEncoder(w).Encode(map[string]string{"email": email})
+		// Aquí irá la lógica de subida de archivos en el futuro
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(map[string]string{"status": "ready_for_upload"})
 	}
 }
 
-// SendUsernameRealHandler: Envía datos de acceso por correo
-func SendUsernameRealHandler(s *service.Service) http.HandlerFunc {
+// LogoutHandler: Revoca la sesión del dispositivo y borra ambas cookies
+func LogoutHandler(s *service.Service) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		var req struct{ Email string }
-		json.NewDecoder(r.Body).Decode(&req)
-
-		if err := s.SendAccessData(req.Email); err != nil {
-			http.Error(w, "Error al enviar correo", 500)
-			return
+		// Revocamos la sesión (best-effort: aunque falle, las cookies se borran igual)
+		if cookie, err := r.Cookie("refresh_token"); err == nil {
+			if claims, err := auth.ValidarRefreshToken(cookie.Value); err == nil {
+				if sesion, err := s.ValidateSession(claims); err == nil {
+					s.Logout(sesion)
+				}
+			}
 		}
+		clearSessionCookies(w)
 		w.WriteHeader(http.StatusOK)
 	}
 }
 
-// HandleFileUpload: Firma actualizada para ser consistente con el resto de la arquitectura
-func HandleFileUpload(s *service.Service) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		// Aquí irá la lógica de subida de archivos en el futuro
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]string{"status": "ready_for_upload"})
-	}
+// setSessionCookies guarda la llave de acceso (corta) y la de refresco (larga)
+func setSessionCookies(w http.ResponseWriter, accessToken, refreshToken string) {
+	// Seteamos la Cookie de Acceso (Corta)
+	http.SetCookie(w, &http.Cookie{
+		Name:     "auth_token",
+		Value:    accessToken,
+		Expires:  time.Now().Add(auth.AccessTokenTTL),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+		Path:     "/",
+	})
+
+	// Seteamos la Cookie de Refresco (Larga)
+	http.SetCookie(w, &http.Cookie{
+		Name:     "refresh_token",
+		Value:    refreshToken,
+		Expires:  time.Now().Add(auth.RefreshTokenTTL),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteNoneMode,
+		Path:     "/",
+	})
 }
 
-func LogoutHandler(w http.ResponseWriter, r *http.Request) {
+// clearSessionCookies expira las cookies de acceso y refresco
+func clearSessionCookies(w http.ResponseWriter) {
 	// Borramos la de acceso
 	http.SetCookie(w, &http.Cookie{
 		Name: "auth_token", Value: "", Path: "/", Expires: time.Unix(0, 0),
@@ -290,7 +392,6 @@ func LogoutHandler(w http.ResponseWriter, r *http.Request) {
 		Name: "refresh_token", Value: "", Path: "/", Expires: time.Unix(0, 0),
 		HttpOnly: true, Secure: true, SameSite: http.SameSiteNoneMode,
 	})
-	w.WriteHeader(http.StatusOK)
 }
 
 func RefreshTokenHandler(s *service.Service) http.HandlerFunc {
@@ -301,22 +402,18 @@ func RefreshTokenHandler(s *service.Service) http.HandlerFunc {
 			return
 		}
 
-		token, err := auth.ValidarJWT(cookie.Value)
-		if err != nil || !token.Valid {
+		// Generamos nueva llave corta (solo si la sesión sigue activa)
+		newAccess, err := s.RefreshSession(cookie.Value, clientIP(r))
+		if err != nil {
+			clearSessionCookies(w)
 			http.Error(w, "Llave de refresco inválida", http.StatusUnauthorized)
 			return
 		}
 
-		claims := token.Claims.(jwt.MapClaims)
-		userID := claims["sub"].(string)
-
-		// Generamos nueva llave corta
-		newAccess, _ := auth.GenerarAccessToken(userID)
-
 		http.SetCookie(w, &http.Cookie{
 			Name:     "auth_token",
 			Value:    newAccess,
-			Expires:  time.Now().Add(15 * time.Minute),
+			Expires:  time.Now().Add(auth.AccessTokenTTL),
 			HttpOnly: true, Secure: true, SameSite: http.SameSiteNoneMode, Path: "/",
 		})
 
@@ -324,22 +421,56 @@ func RefreshTokenHandler(s *service.Service) http.HandlerFunc {
 	}
 }
 
+// ResetPasswordHandler: Último paso de la recuperación (requiere un ticket con PIN verificado)
 func ResetPasswordHandler(s *service.Service) http.HandlerFunc {
-    return func(w http.ResponseWriter, r *http.Request) {
-        var req struct {
-            Username    string `json:"username"`
-            NewPassword string `json:"new_password"`
-        }
-        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-            http.Error(w, "JSON inválido", 400)
-            return
-        }
-        // Llamamos al servicio para actualizar
-        err := s.UpdatePasswordByUsername(req.Username, req.NewPassword)
-        if err != nil {
-            http.Error(w, err.Error(), 500)
-            return
-        }
-        w.WriteHeader(http.StatusOK)
-    }
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			RecoveryID  string `json:"recovery_id"`
+			NewPassword string `json:"new_password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", 400)
+			return
+		}
+
+		err := s.CompleteRecovery(req.RecoveryID, req.NewPassword)
+		switch {
+		case errors.Is(err, service.ErrClaveDebil):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrRecuperacionInvalida):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case err != nil:
+			http.Error(w, "Error al actualizar la contraseña", http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}
+}
+
+// ChangePasswordHandler: Cambio de contraseña desde el perfil (sesión activa + clave actual).
+// Reemplaza las cookies para que este dispositivo siga conectado; los demás quedan cerrados.
+func ChangePasswordHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			CurrentPassword string `json:"current_password"`
+			NewPassword     string `json:"new_password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", 400)
+			return
+		}
+
+		access, refresh, err := s.ChangePassword(ClaimsFromContext(r), req.CurrentPassword, req.NewPassword)
+		switch {
+		case errors.Is(err, service.ErrClaveActualIncorrecta):
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+		case errors.Is(err, service.ErrClaveDebil):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case err != nil:
+			http.Error(w, "Error al actualizar la contraseña", http.StatusInternalServerError)
+		default:
+			setSessionCookies(w, access, refresh)
+			w.WriteHeader(http.StatusOK)
+		}
+	}
 }
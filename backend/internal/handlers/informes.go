@@ -0,0 +1,99 @@
+/**
+ * ARCHIVO: informes.go
+ * UBICACIÓN: internal/handlers/informes.go
+ * DESCRIPCIÓN: Endpoints del informe mensual de servicio: el miembro entrega y consulta
+ * el suyo, el superintendente ve los pendientes de su grupo y el secretario los totales.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gestion-congregacion/backend/internal/service"
+)
+
+// writeInformeError traduce los errores propios de informes y delega el resto
+func writeInformeError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInformeInvalido), errors.Is(err, service.ErrMesInvalido), errors.Is(err, service.ErrSinGrupo):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrInformeNoEncontrado):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrPersonaNoHabilitada):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		writeServiceError(w, err)
+	}
+}
+
+// EntregarInformeHandler: El miembro entrega o corrige su informe del mes
+func EntregarInformeHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req service.DatosInforme
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		i, err := s.SubmitServiceReport(SesionFromContext(r).PersonaID, req)
+		if err != nil {
+			writeInformeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(i)
+	}
+}
+
+// MiInformeHandler: El informe propio del mes (?mes=AAAA-MM, por defecto el anterior)
+func MiInformeHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		i, err := s.GetOwnServiceReport(SesionFromContext(r).PersonaID, r.URL.Query().Get("mes"))
+		if err != nil {
+			writeInformeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(i)
+	}
+}
+
+// InformesPendientesHandler: Miembros del grupo sin informe (?mes=AAAA-MM&grupo=N)
+func InformesPendientesHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var grupo *int
+		if g := r.URL.Query().Get("grupo"); g != "" {
+			n, err := strconv.Atoi(g)
+			if err != nil {
+				http.Error(w, "grupo inválido", http.StatusBadRequest)
+				return
+			}
+			grupo = &n
+		}
+
+		p, err := s.GetMissingReports(SesionFromContext(r).PersonaID, r.URL.Query().Get("mes"), grupo)
+		if err != nil {
+			writeInformeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	}
+}
+
+// TotalesInformesHandler: Totales del mes por categoría (?mes=AAAA-MM)
+func TotalesInformesHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		t, err := s.GetReportTotals(SesionFromContext(r).PersonaID, r.URL.Query().Get("mes"))
+		if err != nil {
+			writeInformeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t)
+	}
+}
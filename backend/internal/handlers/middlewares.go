@@ -8,12 +8,35 @@
 package handlers
 
 import (
-	//"context"
+	"context"
+	"errors"
 	"gestion-congregacion/backend/internal/auth"
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/service"
 	"net/http"
 	"strings"
 )
 
+// ctxKey evita colisiones con otras claves guardadas en el contexto de la petición
+type ctxKey string
+
+const (
+	sesionCtxKey ctxKey = "sesion"
+	claimsCtxKey ctxKey = "claims"
+)
+
+// SesionFromContext devuelve la sesión validada por AuthMiddleware (nil en rutas públicas)
+func SesionFromContext(r *http.Request) *models.Sesion {
+	sesion, _ := r.Context().Value(sesionCtxKey).(*models.Sesion)
+	return sesion
+}
+
+// ClaimsFromContext devuelve la llave de acceso ya validada (nil en rutas públicas)
+func ClaimsFromContext(r *http.Request) *auth.Claims {
+	claims, _ := r.Context().Value(claimsCtxKey).(*auth.Claims)
+	return claims
+}
+
 // Añadimos cabeceras de blindaje industrial
 func SecurityHeadersMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -35,7 +58,8 @@ func SecurityHeadersMiddleware(next http.Handler) http.Handler {
 	})
 }
 
-func AuthMiddleware(next http.Handler) http.Handler {
+// AuthMiddleware valida la llave de acceso y que su sesión siga activa en core_sesiones
+func AuthMiddleware(s *service.Service, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// 1. Intentar obtener la cookie
 		cookie, err := r.Cookie("auth_token")
@@ -46,13 +70,26 @@ func AuthMiddleware(next http.Handler) http.Handler {
 
 		tokenStr := cookie.Value
 
-		// 2. Validar el token (esto sigue igual)
-		token, err := auth.ValidarJWT(tokenStr)
-		if err != nil || !token.Valid {
+		// 2. Validar el token (solo llaves de tipo 'access'; una de refresco es rechazada)
+		claims, err := auth.ValidarAccessToken(tokenStr)
+		if err != nil {
 			http.Error(w, "Token inválido", http.StatusUnauthorized)
 			return
 		}
 
-		next.ServeHTTP(w, r)
+		// 3. Verificar que la sesión no haya sido revocada (ej: dispositivo perdido)
+		sesion, err := s.ValidateSession(claims)
+		if errors.Is(err, service.ErrCuentaSuspendida) {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
+		if err != nil {
+			http.Error(w, "Sesión expirada o no autorizada", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), sesionCtxKey, sesion)
+		ctx = context.WithValue(ctx, claimsCtxKey, claims)
+		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
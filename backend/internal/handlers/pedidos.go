@@ -0,0 +1,70 @@
+/**
+ * ARCHIVO: pedidos.go
+ * UBICACIÓN: internal/handlers/pedidos.go
+ * DESCRIPCIÓN: Endpoints del mostrador de literatura: llegada de stock y pedidos
+ * apartados. Solo para el admin local; cada pedido listo avisa al hermano.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gestion-congregacion/backend/internal/service"
+)
+
+// writePedidoError traduce los errores propios de pedidos y delega el resto
+func writePedidoError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrCantidadInvalida):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrPedidoNoDisponible):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeServiceError(w, err)
+	}
+}
+
+// LlegadaStockHandler: Registra ejemplares recibidos y devuelve los pedidos que quedaron listos
+func LlegadaStockHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			PublicacionID string `json:"publicacion_id"`
+			Cantidad      int    `json:"cantidad"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		listos, err := s.RegisterStockArrival(SesionFromContext(r).PersonaID, req.PublicacionID, req.Cantidad)
+		if err != nil {
+			writePedidoError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(listos)
+	}
+}
+
+// ApartarPedidoHandler: Marca un pedido como listo para retirar
+func ApartarPedidoHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		pedidoID, err := strconv.Atoi(r.PathValue("id"))
+		if err != nil {
+			http.Error(w, "id de pedido inválido", http.StatusBadRequest)
+			return
+		}
+
+		p, err := s.SetAsideOrder(SesionFromContext(r).PersonaID, pedidoID)
+		if err != nil {
+			writePedidoError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	}
+}
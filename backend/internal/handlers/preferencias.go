@@ -0,0 +1,91 @@
+/**
+ * ARCHIVO: preferencias.go
+ * UBICACIÓN: internal/handlers/preferencias.go
+ * DESCRIPCIÓN: Endpoints de preferencias de notificación y baja en un clic.
+ * La baja es pública: el enlace firmado identifica al miembro. El GET solo muestra
+ * la confirmación (los antivirus de correo abren enlaces); el POST la aplica.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"net/http"
+
+	"gestion-congregacion/backend/internal/service"
+)
+
+// ListPreferenciasHandler: Matriz categoría × canal del usuario actual
+func ListPreferenciasHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		lista, err := s.ListPreferencias(SesionFromContext(r).PersonaID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(lista)
+	}
+}
+
+// UpdatePreferenciaHandler: Activa o desactiva una categoría en un canal
+func UpdatePreferenciaHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Categoria string `json:"categoria"`
+			Canal     string `json:"canal"`
+			Activo    bool   `json:"activo"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		err := s.UpdatePreferencia(SesionFromContext(r).PersonaID, req.Categoria, req.Canal, req.Activo)
+		if errors.Is(err, service.ErrPreferenciaInvalida) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// paginaBaja: Confirmación mínima servida por el backend (el enlace puede abrirse sin sesión)
+var paginaBaja = template.Must(template.New("baja").Parse(`<!DOCTYPE html>
+<html lang="es"><head><meta charset="UTF-8"><meta name="viewport" content="width=device-width, initial-scale=1.0">
+<title>Avisos por correo</title></head>
+<body style="font-family:Arial,Helvetica,sans-serif;max-width:480px;margin:48px auto;padding:0 16px;color:#1f2a44;">
+{{if .Hecho}}<h1 style="font-size:20px;">Listo</h1>
+<p>Ya no recibirá estos avisos. Puede volver a activarlos desde su perfil.</p>
+{{else}}<h1 style="font-size:20px;">Dejar de recibir estos avisos</h1>
+<form method="POST" action="?t={{.Token}}"><button type="submit" style="padding:10px 18px;">Confirmar baja</button></form>
+{{end}}</body></html>`))
+
+// BajaNotificacionHandler: GET muestra la confirmación; POST (botón o List-Unsubscribe-Post) da de baja
+func BajaNotificacionHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("t")
+		if r.Method == http.MethodPost {
+			if err := s.Unsubscribe(token); err != nil {
+				if errors.Is(err, service.ErrBajaInvalida) {
+					http.Error(w, err.Error(), http.StatusBadRequest)
+					return
+				}
+				writeServiceError(w, err)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		paginaBaja.Execute(w, struct {
+			Token string
+			Hecho bool
+		}{Token: token, Hecho: r.Method == http.MethodPost})
+	}
+}
@@ -0,0 +1,110 @@
+/**
+ * ARCHIVO: reuniones.go
+ * UBICACIÓN: internal/handlers/reuniones.go
+ * DESCRIPCIÓN: Endpoints de reuniones: abrir y finalizar (ancianos), la reunión en
+ * curso con su sala de presencia y el registro de presentes de una reunión.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"gestion-congregacion/backend/internal/service"
+)
+
+// writeReunionError traduce los errores propios de reuniones y delega el resto
+func writeReunionError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrTipoReunionInvalido):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrReunionNoEncontrada):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, service.ErrReunionEnCurso):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		writeServiceError(w, err)
+	}
+}
+
+// reunionID lee el {id} de la ruta
+func reunionID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		http.Error(w, "id de reunión inválido", http.StatusBadRequest)
+		return 0, false
+	}
+	return id, true
+}
+
+// IniciarReunionHandler: Un anciano abre la reunión de su congregación
+func IniciarReunionHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Tipo string `json:"tipo"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		m, err := s.StartMeeting(SesionFromContext(r).PersonaID, req.Tipo)
+		if err != nil {
+			writeReunionError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(m)
+	}
+}
+
+// ReunionEnCursoHandler: La reunión abierta y su sala (con el total en vivo para ancianos)
+func ReunionEnCursoHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.GetCurrentMeeting(SesionFromContext(r).PersonaID)
+		if err != nil {
+			writeReunionError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	}
+}
+
+// FinalizarReunionHandler: Cierra la reunión y registra a los presentes
+func FinalizarReunionHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := reunionID(w, r)
+		if !ok {
+			return
+		}
+		m, err := s.EndMeeting(SesionFromContext(r).PersonaID, id)
+		if err != nil {
+			writeReunionError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m)
+	}
+}
+
+// RegistroReunionHandler: Reunión con la lista de presentes registrados
+func RegistroReunionHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		id, ok := reunionID(w, r)
+		if !ok {
+			return
+		}
+		reg, err := s.GetMeetingRecord(SesionFromContext(r).PersonaID, id)
+		if err != nil {
+			writeReunionError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(reg)
+	}
+}
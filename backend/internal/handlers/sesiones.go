@@ -0,0 +1,101 @@
+/**
+ * ARCHIVO: sesiones.go
+ * UBICACIÓN: internal/handlers/sesiones.go
+ * DESCRIPCIÓN: Endpoints de "Dispositivos conectados". El usuario gestiona
+ * sus propias sesiones; el admin local, las de su congregación.
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"gestion-congregacion/backend/internal/service"
+	"net/http"
+	"strconv"
+)
+
+// writeServiceError traduce los errores de sesiones y administración a códigos HTTP
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrSinPermiso):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrSesionInvalida), errors.Is(err, service.ErrDifusionNoEncontrada):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	default:
+		http.Error(w, "Error al procesar la solicitud", http.StatusInternalServerError)
+	}
+}
+
+// ListSesionesHandler: Lista los dispositivos con sesión activa del usuario actual
+func ListSesionesHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		actual := SesionFromContext(r)
+		lista, err := s.ListSessions(actual.PersonaID, actual.ID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(lista)
+	}
+}
+
+// RevocarSesionHandler: El usuario cierra uno de sus dispositivos
+func RevocarSesionHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			SesionID string `json:"sesion_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SesionID == "" {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		actual := SesionFromContext(r)
+		if err := s.RevokeSession(actual.PersonaID, req.SesionID); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
+
+// AdminListSesionesHandler: El admin local consulta los dispositivos de un miembro (?persona_id=)
+func AdminListSesionesHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		miembroID, err := strconv.Atoi(r.URL.Query().Get("persona_id"))
+		if err != nil {
+			http.Error(w, "persona_id inválido", http.StatusBadRequest)
+			return
+		}
+
+		lista, err := s.ListMemberSessions(SesionFromContext(r).PersonaID, miembroID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(lista)
+	}
+}
+
+// AdminRevocarSesionHandler: El admin local cierra la sesión de un miembro (ej: teléfono perdido)
+func AdminRevocarSesionHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			PersonaID int    `json:"persona_id"`
+			SesionID  string `json:"sesion_id"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.SesionID == "" {
+			http.Error(w, "JSON inválido", http.StatusBadRequest)
+			return
+		}
+
+		if err := s.RevokeMemberSession(SesionFromContext(r).PersonaID, req.PersonaID, req.SesionID); err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+}
@@ -0,0 +1,113 @@
+/**
+ * ARCHIVO: tiempo_real.go
+ * UBICACIÓN: internal/handlers/tiempo_real.go
+ * DESCRIPCIÓN: Entrada al tiempo real: WebSocket (/ws) o, donde está bloqueado, SSE
+ * (/api/eventos) con sus comandos por POST. Va detrás de AuthMiddleware: solo se
+ * conectan sesiones activas, la conexión se registra con la identidad de la llave y
+ * el hub revalida la sesión mientras dura.
+ * El esquema del protocolo es público (/api/eventos/esquema).
+ */
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"gestion-congregacion/backend/internal/service"
+	"gestion-congregacion/backend/internal/ws"
+)
+
+// clienteTiempoReal arma la identidad de la conexión desde la llave de la sesión
+func clienteTiempoReal(s *service.Service, w http.ResponseWriter, r *http.Request) (ws.Cliente, bool) {
+	claims := ClaimsFromContext(r)
+	if claims == nil {
+		http.Error(w, "Sesión expirada o no autorizada", http.StatusUnauthorized)
+		return ws.Cliente{}, false
+	}
+
+	cli := ws.Cliente{
+		UsuarioID:      claims.Subject,
+		PersonaID:      claims.PersonaID,
+		CongregacionID: claims.CongregacionID,
+	}
+	modulos, err := s.ModulosDeUsuario(claims.Subject, claims.CongregacionID)
+	if err != nil {
+		// Sin módulos sigue recibiendo los avisos personales y de la congregación
+		log.Println("❌ Error al leer los módulos del usuario:", err)
+	}
+	cli.Modulos = modulos
+	cli.Anciano = s.EsAnciano(claims.PersonaID)
+	// Revocar la sesión o suspender la cuenta corta también las conexiones abiertas
+	cli.Vigente = func() bool {
+		_, err := s.ValidateSession(claims)
+		return err == nil
+	}
+	return cli, true
+}
+
+// WsHandler registra la conexión en las salas de su usuario, persona, congregación y módulos
+func WsHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if cli, ok := clienteTiempoReal(s, w, r); ok {
+			ws.Serve(w, r, cli)
+		}
+	}
+}
+
+// SseHandler: Los mismos eventos que el WebSocket como Server-Sent Events
+func SseHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if cli, ok := clienteTiempoReal(s, w, r); ok {
+			ws.ServeSSE(w, r, cli)
+		}
+	}
+}
+
+// ComandoTiempoRealHandler: subscribe/unsubscribe/ack para una conexión SSE propia
+func ComandoTiempoRealHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		cli, ok := clienteTiempoReal(s, w, r)
+		if !ok {
+			return
+		}
+		var msg ws.MensajeCliente
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			http.Error(w, "Mensaje inválido", http.StatusBadRequest)
+			return
+		}
+
+		err := ws.Comando(cli, r.PathValue("conexion"), msg)
+		switch {
+		case errors.Is(err, ws.ErrConexionDesconocida):
+			http.Error(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, ws.ErrMensajeInvalido):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case err != nil:
+			writeServiceError(w, err)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}
+}
+
+// EsquemaEventosHandler publica el JSON Schema de los eventos y mensajes del cliente
+func EsquemaEventosHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/schema+json")
+	w.Write(ws.Esquema())
+}
+
+// MetricasTiempoRealHandler: Conectados, salas y envíos del hub de esta instancia
+func MetricasTiempoRealHandler(s *service.Service) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		m, err := s.RealtimeMetrics(SesionFromContext(r).PersonaID)
+		if err != nil {
+			writeServiceError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(m)
+	}
+}
@@ -0,0 +1,63 @@
+/**
+ * ARCHIVO: markdown.go
+ * UBICACIÓN: internal/markdown/markdown.go
+ * DESCRIPCIÓN: Convierte el Markdown que escriben los admins (boletines, anuncios,
+ * cuerpos de correo) a HTML seguro. goldmark descarta el HTML crudo y luego una
+ * política UGC de bluemonday deja pasar solo una lista cerrada de etiquetas.
+ * Los enlaces externos se abren en otra pestaña con rel="nofollow noopener".
+ */
+
+package markdown
+
+import (
+	"bytes"
+	"regexp"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+	"github.com/yuin/goldmark/renderer/html"
+)
+
+// conversor: CommonMark + tachado y autoenlaces; cada salto de línea se respeta
+// (los boletines viejos eran texto plano con saltos de línea)
+var conversor = goldmark.New(
+	goldmark.WithExtensions(extension.Strikethrough, extension.Linkify),
+	goldmark.WithRendererOptions(html.WithHardWraps()),
+)
+
+// politica es segura para uso concurrente una vez armada
+var politica = nuevaPolitica()
+
+// nuevaPolitica: Subconjunto de la política UGC (sin imágenes, tablas ni estilos)
+func nuevaPolitica() *bluemonday.Policy {
+	p := bluemonday.NewPolicy()
+	p.AllowElements("p", "br", "hr", "strong", "b", "em", "i", "del", "s", "blockquote", "pre", "code")
+	p.AllowElements("h1", "h2", "h3", "h4", "h5", "h6")
+	p.AllowLists()
+
+	// Enlaces: solo http(s), mailto y relativos
+	p.AllowAttrs("href").OnElements("a")
+	p.AllowURLSchemes("http", "https", "mailto")
+	p.AllowRelativeURLs(true)
+	p.RequireParseableURLs(true)
+	p.RequireNoFollowOnFullyQualifiedLinks(true)
+	p.AddTargetBlankToFullyQualifiedLinks(true)
+
+	// Clase del resaltado de bloques de código (```go)
+	p.AllowAttrs("class").Matching(regexp.MustCompile(`^language-[\w-]+$`)).OnElements("code")
+	return p
+}
+
+// Render devuelve el HTML saneado del texto Markdown ("" si no hay texto)
+func Render(src string) string {
+	if src == "" {
+		return ""
+	}
+	var b bytes.Buffer
+	if err := conversor.Convert([]byte(src), &b); err != nil {
+		// goldmark solo falla si falla el writer; un bytes.Buffer no lo hace
+		return politica.Sanitize(src)
+	}
+	return string(politica.SanitizeBytes(b.Bytes()))
+}
@@ -0,0 +1,50 @@
+/**
+ * ARCHIVO: markdown_test.go
+ * UBICACIÓN: backend/internal/markdown/markdown_test.go
+ * DESCRIPCIÓN: Pruebas del formato permitido y del saneado de HTML y enlaces.
+ */
+
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderFormatoPermitido(t *testing.T) {
+	html := Render("# Aviso\n\n1. Uno\n2. *Dos*\n\nLínea\nsiguiente ~~vieja~~")
+	for _, esperado := range []string{"<h1>Aviso</h1>", "<ol>", "<li><em>Dos</em></li>", "Línea<br>", "<del>vieja</del>"} {
+		if !strings.Contains(html, esperado) {
+			t.Errorf("Falta %q en:\n%s", esperado, html)
+		}
+	}
+}
+
+func TestRenderSaneaHTMLyEnlaces(t *testing.T) {
+	casos := []string{
+		`<script>alert(1)</script>`,
+		`<img src=x onerror=alert(1)>`,
+		`[clic](javascript:alert(1))`,
+		`<a href="https://x.org" onclick="alert(1)">x</a>`,
+		"<iframe src=\"https://x.org\"></iframe>",
+	}
+	for _, c := range casos {
+		html := Render(c)
+		for _, prohibido := range []string{"<script", "onerror", "javascript:", "onclick", "<iframe", "<img"} {
+			if strings.Contains(html, prohibido) {
+				t.Errorf("FALLO DE SEGURIDAD: %q sobrevivió en %q -> %q", prohibido, c, html)
+			}
+		}
+	}
+
+	html := Render("Ver https://jw.org y [el programa](/programa)")
+	if !strings.Contains(html, `<a href="https://jw.org" rel="nofollow noopener" target="_blank">`) {
+		t.Errorf("El enlace externo debía abrirse aparte con rel=noopener: %s", html)
+	}
+	if !strings.Contains(html, `<a href="/programa">`) {
+		t.Errorf("El enlace interno debía quedar tal cual: %s", html)
+	}
+	if Render("") != "" {
+		t.Errorf("Sin texto no hay HTML")
+	}
+}
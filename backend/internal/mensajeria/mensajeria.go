@@ -0,0 +1,196 @@
+/**
+ * ARCHIVO: mensajeria.go
+ * UBICACIÓN: internal/mensajeria/mensajeria.go
+ * DESCRIPCIÓN: Canales de mensajería al teléfono (SMS y WhatsApp) intercambiables.
+ * El servicio solo conoce la interfaz Notifier; Twilio es el proveedor real y Fake
+ * registra los mensajes en memoria para pruebas y desarrollo local.
+ */
+
+package mensajeria
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Canal: Medio elegido por el miembro para recibir códigos (core_personas.canal_preferido)
+type Canal string
+
+const (
+	CanalEmail    Canal = "email"
+	CanalSMS      Canal = "sms"
+	CanalWhatsApp Canal = "whatsapp"
+)
+
+// Valido indica si el valor es un canal conocido
+func (c Canal) Valido() bool {
+	return c == CanalEmail || c == CanalSMS || c == CanalWhatsApp
+}
+
+// TwilioAPIURL es la URL base oficial (sobrescribible con TWILIO_API_URL)
+const TwilioAPIURL = "https://api.twilio.com/2010-04-01"
+
+var (
+	ErrSinCredenciales = errors.New("credenciales del proveedor de mensajería no configuradas")
+	ErrSinTelefono     = errors.New("el miembro no tiene un teléfono válido registrado")
+	ErrRechazado       = errors.New("mensaje rechazado por el proveedor")
+)
+
+// Notifier envía un mensaje de texto a un teléfono en formato E.164
+type Notifier interface {
+	Send(ctx context.Context, telefono, texto string) error
+}
+
+// Twilio: Envía SMS o WhatsApp a través de la API de mensajes de Twilio
+type Twilio struct {
+	canal      Canal
+	accountSID string
+	authToken  string
+	from       string
+	apiURL     string
+	client     *http.Client
+}
+
+// NewTwilioSMS crea el canal SMS. apiURL vacío usa la URL oficial.
+func NewTwilioSMS(accountSID, authToken, from, apiURL string) *Twilio {
+	return newTwilio(CanalSMS, accountSID, authToken, from, apiURL)
+}
+
+// NewTwilioWhatsApp crea el canal WhatsApp (Twilio antepone "whatsapp:" a origen y destino)
+func NewTwilioWhatsApp(accountSID, authToken, from, apiURL string) *Twilio {
+	return newTwilio(CanalWhatsApp, accountSID, authToken, from, apiURL)
+}
+
+func newTwilio(canal Canal, accountSID, authToken, from, apiURL string) *Twilio {
+	if apiURL == "" {
+		apiURL = TwilioAPIURL
+	}
+	return &Twilio{
+		canal:      canal,
+		accountSID: accountSID,
+		authToken:  authToken,
+		from:       from,
+		apiURL:     strings.TrimSuffix(apiURL, "/"),
+		client:     &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// direccion arma el destino según el canal ("+549..." o "whatsapp:+549...")
+func (t *Twilio) direccion(telefono string) string {
+	if t.canal == CanalWhatsApp {
+		return "whatsapp:" + telefono
+	}
+	return telefono
+}
+
+// Send publica el mensaje en /Accounts/{sid}/Messages.json con autenticación básica
+func (t *Twilio) Send(ctx context.Context, telefono, texto string) error {
+	if t.accountSID == "" || t.authToken == "" || t.from == "" {
+		log.Printf("⚠️  ALERTA MENSAJERÍA: credenciales de %s no configuradas", t.canal)
+		return ErrSinCredenciales
+	}
+	if telefono == "" {
+		return ErrSinTelefono
+	}
+
+	data := url.Values{}
+	data.Set("To", t.direccion(telefono))
+	data.Set("From", t.direccion(t.from))
+	data.Set("Body", texto)
+
+	endpoint := fmt.Sprintf("%s/Accounts/%s/Messages.json", t.apiURL, url.PathEscape(t.accountSID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(data.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(t.accountSID, t.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		log.Printf("❌ ERROR de red al enviar %s: %v", t.canal, err)
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var result struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		json.NewDecoder(resp.Body).Decode(&result)
+		log.Printf("🚫 %s RECHAZADO por Twilio (%d): %s", t.canal, result.Code, result.Message)
+		return fmt.Errorf("%w: %d %s", ErrRechazado, result.Code, result.Message)
+	}
+	return nil
+}
+
+// Mensaje registra un envío recibido por el proveedor falso
+type Mensaje struct {
+	Canal    Canal
+	Telefono string
+	Texto    string
+}
+
+// Fake: Proveedor local sin red. Guarda los mensajes para que las pruebas los inspeccionen.
+type Fake struct {
+	canal Canal
+
+	mu       sync.Mutex
+	enviados []Mensaje
+}
+
+// NewFake crea un proveedor falso para el canal indicado
+func NewFake(canal Canal) *Fake {
+	return &Fake{canal: canal}
+}
+
+// Send registra el mensaje sin enviarlo
+func (f *Fake) Send(_ context.Context, telefono, texto string) error {
+	if telefono == "" {
+		return ErrSinTelefono
+	}
+	f.mu.Lock()
+	f.enviados = append(f.enviados, Mensaje{Canal: f.canal, Telefono: telefono, Texto: texto})
+	f.mu.Unlock()
+	return nil
+}
+
+// Enviados devuelve una copia de los mensajes registrados
+func (f *Fake) Enviados() []Mensaje {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]Mensaje(nil), f.enviados...)
+}
+
+// NewFromEnv arma los canales telefónicos habilitados según SMS_PROVIDER y WHATSAPP_PROVIDER
+// ("twilio" o "fake"). Un canal sin proveedor no se registra y el servicio recurre al email.
+func NewFromEnv() map[Canal]Notifier {
+	sid, token, apiURL := os.Getenv("TWILIO_ACCOUNT_SID"), os.Getenv("TWILIO_AUTH_TOKEN"), os.Getenv("TWILIO_API_URL")
+
+	canales := map[Canal]Notifier{}
+	switch strings.ToLower(os.Getenv("SMS_PROVIDER")) {
+	case "twilio":
+		canales[CanalSMS] = NewTwilioSMS(sid, token, os.Getenv("TWILIO_SMS_FROM"), apiURL)
+	case "fake":
+		log.Println("⚠️  SMS en modo FAKE: usar solo en desarrollo local")
+		canales[CanalSMS] = NewFake(CanalSMS)
+	}
+	switch strings.ToLower(os.Getenv("WHATSAPP_PROVIDER")) {
+	case "twilio":
+		canales[CanalWhatsApp] = NewTwilioWhatsApp(sid, token, os.Getenv("TWILIO_WHATSAPP_FROM"), apiURL)
+	case "fake":
+		log.Println("⚠️  WhatsApp en modo FAKE: usar solo en desarrollo local")
+		canales[CanalWhatsApp] = NewFake(CanalWhatsApp)
+	}
+	return canales
+}
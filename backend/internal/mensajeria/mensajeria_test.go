@@ -0,0 +1,85 @@
+/**
+ * ARCHIVO: mensajeria_test.go
+ * UBICACIÓN: backend/internal/mensajeria/mensajeria_test.go
+ * DESCRIPCIÓN: Pruebas de los canales SMS/WhatsApp contra un servidor local (sin Internet).
+ */
+
+package mensajeria
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// servidorTwilio simula la API de mensajes y registra el formulario recibido
+func servidorTwilio(t *testing.T, status int, recibido *map[string]string) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		usuario, clave, _ := r.BasicAuth()
+		*recibido = map[string]string{
+			"path": r.URL.Path,
+			"auth": usuario + ":" + clave,
+			"to":   r.PostForm.Get("To"),
+			"from": r.PostForm.Get("From"),
+			"body": r.PostForm.Get("Body"),
+		}
+		w.WriteHeader(status)
+		w.Write([]byte(`{"code":21211,"message":"Invalid 'To' Phone Number"}`))
+	}))
+}
+
+func TestTwilioWhatsAppUsaPrefijo(t *testing.T) {
+	var recibido map[string]string
+	srv := servidorTwilio(t, http.StatusCreated, &recibido)
+	defer srv.Close()
+
+	n := NewTwilioWhatsApp("AC123", "secreto", "+14155238886", srv.URL)
+	if err := n.Send(context.Background(), "+5491141234567", "Su código es 123456"); err != nil {
+		t.Fatalf("Se esperaba envío exitoso, se obtuvo: %v", err)
+	}
+	if recibido["path"] != "/Accounts/AC123/Messages.json" || recibido["auth"] != "AC123:secreto" {
+		t.Errorf("Petición mal formada: %v", recibido)
+	}
+	if recibido["to"] != "whatsapp:+5491141234567" || recibido["from"] != "whatsapp:+14155238886" {
+		t.Errorf("WhatsApp debe anteponer 'whatsapp:' a origen y destino: %v", recibido)
+	}
+}
+
+func TestTwilioSMSRechazado(t *testing.T) {
+	var recibido map[string]string
+	srv := servidorTwilio(t, http.StatusBadRequest, &recibido)
+	defer srv.Close()
+
+	n := NewTwilioSMS("AC123", "secreto", "+15005550006", srv.URL)
+	err := n.Send(context.Background(), "+5491141234567", "hola")
+	if !errors.Is(err, ErrRechazado) {
+		t.Errorf("Se esperaba ErrRechazado, se obtuvo: %v", err)
+	}
+	if recibido["to"] != "+5491141234567" {
+		t.Errorf("SMS no debe modificar el destino: %v", recibido["to"])
+	}
+}
+
+func TestTwilioSinCredenciales(t *testing.T) {
+	n := NewTwilioSMS("", "", "", "http://127.0.0.1:0")
+	if err := n.Send(context.Background(), "+5491141234567", "hola"); !errors.Is(err, ErrSinCredenciales) {
+		t.Errorf("Sin credenciales no debe salir a la red, se obtuvo: %v", err)
+	}
+}
+
+func TestFakeRegistraMensajes(t *testing.T) {
+	f := NewFake(CanalSMS)
+	f.Send(context.Background(), "+5491141234567", "Su código es 123456")
+	if err := f.Send(context.Background(), "", "sin destino"); !errors.Is(err, ErrSinTelefono) {
+		t.Errorf("Un destino vacío debe rechazarse, se obtuvo: %v", err)
+	}
+
+	enviados := f.Enviados()
+	if len(enviados) != 1 || enviados[0].Canal != CanalSMS || enviados[0].Texto != "Su código es 123456" {
+		t.Errorf("Mensajes registrados inesperados: %+v", enviados)
+	}
+}
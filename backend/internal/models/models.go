@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type Publicacion struct {
 	ID                string `gorm:"primaryKey" json:"id"`
 	NombrePublicacion string `json:"nombre_publicacion"`
@@ -8,13 +10,37 @@ type Publicacion struct {
 	URLPortada        string `json:"url_portada"`
 }
 
+// Estados de un pedido de literatura (pub_pedidos)
+const (
+	PedidoPendiente = "pendiente"
+	PedidoSinStock  = "sin stock"
+	PedidoListo     = "listo" // Apartado: el hermano ya puede retirarlo
+	PedidoCancelado = "cancelado"
+	PedidoEntregado = "entregado"
+)
+
+// Pedido: Publicación solicitada por un hermano
+type Pedido struct {
+	ID                int        `json:"id" gorm:"primaryKey;column:id"`
+	CongregacionID    string     `json:"congregacion_id" gorm:"column:congregacion_id"`
+	PersonaID         int        `json:"persona_id" gorm:"column:persona_id"`
+	PublicacionID     string     `json:"publicacion_id" gorm:"column:publicacion_id"`
+	NombrePublicacion string     `json:"nombre_publicacion" gorm:"column:nombre_publicacion"`
+	Cantidad          int        `json:"cantidad" gorm:"column:cantidad"`
+	Estado            string     `json:"estado" gorm:"column:estado"`
+	ListoAt           *time.Time `json:"listo_at,omitempty" gorm:"column:listo_at"`
+}
+
 type Usuario struct {
 	ID                 string `gorm:"primaryKey" json:"id"`
 	PersonaID          int    `json:"persona_id" gorm:"column:persona_id"`
 	NombreCompleto     string `json:"nombre_completo" gorm:"column:nombre_completo"`
 	Email              string `json:"email" gorm:"column:email"`
 	Contacto           string `json:"contacto" gorm:"column:contacto"`
-	Estado             string `json:"estado" gorm:"column:estado"` // ALTA/BAJA
+	CanalPreferido     string `json:"canal_preferido" gorm:"column:canal_preferido"` // email/sms/whatsapp
+	Idioma             string `json:"idioma" gorm:"column:idioma"`                   // Idioma de los correos
+	Estado             string `json:"estado" gorm:"column:estado"`                   // ALTA/BAJA
+	EstadoCuenta       string `json:"estado_cuenta" gorm:"column:estado_cuenta"`     // activa/suspendida
 	FotoURL            string `json:"foto_url" gorm:"column:foto_url"`
 	CongregacionID     string `json:"congregacion_id"`
 	CongregacionNombre string `json:"congregacion_nombre" gorm:"column:congregacion_nombre"`
@@ -41,3 +67,328 @@ type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 }
+
+// Sesion representa un dispositivo con sesión iniciada (tabla core_sesiones)
+type Sesion struct {
+	ID          string     `json:"id" gorm:"primaryKey;column:id"`
+	PersonaID   int        `json:"persona_id" gorm:"column:persona_id"`
+	UsuarioID   *string    `json:"usuario_id,omitempty" gorm:"column:usuario_id"`
+	UserAgent   string     `json:"user_agent" gorm:"column:user_agent"`
+	IP          string     `json:"ip" gorm:"column:ip"`
+	CreadoAt    time.Time  `json:"creado_at" gorm:"column:creado_at"`
+	UltimoUsoAt time.Time  `json:"ultimo_uso_at" gorm:"column:ultimo_uso_at"`
+	ExpiraAt    time.Time  `json:"expira_at" gorm:"column:expira_at"`
+	RevocadaAt  *time.Time `json:"revocada_at,omitempty" gorm:"column:revocada_at"`
+
+	// Actual marca la sesión desde la que se hace la consulta (no se persiste)
+	Actual bool `json:"actual" gorm:"-"`
+}
+
+// Acciones registradas en core_auditoria
+const (
+	AccionSuspension   = "SUSPENSION_CUENTA"
+	AccionReactivacion = "REACTIVACION_CUENTA"
+	AccionDesbloqueo   = "DESBLOQUEO_CUENTA"
+)
+
+// Auditoria: Registro inmutable de acciones administrativas sobre una cuenta (tabla core_auditoria)
+type Auditoria struct {
+	ID             int64     `json:"id" gorm:"primaryKey;column:id"`
+	ActorPersonaID int       `json:"actor_persona_id" gorm:"column:actor_persona_id"`
+	Accion         string    `json:"accion" gorm:"column:accion"`
+	PersonaID      int       `json:"persona_id" gorm:"column:persona_id"`
+	CongregacionID string    `json:"congregacion_id" gorm:"column:congregacion_id"`
+	Detalle        string    `json:"detalle" gorm:"column:detalle"`
+	CreadoAt       time.Time `json:"creado_at" gorm:"column:creado_at"`
+}
+
+// Estados de un correo en core_email_outbox
+const (
+	EmailPendiente = "pendiente"
+	EmailEnviado   = "enviado"
+	EmailFallido   = "fallido" // Dead-letter: agotó los reintentos o tuvo un fallo permanente
+)
+
+// EmailOutbox: Correo encolado junto con el cambio que lo origina (tabla core_email_outbox)
+type EmailOutbox struct {
+	ID               int64      `json:"id" gorm:"primaryKey;column:id"`
+	Destinatario     string     `json:"destinatario" gorm:"column:destinatario"`
+	Asunto           string     `json:"asunto" gorm:"column:asunto"`
+	HTML             string     `json:"-" gorm:"column:html"`
+	Texto            string     `json:"-" gorm:"column:texto"`
+	Sensible         bool       `json:"sensible" gorm:"column:sensible"` // PINs: el cuerpo se borra al terminar
+	Estado           string     `json:"estado" gorm:"column:estado"`
+	Intentos         int        `json:"intentos" gorm:"column:intentos"`
+	ProximoIntentoAt time.Time  `json:"proximo_intento_at" gorm:"column:proximo_intento_at"`
+	UltimoError      string     `json:"ultimo_error,omitempty" gorm:"column:ultimo_error"`
+	CreadoAt         time.Time  `json:"creado_at" gorm:"column:creado_at"`
+	EnviadoAt        *time.Time `json:"enviado_at,omitempty" gorm:"column:enviado_at"`
+	DifusionID       *int64     `json:"difusion_id,omitempty" gorm:"column:difusion_id"` // NULL: correo transaccional
+	PersonaID        *int       `json:"persona_id,omitempty" gorm:"column:persona_id"`
+	BajaURL          string     `json:"-" gorm:"column:baja_url"` // Cabecera List-Unsubscribe (vacía en transaccionales)
+}
+
+// Estados de un boletín de seguridad
+const (
+	BoletinBorrador  = "borrador"
+	BoletinPublicado = "publicado"
+)
+
+// Boletin: Boletín de seguridad digital (tabla core_seguridad_info; el título vive en 'contenido')
+type Boletin struct {
+	ID               int        `json:"id" gorm:"primaryKey;column:id"`
+	Titulo           string     `json:"titulo" gorm:"column:contenido"`
+	DescripcionLarga string     `json:"descripcion_larga" gorm:"column:descripcion_larga"` // Markdown tal como lo escribió el admin
+	DescripcionHTML  string     `json:"descripcion_html" gorm:"column:descripcion_html"`   // Render saneado (internal/markdown)
+	Estado           string     `json:"estado" gorm:"column:estado"`
+	Version          int        `json:"version" gorm:"column:version"`
+	CreadoPor        *int       `json:"creado_por,omitempty" gorm:"column:creado_por"`
+	CongregacionID   *string    `json:"congregacion_id,omitempty" gorm:"column:congregacion_id"` // Congregación que lo administra (NULL: boletín anterior, lo gestiona cualquier admin local)
+	UpdatedAt        time.Time  `json:"updated_at" gorm:"column:updated_at"`
+	PublicadoAt      *time.Time `json:"publicado_at,omitempty" gorm:"column:publicado_at"`
+}
+
+// VersionBoletin: Cada guardado del boletín (tabla core_seguridad_info_historial)
+type VersionBoletin struct {
+	ID               int64     `json:"id" gorm:"primaryKey;column:id"`
+	BoletinID        int       `json:"boletin_id" gorm:"column:boletin_id"`
+	Version          int       `json:"version" gorm:"column:version"`
+	Titulo           string    `json:"titulo" gorm:"column:contenido"`
+	DescripcionLarga string    `json:"descripcion_larga" gorm:"column:descripcion_larga"`
+	DescripcionHTML  string    `json:"descripcion_html" gorm:"column:descripcion_html"`
+	Estado           string    `json:"estado" gorm:"column:estado"`
+	EditadoPor       *int      `json:"editado_por,omitempty" gorm:"column:editado_por"`
+	EditadoAt        time.Time `json:"editado_at" gorm:"column:editado_at"`
+}
+
+// AcuseBoletin: Lectura y confirmación de un boletín por persona (tabla core_seguridad_acuses)
+type AcuseBoletin struct {
+	BoletinID    int        `json:"boletin_id" gorm:"primaryKey;column:boletin_id"`
+	PersonaID    int        `json:"persona_id" gorm:"primaryKey;column:persona_id"`
+	LeidoAt      *time.Time `json:"leido_at,omitempty" gorm:"column:leido_at"`           // Primera vez que lo abrió
+	ConfirmadoAt *time.Time `json:"confirmado_at,omitempty" gorm:"column:confirmado_at"` // Pulsó "Entendido"
+	RecordadoAt  *time.Time `json:"recordado_at,omitempty" gorm:"column:recordado_at"`   // Último recordatorio enviado
+}
+
+// LecturaGrupo: Acuses de los miembros activos de un grupo de servicio (Grupo nil: sin grupo asignado)
+type LecturaGrupo struct {
+	Grupo                *int    `json:"grupo"`
+	Miembros             int     `json:"miembros"`
+	Leidos               int     `json:"leidos"`
+	Confirmados          int     `json:"confirmados"`
+	PorcentajeLeido      float64 `json:"porcentaje_leido"`
+	PorcentajeConfirmado float64 `json:"porcentaje_confirmado"`
+}
+
+// LecturasBoletin: Resumen de acuses de un boletín en una congregación
+type LecturasBoletin struct {
+	BoletinID      int            `json:"boletin_id"`
+	CongregacionID string         `json:"congregacion_id"`
+	Congregacion   LecturaGrupo   `json:"congregacion"`
+	Grupos         []LecturaGrupo `json:"grupos"`
+}
+
+// Estados de una difusión
+const (
+	DifusionEnCurso    = "en_curso"
+	DifusionCompletada = "completada"
+)
+
+// Difusion: Envío masivo de un boletín (tabla core_difusiones). Cada destinatario es una
+// fila de core_email_outbox, por eso el envío se retoma solo tras un reinicio.
+type Difusion struct {
+	ID           int64      `json:"id" gorm:"primaryKey;column:id"`
+	BoletinID    int        `json:"boletin_id" gorm:"column:boletin_id"`
+	Titulo       string     `json:"titulo" gorm:"column:titulo"`
+	Total        int        `json:"total" gorm:"column:total"`
+	Estado       string     `json:"estado" gorm:"column:estado"`
+	CreadoPor    int        `json:"creado_por" gorm:"column:creado_por"`
+	CreadoAt     time.Time  `json:"creado_at" gorm:"column:creado_at"`
+	CompletadoAt *time.Time `json:"completado_at,omitempty" gorm:"column:completado_at"`
+}
+
+// FalloDifusion: Destinatario cuyo correo falló (en reintento o en dead-letter)
+type FalloDifusion struct {
+	PersonaID    int    `json:"persona_id"`
+	Nombre       string `json:"nombre"`
+	Destinatario string `json:"destinatario"`
+	Estado       string `json:"estado"`
+	Intentos     int    `json:"intentos"`
+	UltimoError  string `json:"ultimo_error"`
+}
+
+// ProgresoDifusion: Resumen para el endpoint de seguimiento de un boletín
+type ProgresoDifusion struct {
+	Difusion
+	Enviados   int             `json:"enviados"`
+	Pendientes int             `json:"pendientes"`
+	Fallidos   int             `json:"fallidos"`
+	Fallos     []FalloDifusion `json:"fallos"`
+}
+
+// Categorías de avisos que el miembro puede dejar de recibir.
+// Los mensajes de la cuenta (PIN, bloqueo, suspensión) no tienen categoría: siempre se envían.
+const (
+	CategoriaSeguridad  = "seguridad"  // Boletines de seguridad digital
+	CategoriaAnuncios   = "anuncios"   // Anuncios de la congregación
+	CategoriaLiteratura = "literatura" // Publicaciones listas para retirar
+)
+
+// Canales por los que llega un aviso
+const (
+	CanalNotifEmail    = "email"
+	CanalNotifPush     = "push" // Notificación en tiempo real (WebSocket)
+	CanalNotifSMS      = "sms"
+	CanalNotifWhatsApp = "whatsapp"
+)
+
+var (
+	CategoriasNotificacion = []string{CategoriaSeguridad, CategoriaAnuncios, CategoriaLiteratura}
+	CanalesNotificacion    = []string{CanalNotifEmail, CanalNotifPush, CanalNotifSMS, CanalNotifWhatsApp}
+)
+
+// PreferenciaNotificacion: Un par categoría/canal (tabla core_preferencias_notificacion).
+// Sin fila, el aviso está activo: las preferencias solo registran cambios del miembro.
+type PreferenciaNotificacion struct {
+	PersonaID int       `json:"-" gorm:"column:persona_id"`
+	Categoria string    `json:"categoria" gorm:"column:categoria"`
+	Canal     string    `json:"canal" gorm:"column:canal"`
+	Activo    bool      `json:"activo" gorm:"column:activo"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// Tipos de reunión
+const (
+	ReunionEntreSemana = "entre_semana"
+	ReunionFinDeSemana = "fin_de_semana"
+)
+
+// Reunion: Una reunión de la congregación (tabla reu_reuniones). Mientras no está
+// finalizada, los miembros que entran a su sala en tiempo real cuentan como presentes.
+type Reunion struct {
+	ID             int        `json:"id" gorm:"primaryKey;column:id"`
+	CongregacionID string     `json:"congregacion_id" gorm:"column:congregacion_id"`
+	Tipo           string     `json:"tipo" gorm:"column:tipo"`
+	IniciadaAt     time.Time  `json:"iniciada_at" gorm:"column:iniciada_at"`
+	IniciadaPor    int        `json:"iniciada_por" gorm:"column:iniciada_por"`
+	FinalizadaAt   *time.Time `json:"finalizada_at,omitempty" gorm:"column:finalizada_at"`
+	FinalizadaPor  *int       `json:"finalizada_por,omitempty" gorm:"column:finalizada_por"`
+	Presentes      *int       `json:"presentes,omitempty" gorm:"column:presentes"` // Conectados al finalizar (foto de la presencia)
+}
+
+// PresenteReunion: Persona registrada como presente al finalizar (tabla reu_presentes)
+type PresenteReunion struct {
+	PersonaID int    `json:"persona_id"`
+	Nombre    string `json:"nombre"`
+	Grupo     *int   `json:"grupo"`
+}
+
+// PresenciaReunion: Reunión en curso con su sala y, para los ancianos, los conectados ahora
+type PresenciaReunion struct {
+	Reunion   Reunion `json:"reunion"`
+	Sala      string  `json:"sala"`
+	Presentes *int    `json:"presentes,omitempty"`
+}
+
+// RegistroReunion: Reunión finalizada con la lista de presentes
+type RegistroReunion struct {
+	Reunion
+	Asistentes []PresenteReunion `json:"asistentes"`
+}
+
+// Asistencia: Concurrencia de una reunión que informa el secretario (tabla reu_asistencia).
+// Hay una fila por congregación, fecha y tipo; volver a cargarla la corrige.
+type Asistencia struct {
+	ID             int       `json:"id" gorm:"primaryKey;column:id"`
+	CongregacionID string    `json:"congregacion_id" gorm:"column:congregacion_id"`
+	Fecha          time.Time `json:"fecha" gorm:"column:fecha"`
+	Tipo           string    `json:"tipo" gorm:"column:tipo"`
+	Presencial     int       `json:"presencial" gorm:"column:presencial"` // En el Salón del Reino
+	Video          int       `json:"video" gorm:"column:video"`           // Conectados por videoconferencia
+	RegistradoPor  int       `json:"registrado_por" gorm:"column:registrado_por"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// PromedioAsistencia: Resumen de un mes para un tipo de reunión (promedios redondeados)
+type PromedioAsistencia struct {
+	Mes                string `json:"mes"` // AAAA-MM
+	Tipo               string `json:"tipo"`
+	Reuniones          int    `json:"reuniones"`
+	TotalPresencial    int    `json:"total_presencial"`
+	TotalVideo         int    `json:"total_video"`
+	Total              int    `json:"total"`
+	PromedioPresencial int    `json:"promedio_presencial"`
+	PromedioVideo      int    `json:"promedio_video"`
+	Promedio           int    `json:"promedio"`
+}
+
+// InformeAsistencia: Promedios mensuales de la congregación entre dos meses (inclusive)
+type InformeAsistencia struct {
+	CongregacionID string               `json:"congregacion_id"`
+	Desde          string               `json:"desde"`
+	Hasta          string               `json:"hasta"`
+	Meses          []PromedioAsistencia `json:"meses"`
+}
+
+// Categorías del informe de servicio (según situacion_2 o el mes de precursor auxiliar)
+const (
+	CategoriaPublicador        = "publicador"
+	CategoriaPrecursorAuxiliar = "precursor_auxiliar"
+	CategoriaPrecursorRegular  = "precursor_regular"
+	CategoriaPrecursorEspecial = "precursor_especial"
+)
+
+// CategoriasInforme en el orden en que se presentan los totales
+var CategoriasInforme = []string{
+	CategoriaPublicador, CategoriaPrecursorAuxiliar, CategoriaPrecursorRegular, CategoriaPrecursorEspecial,
+}
+
+// InformeServicio: Informe mensual de predicación de una persona (tabla serv_informes).
+// Grupo y categoría se guardan como estaban al entregarlo.
+type InformeServicio struct {
+	ID               int       `json:"id" gorm:"primaryKey;column:id"`
+	CongregacionID   string    `json:"congregacion_id" gorm:"column:congregacion_id"`
+	PersonaID        int       `json:"persona_id" gorm:"column:persona_id"`
+	Mes              time.Time `json:"mes" gorm:"column:mes"` // Primer día del mes informado
+	Grupo            *int      `json:"grupo" gorm:"column:grupo"`
+	Categoria        string    `json:"categoria" gorm:"column:categoria"`
+	Participo        bool      `json:"participo" gorm:"column:participo"`
+	EstudiosBiblicos int       `json:"estudios_biblicos" gorm:"column:estudios_biblicos"`
+	Horas            *int      `json:"horas,omitempty" gorm:"column:horas"` // Solo precursores
+	Comentarios      string    `json:"comentarios" gorm:"column:comentarios"`
+	EnviadoAt        time.Time `json:"enviado_at" gorm:"column:enviado_at"`
+	UpdatedAt        time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+// PendienteInforme: Miembro activo que todavía no entregó el informe del mes
+type PendienteInforme struct {
+	PersonaID int    `json:"persona_id"`
+	Nombre    string `json:"nombre"`
+	Grupo     *int   `json:"grupo"`
+}
+
+// PendientesGrupo: Lo que ve el superintendente de grupo para un mes
+type PendientesGrupo struct {
+	Mes        string             `json:"mes"` // AAAA-MM
+	Grupo      int                `json:"grupo"`
+	Entregados int                `json:"entregados"`
+	Pendientes []PendienteInforme `json:"pendientes"`
+}
+
+// TotalCategoria: Suma de los informes de una categoría en el mes
+type TotalCategoria struct {
+	Categoria        string `json:"categoria"`
+	Informes         int    `json:"informes"`
+	Participaron     int    `json:"participaron"`
+	EstudiosBiblicos int    `json:"estudios_biblicos"`
+	Horas            int    `json:"horas"`
+}
+
+// TotalesInformes: Resumen de la congregación que prepara el secretario
+type TotalesInformes struct {
+	CongregacionID string           `json:"congregacion_id"`
+	Mes            string           `json:"mes"`
+	Categorias     []TotalCategoria `json:"categorias"`
+	Entregados     int              `json:"entregados"`
+	Pendientes     int              `json:"pendientes"`
+}
@@ -0,0 +1,239 @@
+/**
+ * ARCHIVO: plantillas.go
+ * UBICACIÓN: internal/plantillas/plantillas.go
+ * DESCRIPCIÓN: Correos localizados con html/template (escapado automático) y su
+ * alternativa en texto plano. Cada tipo de mensaje tiene su plantilla en correos/
+ * y sus textos por idioma en idiomas/<código>.json; un texto ausente cae al español.
+ * Los idiomas del frontend sin catálogo propio reciben el correo en inglés.
+ * Los idiomas RTL (árabe, hebreo) se maquetan con dir="rtl".
+ */
+
+package plantillas
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltpl "html/template"
+	"slices"
+	"strings"
+	"sync"
+	texttpl "text/template"
+
+	"gestion-congregacion/backend/internal/markdown"
+)
+
+//go:embed correos/*.html correos/*.txt idiomas/*.json
+var archivos embed.FS
+
+// IdiomaPorDefecto coincide con el fallbackLng del frontend
+const IdiomaPorDefecto = "es"
+
+// IdiomaRespaldo: Idioma de los correos para quien eligió un idioma sin traducir
+const IdiomaRespaldo = "en"
+
+// Tipo: Cada mensaje que el sistema envía por correo (o SMS, usando el texto plano)
+type Tipo string
+
+const (
+	PIN                   Tipo = "pin"
+	UsuarioRecuperado     Tipo = "usuario_recuperado"
+	Bloqueo               Tipo = "bloqueo"
+	CuentaSuspendida      Tipo = "cuenta_suspendida"
+	CuentaReactivada      Tipo = "cuenta_reactivada"
+	AvisoSeguridad        Tipo = "aviso_seguridad"
+	RecordatorioSeguridad Tipo = "recordatorio_seguridad" // Boletín sin confirmar tras N días
+	LiteraturaLista       Tipo = "literatura_lista"
+)
+
+var ErrTipoDesconocido = errors.New("plantilla de correo inexistente")
+
+// Soportados replica la lista de idiomas del frontend (src/i18n.js)
+var Soportados = []string{
+	"es", "en", "de", "fr", "pt", "it", "nl", "ru", "el", "ja", "zh-CN", "ko", "id", "vi",
+	"sw", "af", "sq", "am", "ar", "he", "ca", "zh-TW", "hr", "cs", "da", "ee", "fi", "ht",
+	"haw", "hi", "hu", "ilo", "jam", "lr", "mg", "ml", "no", "pap", "pcm", "pl", "ro", "nso",
+	"st", "sk", "sl", "sv", "tl", "ta", "th", "tr", "tw", "uk", "xh", "zu", "gn", "qu",
+}
+
+// Traducidos: Idiomas con catálogo en idiomas/<código>.json (el resto cae a IdiomaRespaldo)
+var Traducidos = []string{"es", "en", "pt", "fr", "ar", "he"}
+
+// idiomasRTL: Mismo criterio que el detector de dirección del frontend
+var idiomasRTL = map[string]bool{"ar": true, "he": true}
+
+// Datos: Valores que las plantillas pueden mostrar (todos se escapan al renderizar)
+type Datos struct {
+	Nombre      string
+	Usuario     string
+	PersonaID   int
+	PIN         string
+	Minutos     int
+	Intentos    int
+	IP          string
+	Titulo      string
+	Descripcion string
+	Publicacion string
+	Cantidad    int
+	BajaURL     string // Enlace firmado para dejar de recibir la categoría (solo avisos no transaccionales)
+}
+
+// Correo: Resultado listo para el outbox
+type Correo struct {
+	Asunto string
+	HTML   string
+	Texto  string
+}
+
+// vista agrega a los datos la información de maquetación
+type vista struct {
+	Datos
+	Idioma string
+	Dir    string // "ltr" o "rtl"
+	Inicio string // Alineación del texto: "left" o "right"
+}
+
+var (
+	cargaUnica sync.Once
+	catalogos  map[string]map[string]map[string]string // idioma -> sección -> clave -> texto
+	errCarga   error
+)
+
+// cargarCatalogos lee una sola vez todos los idiomas embebidos
+func cargarCatalogos() error {
+	cargaUnica.Do(func() {
+		catalogos = map[string]map[string]map[string]string{}
+		entradas, err := archivos.ReadDir("idiomas")
+		if err != nil {
+			errCarga = err
+			return
+		}
+		for _, e := range entradas {
+			b, err := archivos.ReadFile("idiomas/" + e.Name())
+			if err != nil {
+				errCarga = err
+				return
+			}
+			var cat map[string]map[string]string
+			if err := json.Unmarshal(b, &cat); err != nil {
+				errCarga = fmt.Errorf("idiomas/%s: %w", e.Name(), err)
+				return
+			}
+			catalogos[strings.TrimSuffix(e.Name(), ".json")] = cat
+		}
+	})
+	return errCarga
+}
+
+// Normalizar devuelve el idioma traducido en que se escribe el correo
+// ("pt-BR" -> "pt"; "de" -> "en" porque no tiene catálogo; desconocido -> "es")
+func Normalizar(idioma string) string {
+	idioma = strings.TrimSpace(idioma)
+	for _, s := range Soportados {
+		if strings.EqualFold(s, idioma) {
+			if slices.Contains(Traducidos, s) {
+				return s
+			}
+			return IdiomaRespaldo
+		}
+	}
+	if base, _, ok := strings.Cut(idioma, "-"); ok {
+		return Normalizar(base)
+	}
+	return IdiomaPorDefecto
+}
+
+// Valido indica si el código es uno de los idiomas del frontend
+func Valido(idioma string) bool {
+	for _, s := range Soportados {
+		if s == idioma {
+			return true
+		}
+	}
+	return false
+}
+
+// EsRTL indica si el idioma se escribe de derecha a izquierda
+func EsRTL(idioma string) bool {
+	return idiomasRTL[Normalizar(idioma)]
+}
+
+// texto busca la clave en el idioma pedido y, si falta, en español
+func texto(idioma string, tipo Tipo, clave string) string {
+	for _, cat := range []map[string]map[string]string{catalogos[idioma], catalogos[IdiomaPorDefecto]} {
+		if v, ok := cat[string(tipo)][clave]; ok {
+			return v
+		}
+		if v, ok := cat["comun"][clave]; ok {
+			return v
+		}
+	}
+	return clave
+}
+
+// funciones expone {{t "clave"}}: el texto del catálogo se completa con los datos
+// (text/template) y luego html/template escapa el resultado completo.
+// {{markdown}} es la única salida sin escapar y pasa por la política de internal/markdown.
+func funciones(idioma string, tipo Tipo, v *vista) map[string]interface{} {
+	return map[string]interface{}{
+		"t": func(clave string) (string, error) {
+			tpl, err := texttpl.New(clave).Parse(texto(idioma, tipo, clave))
+			if err != nil {
+				return "", err
+			}
+			var b strings.Builder
+			if err := tpl.Execute(&b, v); err != nil {
+				return "", err
+			}
+			return b.String(), nil
+		},
+		// {{markdown .Descripcion}}: mismo renderizador saneado que los boletines
+		"markdown": func(src string) htmltpl.HTML {
+			return htmltpl.HTML(markdown.Render(src))
+		},
+	}
+}
+
+// Render arma asunto, HTML y texto plano del mensaje en el idioma del destinatario
+func Render(tipo Tipo, idioma string, d Datos) (*Correo, error) {
+	if err := cargarCatalogos(); err != nil {
+		return nil, err
+	}
+	if _, err := archivos.Open("correos/" + string(tipo) + ".html"); err != nil {
+		return nil, ErrTipoDesconocido
+	}
+
+	idioma = Normalizar(idioma)
+	v := &vista{Datos: d, Idioma: idioma, Dir: "ltr", Inicio: "left"}
+	if EsRTL(idioma) {
+		v.Dir, v.Inicio = "rtl", "right"
+	}
+	fn := funciones(idioma, tipo, v)
+
+	asunto, err := fn["t"].(func(string) (string, error))("asunto")
+	if err != nil {
+		return nil, err
+	}
+
+	html, err := htmltpl.New("base.html").Funcs(fn).ParseFS(archivos, "correos/base.html", "correos/"+string(tipo)+".html")
+	if err != nil {
+		return nil, err
+	}
+	var bh bytes.Buffer
+	if err := html.ExecuteTemplate(&bh, "base", v); err != nil {
+		return nil, err
+	}
+
+	txt, err := texttpl.New(string(tipo)+".txt").Funcs(fn).ParseFS(archivos, "correos/"+string(tipo)+".txt")
+	if err != nil {
+		return nil, err
+	}
+	var bt bytes.Buffer
+	if err := txt.Execute(&bt, v); err != nil {
+		return nil, err
+	}
+
+	return &Correo{Asunto: asunto, HTML: bh.String(), Texto: strings.TrimSpace(bt.String())}, nil
+}
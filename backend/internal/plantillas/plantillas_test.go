@@ -0,0 +1,164 @@
+/**
+ * ARCHIVO: plantillas_test.go
+ * UBICACIÓN: backend/internal/plantillas/plantillas_test.go
+ * DESCRIPCIÓN: Pruebas de localización, dirección RTL y escapado de los correos.
+ */
+
+package plantillas
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRenderEscapaContenido(t *testing.T) {
+	c, err := Render(AvisoSeguridad, "es", Datos{
+		Nombre:      "Ana <b>",
+		Titulo:      "Phishing",
+		Descripcion: `No comparta su clave <script>alert("x")</script>`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(c.HTML, "<script>") || strings.Contains(c.HTML, "Ana <b>") {
+		t.Errorf("FALLO DE SEGURIDAD: Contenido sin escapar en el HTML:\n%s", c.HTML)
+	}
+	// La descripción es Markdown: el HTML crudo se descarta y el texto se conserva
+	if !strings.Contains(c.HTML, "No comparta su clave") {
+		t.Errorf("La descripción debía mostrarse sin el script")
+	}
+	// El texto plano no es HTML: conserva el contenido literal
+	if !strings.Contains(c.Texto, `<script>alert("x")</script>`) || c.Asunto != "⚠️ Phishing" {
+		t.Errorf("Texto plano o asunto inesperados: %q / %q", c.Texto, c.Asunto)
+	}
+}
+
+func TestRenderRTLyRespaldo(t *testing.T) {
+	ar, err := Render(PIN, "ar", Datos{PIN: "123456", Minutos: 15})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(ar.HTML, `dir="rtl"`) || !strings.Contains(ar.HTML, `lang="ar"`) {
+		t.Errorf("El árabe debe maquetarse RTL")
+	}
+
+	// Alemán no tiene textos propios: recibe el correo en inglés, no en español
+	de, _ := Render(PIN, "de", Datos{PIN: "123456", Minutos: 15})
+	if de.Asunto != "Code: 123456" || !strings.Contains(de.HTML, `lang="en"`) || !strings.Contains(de.HTML, `dir="ltr"`) {
+		t.Errorf("Respaldo al inglés inesperado: %q / %q", de.Asunto, de.Texto)
+	}
+
+	// Un código que el frontend no ofrece sigue cayendo al idioma por defecto
+	if got := Normalizar("xx"); got != IdiomaPorDefecto {
+		t.Errorf("Normalizar(xx) = %q, se esperaba %q", got, IdiomaPorDefecto)
+	}
+
+	en, _ := Render(PIN, "en-GB", Datos{PIN: "123456", Minutos: 15})
+	if en.Asunto != "Code: 123456" || !strings.Contains(en.Texto, "expires in 15 minutes") {
+		t.Errorf("en-GB debía resolverse a inglés: %q / %q", en.Asunto, en.Texto)
+	}
+}
+
+func TestCatalogosCompletos(t *testing.T) {
+	// Cada idioma debe traducir todas las claves del español (evita textos mezclados)
+	if err := cargarCatalogos(); err != nil {
+		t.Fatal(err)
+	}
+	for idioma, cat := range catalogos {
+		for seccion, claves := range catalogos[IdiomaPorDefecto] {
+			for clave := range claves {
+				if _, ok := cat[seccion][clave]; !ok {
+					t.Errorf("idiomas/%s.json: falta %s.%s", idioma, seccion, clave)
+				}
+			}
+		}
+		if !Valido(idioma) {
+			t.Errorf("idiomas/%s.json no corresponde a un idioma del frontend", idioma)
+		}
+	}
+}
+
+func TestIdiomasTraducidos(t *testing.T) {
+	// Traducidos debe coincidir con los catálogos embebidos: ni de más ni de menos
+	if err := cargarCatalogos(); err != nil {
+		t.Fatal(err)
+	}
+	if len(Traducidos) != len(catalogos) {
+		t.Errorf("Traducidos = %v, pero hay %d catálogos", Traducidos, len(catalogos))
+	}
+	for _, idioma := range Traducidos {
+		if _, ok := catalogos[idioma]; !ok {
+			t.Errorf("%s figura como traducido pero no tiene idiomas/%s.json", idioma, idioma)
+		}
+	}
+	// Los demás idiomas del frontend se normalizan al inglés
+	for _, idioma := range Soportados {
+		_, traducido := catalogos[idioma]
+		if got := Normalizar(idioma); traducido && got != idioma || !traducido && got != IdiomaRespaldo {
+			t.Errorf("Normalizar(%q) = %q", idioma, got)
+		}
+	}
+}
+
+func TestRenderTipoDesconocido(t *testing.T) {
+	if _, err := Render(Tipo("inexistente"), "es", Datos{}); err != ErrTipoDesconocido {
+		t.Errorf("Se esperaba ErrTipoDesconocido, se obtuvo %v", err)
+	}
+}
+
+func TestRenderEnlaceDeBaja(t *testing.T) {
+	c, err := Render(AvisoSeguridad, "en", Datos{Titulo: "Aviso", BajaURL: "https://example.org/api/notificaciones/baja?t=a.b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(c.HTML, `href="https://example.org/api/notificaciones/baja?t=a.b"`) || !strings.Contains(c.HTML, "Stop receiving these notices") {
+		t.Errorf("El pie debía incluir el enlace de baja traducido:\n%s", c.HTML)
+	}
+	if !strings.Contains(c.Texto, "https://example.org/api/notificaciones/baja?t=a.b") {
+		t.Errorf("El texto plano debía incluir el enlace de baja")
+	}
+
+	pin, _ := Render(PIN, "es", Datos{PIN: "123456"})
+	if strings.Contains(pin.HTML, "notificaciones/baja") {
+		t.Errorf("Los correos transaccionales no llevan enlace de baja")
+	}
+}
+
+func TestRenderLiteraturaLista(t *testing.T) {
+	c, err := Render(LiteraturaLista, "pt", Datos{Nombre: "Ana", Publicacion: "Seja Feliz <Para Sempre>", Cantidad: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(c.HTML, "Seja Feliz &lt;Para Sempre&gt;") || !strings.Contains(c.HTML, "quantidade: 2") {
+		t.Errorf("Nombre de la publicación o cantidad inesperados:\n%s", c.HTML)
+	}
+	if c.Asunto != "Sua publicação está pronta" {
+		t.Errorf("Asunto inesperado: %q", c.Asunto)
+	}
+}
+
+func TestRenderDescripcionMarkdown(t *testing.T) {
+	c, err := Render(AvisoSeguridad, "es", Datos{Titulo: "Phishing", Descripcion: "## Qué hacer\n\n- **No** responda\n- Avise al [anciano](https://jw.org)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, esperado := range []string{"<h2>Qué hacer</h2>", "<li><strong>No</strong> responda</li>", `rel="nofollow noopener"`} {
+		if !strings.Contains(c.HTML, esperado) {
+			t.Errorf("Falta %q en el HTML:\n%s", esperado, c.HTML)
+		}
+	}
+	// El texto plano conserva el Markdown legible
+	if !strings.Contains(c.Texto, "- **No** responda") {
+		t.Errorf("Texto plano inesperado: %q", c.Texto)
+	}
+}
+
+func TestRenderRecordatorioSeguridad(t *testing.T) {
+	c, err := Render(RecordatorioSeguridad, "en", Datos{Nombre: "Ana", Titulo: "Phishing", Descripcion: "**Never** share your PIN"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Asunto != "🔔 Reminder: Phishing" || !strings.Contains(c.HTML, "<strong>Never</strong>") || !strings.Contains(c.Texto, "not yet confirmed") {
+		t.Errorf("Recordatorio inesperado: %q\n%s\n%s", c.Asunto, c.HTML, c.Texto)
+	}
+}
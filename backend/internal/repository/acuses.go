@@ -0,0 +1,118 @@
+/**
+ * ARCHIVO: acuses.go
+ * UBICACIÓN: internal/repository/acuses.go
+ * DESCRIPCIÓN: Acuses de lectura de los boletines (core_seguridad_acuses): cuándo
+ * cada miembro abrió y confirmó un boletín, y cuándo se le recordó por última vez.
+ * Los recordatorios se suman a la difusión del boletín, así respetan la misma tasa.
+ */
+
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RegisterAcknowledgement marca el boletín como leído (y confirmado si se pide).
+// Las fechas ya registradas no se pisan: cuenta la primera lectura y la primera confirmación.
+func (r *Repository) RegisterAcknowledgement(boletinID, personaID int, confirmar bool) error {
+	ahora := time.Now().UTC()
+	a := &models.AcuseBoletin{BoletinID: boletinID, PersonaID: personaID, LeidoAt: &ahora}
+	if confirmar {
+		a.ConfirmadoAt = &ahora
+	}
+	return r.db.Table("core_seguridad_acuses").
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "boletin_id"}, {Name: "persona_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"leido_at":      gorm.Expr("COALESCE(core_seguridad_acuses.leido_at, EXCLUDED.leido_at)"),
+				"confirmado_at": gorm.Expr("COALESCE(core_seguridad_acuses.confirmado_at, EXCLUDED.confirmado_at)"),
+			}),
+		}).
+		Create(a).Error
+}
+
+// GetAcknowledgementStats cuenta, por grupo de servicio, los miembros activos de la
+// congregación y cuántos leyeron y confirmaron el boletín (sin porcentajes)
+func (r *Repository) GetAcknowledgementStats(boletinID int, congID string) ([]models.LecturaGrupo, error) {
+	lista := []models.LecturaGrupo{}
+	err := r.db.Table("core_personas").
+		Select("core_personas.grupo, COUNT(*) as miembros, COUNT(a.leido_at) as leidos, COUNT(a.confirmado_at) as confirmados").
+		Joins("LEFT JOIN core_seguridad_acuses a ON a.persona_id = core_personas.id AND a.boletin_id = ?", boletinID).
+		Where("core_personas.congregacion_id = ? AND core_personas.estado = 'ALTA'", congID).
+		Group("core_personas.grupo").
+		Order("core_personas.grupo NULLS LAST").
+		Scan(&lista).Error
+	return lista, err
+}
+
+// GetUnacknowledged: Miembros activos de la congregación que no confirmaron el boletín
+// y a los que no se les recordó después de 'antes' (el email puede venir vacío)
+func (r *Repository) GetUnacknowledged(boletinID int, congID string, antes time.Time) ([]Destinatario, error) {
+	var lista []Destinatario
+	err := r.db.Table("core_personas").
+		Select("core_personas.id as persona_id, COALESCE(core_personas.email, '') as email, core_personas.apellido_nombre as nombre_completo, COALESCE(core_personas.idioma, 'es') as idioma").
+		Joins("LEFT JOIN core_seguridad_acuses a ON a.persona_id = core_personas.id AND a.boletin_id = ?", boletinID).
+		Where("core_personas.congregacion_id = ? AND core_personas.estado = 'ALTA'", congID).
+		Where("a.confirmado_at IS NULL AND (a.recordado_at IS NULL OR a.recordado_at < ?)", antes).
+		Order("core_personas.id").
+		Scan(&lista).Error
+	return lista, err
+}
+
+// RegisterReminders anota el recordatorio de cada persona y encola sus correos en la
+// difusión del boletín (la reabre si ya estaba completada, o la crea si no tenía)
+func (r *Repository) RegisterReminders(boletinID, editor int, personas []int, avisos []*models.EmailOutbox) error {
+	if len(personas) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		ahora := time.Now().UTC()
+		acuses := make([]models.AcuseBoletin, len(personas))
+		for i, p := range personas {
+			acuses[i] = models.AcuseBoletin{BoletinID: boletinID, PersonaID: p, RecordadoAt: &ahora}
+		}
+		err := tx.Table("core_seguridad_acuses").
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "boletin_id"}, {Name: "persona_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"recordado_at"}),
+			}).
+			Create(&acuses).Error
+		if err != nil || len(avisos) == 0 {
+			return err
+		}
+
+		var d models.Difusion
+		err = tx.Table("core_difusiones").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("boletin_id = ?", boletinID).
+			First(&d).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Boletín publicado sin difusión (nota breve): la primera tanda es su difusión
+			var b models.Boletin
+			if err := boletines(tx).Where("id = ?", boletinID).First(&b).Error; err != nil {
+				return err
+			}
+			_, err := registrarDifusion(tx, &b, editor, avisos)
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, a := range avisos {
+			a.DifusionID = &d.ID
+		}
+		err = tx.Table("core_difusiones").Where("id = ?", d.ID).Updates(map[string]interface{}{
+			"total": gorm.Expr("total + ?", len(avisos)), "estado": models.DifusionEnCurso, "completado_at": nil,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return insertEmails(tx, avisos...)
+	})
+}
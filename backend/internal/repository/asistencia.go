@@ -0,0 +1,68 @@
+/**
+ * ARCHIVO: asistencia.go
+ * UBICACIÓN: internal/repository/asistencia.go
+ * DESCRIPCIÓN: Concurrencia a las reuniones que informan los secretarios
+ * (reu_asistencia): una fila por congregación, fecha y tipo de reunión.
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// ModuloReuniones: Permiso de core_permisos_modulos que habilita cargar la asistencia
+const ModuloReuniones = "reuniones"
+
+// GetSecretarioCongregacion devuelve la congregación donde la persona puede registrar
+// la asistencia: ancianos, admins locales y usuarios con el módulo de reuniones en
+// nivel de edición (2) o más; "" en otro caso
+func (r *Repository) GetSecretarioCongregacion(personaID int) string {
+	return r.congregacionConModulo(personaID, ModuloReuniones, 2)
+}
+
+// congregacionConModulo: La del anciano o admin local o, si no lo es, la congregación
+// donde tiene el módulo con al menos 'nivel' de acceso ("" si no hay ninguna). Con
+// varias cuentas o permisos gana la cuenta más antigua, como en GetAdminCongregacion.
+func (r *Repository) congregacionConModulo(personaID int, modulo string, nivel int) string {
+	if congID := r.GetAncianoCongregacion(personaID); congID != "" {
+		return congID
+	}
+	var congID string
+	r.db.Table("core_permisos_modulos").Select("core_permisos_modulos.congregacion_id").
+		Joins("JOIN core_usuarios ON core_usuarios.id = core_permisos_modulos.usuario_id").
+		Where("core_usuarios.persona_id = ? AND core_permisos_modulos.modulo_id = ? AND core_permisos_modulos.nivel_acceso >= ?",
+			personaID, modulo, nivel).
+		Order("core_usuarios.creado_at, core_usuarios.id, core_permisos_modulos.congregacion_id").
+		Limit(1).
+		Scan(&congID)
+	return congID
+}
+
+// SaveAttendance crea o corrige la asistencia de la fecha y tipo de la congregación
+func (r *Repository) SaveAttendance(a *models.Asistencia) error {
+	return r.db.Table("reu_asistencia").Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "congregacion_id"}, {Name: "fecha"}, {Name: "tipo"}},
+		DoUpdates: clause.AssignmentColumns([]string{"presencial", "video", "registrado_por", "updated_at"}),
+	}).Create(a).Error
+}
+
+// ListAttendance: Asistencia de la congregación con fecha en [desde, hasta)
+func (r *Repository) ListAttendance(congID string, desde, hasta time.Time) ([]models.Asistencia, error) {
+	lista := []models.Asistencia{}
+	err := r.db.Table("reu_asistencia").
+		Where("congregacion_id = ? AND fecha >= ? AND fecha < ?", congID, desde, hasta).
+		Order("fecha, tipo").
+		Find(&lista).Error
+	return lista, err
+}
+
+// DeleteAttendance borra un registro de la congregación; devuelve cuántas filas borró
+func (r *Repository) DeleteAttendance(id int, congID string) (int64, error) {
+	res := r.db.Table("reu_asistencia").Where("id = ? AND congregacion_id = ?", id, congID).Delete(&models.Asistencia{})
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,48 @@
+/**
+ * ARCHIVO: auditoria.go
+ * UBICACIÓN: internal/repository/auditoria.go
+ * DESCRIPCIÓN: Rastro de auditoría (core_auditoria). Las filas se insertan dentro
+ * de la misma transacción que el cambio auditado y nunca se modifican.
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// insertAuditoria registra la acción usando la transacción del cambio auditado
+func insertAuditoria(tx *gorm.DB, a *models.Auditoria) error {
+	// congregacion_id es uuid: una cadena vacía debe guardarse como NULL
+	var congID interface{}
+	if a.CongregacionID != "" {
+		congID = a.CongregacionID
+	}
+	return tx.Table("core_auditoria").Create(map[string]interface{}{
+		"actor_persona_id": a.ActorPersonaID,
+		"accion":           a.Accion,
+		"persona_id":       a.PersonaID,
+		"congregacion_id":  congID,
+		"detalle":          a.Detalle,
+		"creado_at":        time.Now().UTC(),
+	}).Error
+}
+
+// SaveAuditoria registra una acción que no modifica tablas (ej: desbloqueo en Redis)
+func (r *Repository) SaveAuditoria(a *models.Auditoria) error {
+	return insertAuditoria(r.db, a)
+}
+
+// ListAuditoria trae el historial de acciones sobre una persona (más recientes primero)
+func (r *Repository) ListAuditoria(personaID int) ([]models.Auditoria, error) {
+	var lista []models.Auditoria
+	err := r.db.Table("core_auditoria").
+		Where("persona_id = ?", personaID).
+		Order("creado_at desc").
+		Find(&lista).Error
+	return lista, err
+}
@@ -0,0 +1,189 @@
+/**
+ * ARCHIVO: boletines.go
+ * UBICACIÓN: internal/repository/boletines.go
+ * DESCRIPCIÓN: Boletines de seguridad (core_seguridad_info) con borradores, versiones
+ * y baja lógica. Cada guardado deja una copia en core_seguridad_info_historial dentro
+ * de la misma transacción, así el historial nunca queda desfasado del boletín.
+ */
+
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/monitor"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// boletines: Solo los que no fueron eliminados
+func boletines(tx *gorm.DB) *gorm.DB {
+	return tx.Table("core_seguridad_info").Where("eliminado_at IS NULL")
+}
+
+// guardarVersion copia el estado actual del boletín en el historial
+func guardarVersion(tx *gorm.DB, b *models.Boletin, editor int) error {
+	return tx.Table("core_seguridad_info_historial").Create(&models.VersionBoletin{
+		BoletinID:        b.ID,
+		Version:          b.Version,
+		Titulo:           b.Titulo,
+		DescripcionLarga: b.DescripcionLarga,
+		DescripcionHTML:  b.DescripcionHTML,
+		Estado:           b.Estado,
+		EditadoPor:       &editor,
+		EditadoAt:        b.UpdatedAt,
+	}).Error
+}
+
+// crearBoletin inserta el boletín (versión 1) y su primera entrada de historial
+func crearBoletin(tx *gorm.DB, b *models.Boletin, editor int) error {
+	ahora := time.Now().UTC()
+	b.Version, b.CreadoPor, b.UpdatedAt = 1, &editor, ahora
+	if b.Estado == models.BoletinPublicado {
+		b.PublicadoAt = &ahora
+	}
+	if err := tx.Table("core_seguridad_info").Create(b).Error; err != nil {
+		return err
+	}
+	return guardarVersion(tx, b, editor)
+}
+
+// CreateBulletin guarda un boletín nuevo (borrador o publicado sin difusión por correo)
+func (r *Repository) CreateBulletin(b *models.Boletin, editor int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return crearBoletin(tx, b, editor)
+	})
+}
+
+// UpdateBulletin edita título y descripción (Markdown y su HTML); la versión sube de a una
+func (r *Repository) UpdateBulletin(id int, titulo, desc, descHTML string, editor int) (*models.Boletin, error) {
+	var b models.Boletin
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := boletines(tx).Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", id).First(&b).Error
+		if err != nil {
+			return err
+		}
+		b.Titulo, b.DescripcionLarga, b.DescripcionHTML = titulo, desc, descHTML
+		b.Version++
+		b.UpdatedAt = time.Now().UTC()
+		err = tx.Table("core_seguridad_info").Where("id = ?", id).Updates(map[string]interface{}{
+			"contenido": b.Titulo, "descripcion_larga": b.DescripcionLarga, "descripcion_html": b.DescripcionHTML,
+			"version": b.Version, "updated_at": b.UpdatedAt,
+		}).Error
+		if err != nil {
+			return err
+		}
+		return guardarVersion(tx, &b, editor)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// publicarBorrador pasa un borrador a publicado (el historial registra el cambio de estado)
+func publicarBorrador(tx *gorm.DB, id, editor int) (*models.Boletin, error) {
+	var b models.Boletin
+	err := boletines(tx).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("id = ? AND estado = ?", id, models.BoletinBorrador).
+		First(&b).Error
+	if err != nil {
+		return nil, err
+	}
+	ahora := time.Now().UTC()
+	b.Estado, b.PublicadoAt, b.UpdatedAt = models.BoletinPublicado, &ahora, ahora
+	err = tx.Table("core_seguridad_info").Where("id = ?", id).Updates(map[string]interface{}{
+		"estado": b.Estado, "publicado_at": ahora, "updated_at": ahora,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &b, guardarVersion(tx, &b, editor)
+}
+
+// DeleteBulletin: Baja lógica (las difusiones y el historial siguen referenciándolo)
+func (r *Repository) DeleteBulletin(id, editor int) error {
+	res := boletines(r.db).Where("id = ?", id).Updates(map[string]interface{}{
+		"eliminado_at": time.Now().UTC(), "eliminado_por": editor,
+	})
+	if res.Error == nil && res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return res.Error
+}
+
+// GetCongregacionBoletin: Congregación guardada al crear el boletín ("" en los anteriores a la columna)
+func (r *Repository) GetCongregacionBoletin(id int) (string, error) {
+	var fila struct{ CongregacionID *string }
+	err := boletines(r.db).Select("congregacion_id").Where("id = ?", id).Take(&fila).Error
+	if err != nil || fila.CongregacionID == nil {
+		return "", err
+	}
+	return *fila.CongregacionID, nil
+}
+
+// GetBulletin trae un boletín; los borradores solo si se piden
+func (r *Repository) GetBulletin(id int, conBorradores bool) (*models.Boletin, error) {
+	var b models.Boletin
+	q := boletines(r.db).Where("id = ?", id)
+	if !conBorradores {
+		q = q.Where("estado = ?", models.BoletinPublicado)
+	}
+	if err := q.First(&b).Error; err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// ListBulletins pagina los boletines (más recientes primero) y devuelve el total
+func (r *Repository) ListBulletins(conBorradores bool, pagina, porPagina int) ([]models.Boletin, int64, error) {
+	// Consulta nueva en cada uso: Count y Find no deben compartir el mismo statement
+	filtro := func() *gorm.DB {
+		q := boletines(r.db)
+		if !conBorradores {
+			q = q.Where("estado = ?", models.BoletinPublicado)
+		}
+		return q
+	}
+
+	var total int64
+	if err := filtro().Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	lista := []models.Boletin{}
+	err := filtro().Order("updated_at desc, id desc").
+		Offset((pagina - 1) * porPagina).Limit(porPagina).
+		Find(&lista).Error
+	return lista, total, err
+}
+
+// GetBulletinHistory devuelve todas las versiones guardadas (la más nueva primero)
+func (r *Repository) GetBulletinHistory(id int) ([]models.VersionBoletin, error) {
+	lista := []models.VersionBoletin{}
+	err := r.db.Table("core_seguridad_info_historial").
+		Where("boletin_id = ?", id).
+		Order("id desc").
+		Find(&lista).Error
+	return lista, err
+}
+
+// GetLatestSecurityInfo: Último boletín publicado (lo que ven todos los miembros)
+func (r *Repository) GetLatestSecurityInfo() (*models.Boletin, error) {
+	var b models.Boletin
+	err := boletines(r.db).Where("estado = ?", models.BoletinPublicado).
+		Order("publicado_at desc NULLS LAST, updated_at desc").
+		First(&b).Error
+
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			monitor.TripCircuit() // Solo disparamos el breaker si es un error de conexión, no si la tabla está vacía
+		}
+		return nil, err
+	}
+
+	monitor.ResetFailures()
+	return &b, nil
+}
@@ -0,0 +1,127 @@
+/**
+ * ARCHIVO: difusiones.go
+ * UBICACIÓN: internal/repository/difusiones.go
+ * DESCRIPCIÓN: Difusiones masivas de boletines (core_difusiones). El boletín, la
+ * difusión y un correo por destinatario se guardan en una sola transacción; el
+ * worker del outbox los entrega a la tasa configurada y marca la difusión como
+ * completada cuando ya no quedan correos pendientes.
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// registrarDifusion crea la difusión de un boletín ya publicado y encola sus correos
+func registrarDifusion(tx *gorm.DB, b *models.Boletin, creadoPor int, avisos []*models.EmailOutbox) (*models.Difusion, error) {
+	ahora := time.Now().UTC()
+	d := &models.Difusion{
+		BoletinID: b.ID,
+		Titulo:    b.Titulo,
+		Total:     len(avisos),
+		Estado:    models.DifusionEnCurso,
+		CreadoPor: creadoPor,
+		CreadoAt:  ahora,
+	}
+	if len(avisos) == 0 {
+		d.Estado, d.CompletadoAt = models.DifusionCompletada, &ahora
+	}
+	if err := tx.Table("core_difusiones").Create(d).Error; err != nil {
+		return nil, err
+	}
+
+	for _, a := range avisos {
+		a.DifusionID = &d.ID
+	}
+	return d, insertEmails(tx, avisos...)
+}
+
+// CreateBroadcast publica un boletín nuevo y registra su difusión con un correo por destinatario
+func (r *Repository) CreateBroadcast(b *models.Boletin, creadoPor int, avisos []*models.EmailOutbox) (*models.Difusion, error) {
+	var d *models.Difusion
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		b.Estado = models.BoletinPublicado
+		if err := crearBoletin(tx, b, creadoPor); err != nil {
+			return err
+		}
+		var err error
+		d, err = registrarDifusion(tx, b, creadoPor, avisos)
+		return err
+	})
+	return d, err
+}
+
+// PublishBulletin publica un borrador y registra su difusión; 'avisos' recibe el boletín
+// bloqueado para armar los correos con su contenido definitivo
+func (r *Repository) PublishBulletin(id, editor int, avisos func(b *models.Boletin) ([]*models.EmailOutbox, error)) (*models.Difusion, error) {
+	var d *models.Difusion
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		b, err := publicarBorrador(tx, id, editor)
+		if err != nil {
+			return err
+		}
+		correos, err := avisos(b)
+		if err != nil {
+			return err
+		}
+		d, err = registrarDifusion(tx, b, editor, correos)
+		return err
+	})
+	return d, err
+}
+
+// CompleteBroadcasts cierra las difusiones que ya no tienen correos pendientes
+func (r *Repository) CompleteBroadcasts() error {
+	return r.db.Table("core_difusiones").
+		Where("estado = ?", models.DifusionEnCurso).
+		Where("NOT EXISTS (SELECT 1 FROM core_email_outbox WHERE core_email_outbox.difusion_id = core_difusiones.id AND core_email_outbox.estado = ?)", models.EmailPendiente).
+		Updates(map[string]interface{}{"estado": models.DifusionCompletada, "completado_at": time.Now().UTC()}).Error
+}
+
+// GetBroadcastProgress resume la difusión de un boletín. Los totales son globales;
+// el detalle de fallos se limita a los miembros de la congregación indicada.
+func (r *Repository) GetBroadcastProgress(boletinID int, congregacionID string) (*models.ProgresoDifusion, error) {
+	p := &models.ProgresoDifusion{Fallos: []models.FalloDifusion{}}
+	err := r.db.Table("core_difusiones").Where("boletin_id = ?", boletinID).First(&p.Difusion).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var conteos []struct {
+		Estado   string
+		Cantidad int
+	}
+	err = r.db.Table("core_email_outbox").
+		Select("estado, COUNT(*) as cantidad").
+		Where("difusion_id = ?", p.ID).
+		Group("estado").
+		Scan(&conteos).Error
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range conteos {
+		switch c.Estado {
+		case models.EmailEnviado:
+			p.Enviados = c.Cantidad
+		case models.EmailPendiente:
+			p.Pendientes = c.Cantidad
+		case models.EmailFallido:
+			p.Fallidos = c.Cantidad
+		}
+	}
+
+	// Fallos: los que están en dead-letter y los pendientes que ya tuvieron algún error
+	err = r.db.Table("core_email_outbox").
+		Select("core_email_outbox.persona_id, core_personas.apellido_nombre as nombre, core_email_outbox.destinatario, core_email_outbox.estado, core_email_outbox.intentos, COALESCE(core_email_outbox.ultimo_error, '') as ultimo_error").
+		Joins("JOIN core_personas ON core_personas.id = core_email_outbox.persona_id").
+		Where("core_email_outbox.difusion_id = ? AND core_personas.congregacion_id = ?", p.ID, congregacionID).
+		Where("core_email_outbox.estado = ? OR (core_email_outbox.estado = ? AND core_email_outbox.intentos > 0)", models.EmailFallido, models.EmailPendiente).
+		Order("core_email_outbox.estado, core_personas.apellido_nombre").
+		Scan(&p.Fallos).Error
+	return p, err
+}
@@ -0,0 +1,141 @@
+/**
+ * ARCHIVO: informes.go
+ * UBICACIÓN: internal/repository/informes.go
+ * DESCRIPCIÓN: Informes mensuales de servicio (serv_informes): uno por persona y mes,
+ * con el grupo de core_personas al momento de entregarlo. Los pendientes se calculan
+ * sobre los miembros en ALTA según su grupo actual.
+ */
+
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// ModuloInformes: Permiso de core_permisos_modulos que habilita ver los informes de la congregación
+const ModuloInformes = "informes"
+
+// GetSecretarioInformes devuelve la congregación cuyos informes puede consultar la
+// persona: ancianos, admins locales y usuarios con el módulo de informes (cualquier
+// nivel: solo se leen); "" en otro caso. El módulo de reuniones no alcanza.
+func (r *Repository) GetSecretarioInformes(personaID int) string {
+	return r.congregacionConModulo(personaID, ModuloInformes, 1)
+}
+
+// DatosServicio: Lo que hace falta de core_personas para recibir un informe
+type DatosServicio struct {
+	CongregacionID string
+	Grupo          *int
+	Situacion2     string // Etiqueta de precursorado (ej: 'Regular')
+	Estado         string
+}
+
+// GetDatosServicio: Congregación, grupo y precursorado de la persona
+func (r *Repository) GetDatosServicio(personaID int) (*DatosServicio, error) {
+	var d DatosServicio
+	err := r.db.Table("core_personas").
+		Select("congregacion_id, grupo, COALESCE(situacion_2, '') as situacion2, COALESCE(estado, '') as estado").
+		Where("id = ?", personaID).
+		Take(&d).Error
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// esSuperintendente: core_personas no registra quién atiende cada grupo de servicio, así
+// que se toma como superintendente de su grupo a todo anciano o siervo ministerial en
+// ALTA (situacion_1) con grupo asignado. Un grupo puede tener varios.
+func esSuperintendente(estado, situacion1 string, grupo *int) bool {
+	situacion1 = strings.ToLower(strings.TrimSpace(situacion1))
+	return estado == "ALTA" && grupo != nil &&
+		(strings.HasPrefix(situacion1, "anciano") || strings.HasPrefix(situacion1, "siervo"))
+}
+
+// GetSuperintendenteGrupo devuelve la congregación y el grupo que atiende la persona
+// según esSuperintendente; "" y nil en otro caso
+func (r *Repository) GetSuperintendenteGrupo(personaID int) (string, *int) {
+	var fila struct {
+		CongregacionID string
+		Grupo          *int
+		Estado         string
+		Situacion1     string
+	}
+	err := r.db.Table("core_personas").
+		Select("congregacion_id, grupo, COALESCE(estado, '') as estado, COALESCE(situacion_1, '') as situacion1").
+		Where("id = ?", personaID).
+		Take(&fila).Error
+	if err != nil || !esSuperintendente(fila.Estado, fila.Situacion1, fila.Grupo) {
+		return "", nil
+	}
+	return fila.CongregacionID, fila.Grupo
+}
+
+// SaveServiceReport crea o corrige el informe de la persona para el mes
+func (r *Repository) SaveServiceReport(i *models.InformeServicio) error {
+	return r.db.Table("serv_informes").Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "persona_id"}, {Name: "mes"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"grupo", "categoria", "participo", "estudios_biblicos", "horas", "comentarios", "updated_at",
+		}),
+	}).Create(i).Error
+}
+
+// GetServiceReport: Informe de la persona para el mes
+func (r *Repository) GetServiceReport(personaID int, mes time.Time) (*models.InformeServicio, error) {
+	var i models.InformeServicio
+	err := r.db.Table("serv_informes").Where("persona_id = ? AND mes = ?", personaID, mes).First(&i).Error
+	if err != nil {
+		return nil, err
+	}
+	return &i, nil
+}
+
+// GetMissingReports: Miembros en ALTA del grupo sin informe del mes, y cuántos lo entregaron
+func (r *Repository) GetMissingReports(congID string, grupo int, mes time.Time) ([]models.PendienteInforme, int64, error) {
+	pendientes := []models.PendienteInforme{}
+	err := r.db.Table("core_personas").
+		Select("core_personas.id as persona_id, core_personas.apellido_nombre as nombre, core_personas.grupo").
+		Joins("LEFT JOIN serv_informes i ON i.persona_id = core_personas.id AND i.mes = ?", mes).
+		Where("core_personas.congregacion_id = ? AND core_personas.estado = 'ALTA' AND core_personas.grupo = ?", congID, grupo).
+		Where("i.id IS NULL").
+		Order("core_personas.apellido_nombre").
+		Scan(&pendientes).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var entregados int64
+	err = r.db.Table("core_personas").
+		Joins("JOIN serv_informes i ON i.persona_id = core_personas.id AND i.mes = ?", mes).
+		Where("core_personas.congregacion_id = ? AND core_personas.estado = 'ALTA' AND core_personas.grupo = ?", congID, grupo).
+		Count(&entregados).Error
+	return pendientes, entregados, err
+}
+
+// GetReportTotals: Suma de los informes del mes en la congregación, por categoría
+func (r *Repository) GetReportTotals(congID string, mes time.Time) ([]models.TotalCategoria, error) {
+	filas := []models.TotalCategoria{}
+	err := r.db.Table("serv_informes").
+		Select(`categoria, COUNT(*) as informes, COUNT(*) FILTER (WHERE participo) as participaron,
+			COALESCE(SUM(estudios_biblicos), 0) as estudios_biblicos, COALESCE(SUM(horas), 0) as horas`).
+		Where("congregacion_id = ? AND mes = ?", congID, mes).
+		Group("categoria").
+		Scan(&filas).Error
+	return filas, err
+}
+
+// CountMissingReports: Miembros en ALTA de la congregación sin informe del mes
+func (r *Repository) CountMissingReports(congID string, mes time.Time) (int64, error) {
+	var n int64
+	err := r.db.Table("core_personas").
+		Joins("LEFT JOIN serv_informes i ON i.persona_id = core_personas.id AND i.mes = ?", mes).
+		Where("core_personas.congregacion_id = ? AND core_personas.estado = 'ALTA' AND i.id IS NULL", congID).
+		Count(&n).Error
+	return n, err
+}
@@ -0,0 +1,33 @@
+/**
+ * ARCHIVO: informes_test.go
+ * UBICACIÓN: backend/internal/repository/informes_test.go
+ * DESCRIPCIÓN: Quién cuenta como superintendente de un grupo de servicio.
+ */
+
+package repository
+
+import "testing"
+
+func TestEsSuperintendente(t *testing.T) {
+	grupo := 3
+	casos := []struct {
+		nombre     string
+		estado     string
+		situacion1 string
+		grupo      *int
+		es         bool
+	}{
+		{"anciano con grupo", "ALTA", "Anciano", &grupo, true},
+		{"siervo ministerial con grupo", "ALTA", "Siervo Ministerial", &grupo, true},
+		{"mayúsculas y espacios", "ALTA", "  ANCIANO ", &grupo, true},
+		{"anciano sin grupo", "ALTA", "Anciano", nil, false},
+		{"anciano dado de baja", "BAJA", "Anciano", &grupo, false},
+		{"publicador", "ALTA", "Publicador", &grupo, false},
+		{"sin situación", "ALTA", "", &grupo, false},
+	}
+	for _, c := range casos {
+		if got := esSuperintendente(c.estado, c.situacion1, c.grupo); got != c.es {
+			t.Errorf("%s: esSuperintendente = %v, se esperaba %v", c.nombre, got, c.es)
+		}
+	}
+}
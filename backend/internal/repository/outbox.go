@@ -0,0 +1,126 @@
+/**
+ * ARCHIVO: outbox.go
+ * UBICACIÓN: internal/repository/outbox.go
+ * DESCRIPCIÓN: Bandeja de salida de correos (core_email_outbox). Los correos se
+ * insertan en la misma transacción que el cambio que los origina, así un rollback
+ * nunca deja un aviso enviado ni un cambio confirmado sin su aviso.
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// insertEmails encola los correos usando la transacción del cambio de negocio
+func insertEmails(tx *gorm.DB, emails ...*models.EmailOutbox) error {
+	ahora := time.Now().UTC()
+	for _, e := range emails {
+		if e == nil {
+			continue
+		}
+		e.Estado = models.EmailPendiente
+		e.CreadoAt = ahora
+		e.ProximoIntentoAt = ahora
+		if err := tx.Table("core_email_outbox").Create(e).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnqueueEmail encola correos que no acompañan un cambio en tablas (ej: bloqueo en Redis)
+func (r *Repository) EnqueueEmail(emails ...*models.EmailOutbox) error {
+	return insertEmails(r.db, emails...)
+}
+
+// ClaimPendingEmails reserva un lote de correos vencidos: hasta 'lote' transaccionales y
+// hasta 'cupoDifusion' de difusiones masivas (así un boletín no demora los PINs).
+// FOR UPDATE SKIP LOCKED permite varias instancias del worker; 'reserva' posterga el
+// próximo intento por si el proceso muere.
+func (r *Repository) ClaimPendingEmails(lote, cupoDifusion int, reserva time.Duration) ([]models.EmailOutbox, error) {
+	var emails []models.EmailOutbox
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		ahora := time.Now().UTC()
+		pendientes := func(clase string, limite int) ([]models.EmailOutbox, error) {
+			var lista []models.EmailOutbox
+			if limite <= 0 {
+				return lista, nil
+			}
+			err := tx.Table("core_email_outbox").
+				Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+				Where("estado = ? AND proximo_intento_at <= ?", models.EmailPendiente, ahora).
+				Where(clase).
+				Order("proximo_intento_at").
+				Limit(limite).
+				Find(&lista).Error
+			return lista, err
+		}
+
+		transaccionales, err := pendientes("difusion_id IS NULL", lote)
+		if err != nil {
+			return err
+		}
+		masivos, err := pendientes("difusion_id IS NOT NULL", cupoDifusion)
+		if err != nil {
+			return err
+		}
+		emails = append(transaccionales, masivos...)
+		if len(emails) == 0 {
+			return nil
+		}
+
+		ids := make([]int64, len(emails))
+		for i, e := range emails {
+			ids[i] = e.ID
+		}
+		return tx.Table("core_email_outbox").Where("id IN ?", ids).
+			Update("proximo_intento_at", ahora.Add(reserva)).Error
+	})
+	return emails, err
+}
+
+// vaciarSensible borra asunto y cuerpo de un correo sensible: el PIN puede estar en cualquiera
+func vaciarSensible(campos map[string]interface{}) {
+	campos["asunto"], campos["html"], campos["texto"] = "", "", ""
+}
+
+// MarkEmailSent cierra el correo; los sensibles (PINs) pierden asunto y cuerpo
+func (r *Repository) MarkEmailSent(e *models.EmailOutbox) error {
+	campos := map[string]interface{}{
+		"estado":     models.EmailEnviado,
+		"intentos":   e.Intentos + 1,
+		"enviado_at": time.Now().UTC(),
+	}
+	if e.Sensible {
+		vaciarSensible(campos)
+	}
+	return r.db.Table("core_email_outbox").Where("id = ?", e.ID).Updates(campos).Error
+}
+
+// MarkEmailRetry registra el fallo y agenda el siguiente intento
+func (r *Repository) MarkEmailRetry(e *models.EmailOutbox, proximo time.Time, causa string) error {
+	return r.db.Table("core_email_outbox").Where("id = ?", e.ID).Updates(map[string]interface{}{
+		"intentos":           e.Intentos + 1,
+		"proximo_intento_at": proximo,
+		"ultimo_error":       causa,
+	}).Error
+}
+
+// MarkEmailDead mueve el correo a dead-letter (estado 'fallido') para revisión manual
+func (r *Repository) MarkEmailDead(e *models.EmailOutbox, causa string) error {
+	campos := map[string]interface{}{
+		"estado":       models.EmailFallido,
+		"intentos":     e.Intentos + 1,
+		"ultimo_error": causa,
+	}
+	if e.Sensible {
+		vaciarSensible(campos)
+	}
+	return r.db.Table("core_email_outbox").Where("id = ?", e.ID).Updates(campos).Error
+}
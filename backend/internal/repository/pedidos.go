@@ -0,0 +1,122 @@
+/**
+ * ARCHIVO: pedidos.go
+ * UBICACIÓN: internal/repository/pedidos.go
+ * DESCRIPCIÓN: Pedidos de literatura (pub_pedidos) y su paso a 'listo' cuando llega
+ * stock o el siervo los aparta. El cambio de estado y el inventario se guardan en la
+ * misma transacción; los avisos al hermano los encola el servicio tras el commit.
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// estadosEnEspera: Pedidos que todavía pueden apartarse
+var estadosEnEspera = []string{models.PedidoPendiente, models.PedidoSinStock}
+
+// pedidosQuery: Pedido con el nombre de la publicación, bloqueando solo las filas de pub_pedidos
+func pedidosQuery(tx *gorm.DB) *gorm.DB {
+	return tx.Table("pub_pedidos").
+		Select(`pub_pedidos.id, pub_pedidos.congregacion_id, pub_pedidos.persona_id, pub_pedidos.publicacion_id,
+			COALESCE(pub_catalogo.nombre_publicacion, pub_pedidos.publicacion_id) as nombre_publicacion,
+			COALESCE(pub_pedidos.cantidad, 1) as cantidad, pub_pedidos.estado, pub_pedidos.listo_at`).
+		Joins("LEFT JOIN pub_catalogo ON pub_catalogo.id = pub_pedidos.publicacion_id").
+		Clauses(clause.Locking{Strength: "UPDATE", Table: clause.Table{Name: "pub_pedidos"}})
+}
+
+// marcarListos cambia el estado de los pedidos
+func marcarListos(tx *gorm.DB, pedidos []models.Pedido) error {
+	if len(pedidos) == 0 {
+		return nil
+	}
+	ids := make([]int, len(pedidos))
+	for i, p := range pedidos {
+		ids[i] = p.ID
+	}
+	return tx.Table("pub_pedidos").Where("id IN ?", ids).
+		Updates(map[string]interface{}{"estado": models.PedidoListo, "listo_at": pedidos[0].ListoAt}).Error
+}
+
+// repartirStock aparta, en el orden recibido (fecha de pedido), los pedidos que el
+// stock disponible alcanza a cubrir. Un pedido grande que no alcanza no frena a los
+// siguientes más chicos. Devuelve los pedidos listos y el stock que queda libre.
+func repartirStock(enEspera []models.Pedido, disponible int, ahora time.Time) ([]models.Pedido, int) {
+	var listos []models.Pedido
+	for _, p := range enEspera {
+		if p.Cantidad > disponible {
+			continue
+		}
+		disponible -= p.Cantidad
+		p.Estado, p.ListoAt = models.PedidoListo, &ahora
+		listos = append(listos, p)
+	}
+	return listos, disponible
+}
+
+// RegisterStockArrival suma stock y aparta, por orden de llegada, los pedidos en espera
+// que alcanzan a cubrirse. Devuelve los pedidos que quedaron listos.
+func (r *Repository) RegisterStockArrival(congID, publicacionID string, cantidad int) ([]models.Pedido, error) {
+	var listos []models.Pedido
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Fila de inventario de la publicación: se crea si es la primera vez que llega y,
+		// si ya existe, el DO UPDATE la bloquea hasta el fin de la transacción (dos entregas
+		// simultáneas se ordenan sobre la misma fila en lugar de crear dos)
+		var stock struct {
+			ID                 int
+			CantidadDisponible int
+		}
+		err := tx.Raw(`INSERT INTO pub_stock_local (congregacion_id, publicacion_id, cantidad_disponible)
+			VALUES (?, ?, 0)
+			ON CONFLICT (congregacion_id, publicacion_id) DO UPDATE SET cantidad_disponible = pub_stock_local.cantidad_disponible
+			RETURNING id, COALESCE(cantidad_disponible, 0) as cantidad_disponible`, congID, publicacionID).
+			Scan(&stock).Error
+		if err != nil {
+			return err
+		}
+		var enEspera []models.Pedido
+		err = pedidosQuery(tx).
+			Where("pub_pedidos.congregacion_id = ? AND pub_pedidos.publicacion_id = ? AND pub_pedidos.estado IN ?", congID, publicacionID, estadosEnEspera).
+			Order("pub_pedidos.fecha_pedido, pub_pedidos.id").
+			Find(&enEspera).Error
+		if err != nil {
+			return err
+		}
+
+		// El stock guardado es lo que queda libre tras apartar
+		var disponible int
+		listos, disponible = repartirStock(enEspera, stock.CantidadDisponible+cantidad, time.Now().UTC())
+		err = tx.Table("pub_stock_local").Where("id = ?", stock.ID).Update("cantidad_disponible", disponible).Error
+		if err != nil {
+			return err
+		}
+		return marcarListos(tx, listos)
+	})
+	return listos, err
+}
+
+// SetAsideOrder: El siervo aparta a mano un pedido en espera (no descuenta stock:
+// lo registrado en pub_stock_local no incluía ese ejemplar)
+func (r *Repository) SetAsideOrder(congID string, pedidoID int) (*models.Pedido, error) {
+	var p models.Pedido
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := pedidosQuery(tx).
+			Where("pub_pedidos.id = ? AND pub_pedidos.congregacion_id = ? AND pub_pedidos.estado IN ?", pedidoID, congID, estadosEnEspera).
+			First(&p).Error
+		if err != nil {
+			return err
+		}
+		ahora := time.Now().UTC()
+		p.Estado, p.ListoAt = models.PedidoListo, &ahora
+		return marcarListos(tx, []models.Pedido{p})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
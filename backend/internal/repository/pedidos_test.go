@@ -0,0 +1,93 @@
+/**
+ * ARCHIVO: pedidos_test.go
+ * UBICACIÓN: backend/internal/repository/pedidos_test.go
+ * DESCRIPCIÓN: Reparto del stock que llega entre los pedidos en espera (orden de
+ * pedido, pedidos grandes, sobrante).
+ */
+
+package repository
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+)
+
+func TestRepartirStock(t *testing.T) {
+	pedido := func(id, cantidad int) models.Pedido {
+		return models.Pedido{ID: id, Cantidad: cantidad, Estado: models.PedidoPendiente}
+	}
+	casos := []struct {
+		nombre     string
+		disponible int
+		enEspera   []models.Pedido
+		listos     []int
+		queda      int
+	}{
+		{
+			nombre:     "por orden de pedido",
+			disponible: 3,
+			enEspera:   []models.Pedido{pedido(1, 2), pedido(2, 2)},
+			listos:     []int{1},
+			queda:      1,
+		},
+		{
+			nombre:     "un pedido grande no frena a los chicos",
+			disponible: 3,
+			enEspera:   []models.Pedido{pedido(1, 5), pedido(2, 1), pedido(3, 2)},
+			listos:     []int{2, 3},
+			queda:      0,
+		},
+		{
+			nombre:     "el pedido grande se cubre si alcanza primero",
+			disponible: 5,
+			enEspera:   []models.Pedido{pedido(1, 5), pedido(2, 1)},
+			listos:     []int{1},
+			queda:      0,
+		},
+		{
+			nombre:     "sobra stock",
+			disponible: 12,
+			enEspera:   []models.Pedido{pedido(1, 2), pedido(2, 3)},
+			listos:     []int{1, 2},
+			queda:      7,
+		},
+		{
+			nombre:     "sin pedidos en espera",
+			disponible: 5,
+			queda:      5,
+		},
+		{
+			nombre:   "sin stock",
+			enEspera: []models.Pedido{pedido(1, 1)},
+		},
+	}
+
+	ahora := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, c := range casos {
+		t.Run(c.nombre, func(t *testing.T) {
+			listos, queda := repartirStock(c.enEspera, c.disponible, ahora)
+			var ids []int
+			for _, p := range listos {
+				ids = append(ids, p.ID)
+				if p.Estado != models.PedidoListo || p.ListoAt == nil || !p.ListoAt.Equal(ahora) {
+					t.Errorf("El pedido %d debía quedar listo: %+v", p.ID, p)
+				}
+			}
+			if !slices.Equal(ids, c.listos) {
+				t.Errorf("Pedidos apartados: %v, se esperaba %v", ids, c.listos)
+			}
+			if queda != c.queda {
+				t.Errorf("Stock libre: %d, se esperaba %d", queda, c.queda)
+			}
+			// Los pedidos recibidos no se modifican: solo las copias apartadas
+			for _, p := range c.enEspera {
+				if p.Estado != models.PedidoPendiente {
+					t.Errorf("Se modificó el pedido en espera %d", p.ID)
+				}
+			}
+		})
+	}
+}
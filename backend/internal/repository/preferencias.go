@@ -0,0 +1,56 @@
+/**
+ * ARCHIVO: preferencias.go
+ * UBICACIÓN: internal/repository/preferencias.go
+ * DESCRIPCIÓN: Preferencias de notificación por categoría y canal
+ * (core_preferencias_notificacion). Solo se guardan los cambios del miembro:
+ * sin fila, el aviso se envía.
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm/clause"
+)
+
+// filtroSinBaja: Condición SQL que excluye a quienes desactivaron la categoría en el canal
+const filtroSinBaja = `NOT EXISTS (
+	SELECT 1 FROM core_preferencias_notificacion pn
+	WHERE pn.persona_id = core_personas.id AND pn.categoria = ? AND pn.canal = ? AND NOT pn.activo)`
+
+// GetPreferencias devuelve las preferencias guardadas de la persona
+func (r *Repository) GetPreferencias(personaID int) ([]models.PreferenciaNotificacion, error) {
+	var lista []models.PreferenciaNotificacion
+	err := r.db.Table("core_preferencias_notificacion").
+		Where("persona_id = ?", personaID).
+		Find(&lista).Error
+	return lista, err
+}
+
+// SetPreferencia activa o desactiva una categoría en un canal
+func (r *Repository) SetPreferencia(personaID int, categoria, canal string, activo bool) error {
+	return r.db.Table("core_preferencias_notificacion").
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "persona_id"}, {Name: "categoria"}, {Name: "canal"}},
+			DoUpdates: clause.AssignmentColumns([]string{"activo", "updated_at"}),
+		}).
+		Create(&models.PreferenciaNotificacion{
+			PersonaID: personaID,
+			Categoria: categoria,
+			Canal:     canal,
+			Activo:    activo,
+			UpdatedAt: time.Now().UTC(),
+		}).Error
+}
+
+// AceptaNotificacion indica si la persona recibe la categoría por el canal (ante un error, sí)
+func (r *Repository) AceptaNotificacion(personaID int, categoria, canal string) bool {
+	var desactivadas int64
+	err := r.db.Table("core_preferencias_notificacion").
+		Where("persona_id = ? AND categoria = ? AND canal = ? AND NOT activo", personaID, categoria, canal).
+		Count(&desactivadas).Error
+	return err != nil || desactivadas == 0
+}
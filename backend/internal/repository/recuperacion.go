@@ -0,0 +1,59 @@
+/**
+ * ARCHIVO: recuperacion.go
+ * UBICACIÓN: internal/repository/recuperacion.go
+ * DESCRIPCIÓN: Consultas del flujo de recuperación de cuenta. Devuelven el contacto
+ * registrado del miembro; el servicio decide qué se envía y qué se muestra.
+ */
+
+package repository
+
+import "gorm.io/gorm"
+
+// ContactoRecuperacion: Datos de contacto registrados de un miembro
+type ContactoRecuperacion struct {
+	PersonaID      int
+	Email          string
+	Username       string
+	NombreCompleto string
+	Telefono       string // E.164 (vacío si el contacto no es válido)
+	Canal          string // Canal preferido: email, sms o whatsapp
+	Idioma         string // Idioma de los mensajes (core_personas.idioma)
+}
+
+// contactoQuery arma la consulta base (el alias de core_usuarios tiene prioridad, igual que en el login)
+func (r *Repository) contactoQuery() *gorm.DB {
+	return r.db.Table("core_personas").
+		Select(`
+			core_personas.id as persona_id,
+			core_personas.email,
+			COALESCE(core_usuarios.username_temp, core_personas.username_temp) as username,
+			core_personas.apellido_nombre as nombre_completo,
+			COALESCE(core_personas.telefono_e164, '') as telefono,
+			COALESCE(core_personas.canal_preferido, 'email') as canal,
+			COALESCE(core_personas.idioma, 'es') as idioma
+		`).
+		Joins("LEFT JOIN core_usuarios ON core_usuarios.persona_id = core_personas.id")
+}
+
+// GetContactoByIDAndCong busca al miembro por su ID personal y el número de su congregación
+func (r *Repository) GetContactoByIDAndCong(personaID, numCong string) (*ContactoRecuperacion, error) {
+	var c ContactoRecuperacion
+	err := r.contactoQuery().
+		Joins("JOIN core_congregaciones ON core_congregaciones.id = core_personas.congregacion_id").
+		Where("core_personas.id = ? AND core_congregaciones.numero_congregacion = ?", personaID, numCong).
+		Take(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// GetContactoByPersona devuelve el contacto registrado de una persona
+func (r *Repository) GetContactoByPersona(personaID int) (*ContactoRecuperacion, error) {
+	var c ContactoRecuperacion
+	err := r.contactoQuery().Where("core_personas.id = ?", personaID).Take(&c).Error
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
@@ -45,10 +45,15 @@ func (r *Repository) GetUserForLogin(username string) (*models.Usuario, error) {
             core_usuarios.persona_id, 
             core_usuarios.username_temp as username, 
             core_usuarios.password_hash, 
+            core_usuarios.estado_cuenta, 
+            core_usuarios.congregacion_id, 
+            core_usuarios.es_admin_local, 
             core_personas.apellido_nombre as nombre_completo, 
             core_personas.url_imagen as foto_url, 
             core_personas.email, 
             core_personas.contacto, 
+            core_personas.canal_preferido, 
+            core_personas.idioma, 
             core_personas.estado, 
             core_congregaciones.nombre as congregacion_nombre, 
             core_congregaciones.numero_congregacion, 
@@ -62,7 +67,7 @@ func (r *Repository) GetUserForLogin(username string) (*models.Usuario, error) {
         `).
 		Joins("LEFT JOIN core_personas ON core_personas.id = core_usuarios.persona_id").
 		Joins("LEFT JOIN core_congregaciones ON core_congregaciones.id = core_usuarios.congregacion_id").
-		Where("core_usuarios.username_temp ILIKE ?", username). // Quitamos el filtro de estado aquí para probar
+		Where("core_usuarios.username_temp ILIKE ?", username). // Sin filtro de estado: el servicio lo valida tras la contraseña
 		First(&u).Error
 
 	if err != nil {
@@ -80,10 +85,13 @@ func (r *Repository) GetUserForLogin(username string) (*models.Usuario, error) {
                 core_personas.apellido_nombre as nombre_completo, 
                 core_personas.email, 
                 core_personas.contacto, 
+                core_personas.canal_preferido, 
+                core_personas.idioma, 
                 core_personas.url_imagen as foto_url, 
                 core_personas.username_temp as username, 
                 core_personas.password_hash, 
                 core_personas.estado, 
+                core_personas.congregacion_id, 
                 core_congregaciones.nombre as congregacion_nombre, 
                 core_congregaciones.numero_congregacion,
                 core_congregaciones.direccion, 
@@ -109,26 +117,6 @@ func (r *Repository) GetUserForLogin(username string) (*models.Usuario, error) {
 	return &u, nil
 }
 
-func (r *Repository) UserExists(username string) bool {
-	var count int64
-	r.db.Table("core_usuarios").Where("username_temp = ?", username).Count(&count)
-	if count > 0 {
-		return true
-	}
-	r.db.Table("core_personas").Where("username_temp = ?", username).Count(&count)
-	return count > 0
-}
-
-func (r *Repository) GetUserByEmail(email string) (*models.Usuario, error) {
-	var u models.Usuario
-	err := r.db.Table("core_usuarios").
-		Select("core_usuarios.username_temp, core_personas.id as persona_id, core_congregaciones.numero_congregacion, core_congregaciones.nombre as congregacion_nombre").
-		Joins("JOIN core_personas ON core_personas.id = core_usuarios.persona_id").
-		Joins("JOIN core_congregaciones ON core_congregaciones.id = core_usuarios.congregacion_id").
-		Where("core_personas.email = ?", email).First(&u).Error
-	return &u, err
-}
-
 // --- GESTIÓN DE PIN Y VERIFICACIÓN ---
 
 func (r *Repository) GetCongregacionName(num string) string {
@@ -137,10 +125,17 @@ func (r *Repository) GetCongregacionName(num string) string {
 	return nombre
 }
 
-func (r *Repository) SavePin(pin string) error {
-	return r.db.Table("core_verificaciones").Create(map[string]interface{}{
-		"pin": pin, "tipo": "SECURITY_CHECK", "utilizado": false, "expira_at": time.Now().UTC().Add(15 * time.Minute),
-	}).Error
+// SavePin guarda el PIN y encola su correo (si lo hay) en la misma transacción
+func (r *Repository) SavePin(pin string, avisos ...*models.EmailOutbox) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("core_verificaciones").Create(map[string]interface{}{
+			"pin": pin, "tipo": "SECURITY_CHECK", "utilizado": false, "expira_at": time.Now().UTC().Add(15 * time.Minute),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return insertEmails(tx, avisos...)
+	})
 }
 
 func (r *Repository) InvalidateOldPines() {
@@ -159,38 +154,16 @@ func (r *Repository) ConsumePin(pin string) error {
 	return r.db.Table("core_verificaciones").Where("id = ?", v.Id).Update("utilizado", true).Error
 }
 
-// FindEmailByIDAndCong busca el email comparando ID de persona y número de congregación
-func (r *Repository) FindEmailByIDAndCong(personaID, numCong string) (string, error) {
-	var email string
-	err := r.db.Table("core_personas").
-		Select("core_personas.email").
-		Joins("JOIN core_congregaciones ON core_congregaciones.id = core_personas.congregacion_id").
-		Where("core_personas.id = ? AND core_congregaciones.numero_congregacion = ?", personaID, numCong).
-		Scan(&email).Error
-	return email, err
-}
-
-// GetAllContactsForRecovery trae todos los contactos para validación telefónica (Lógica de recuperación)
-func (r *Repository) GetAllContactsForRecovery() ([]struct {
-	Email    string
-	Contacto string
-	Id       int
-}, error) {
-	var results []struct {
-		Email    string
-		Contacto string
-		Id       int
-	}
-	err := r.db.Table("core_personas").Select("email, contacto, id").Scan(&results).Error
-	return results, err
-}
-
 // --- MÓDULO DE PERFIL Y SEGURIDAD ---
 
-func (r *Repository) UpdateProfileField(personaID, campo, valor string) error {
+// UpdateProfileField cambia una columna del perfil (el servicio valida cuáles); el alias
+// se guarda también en la cuenta web
+func (r *Repository) UpdateProfileField(personaID int, campo, valor string) error {
 	if campo == "username" {
 		return r.db.Transaction(func(tx *gorm.DB) error {
-			tx.Table("core_personas").Where("id = ?", personaID).Update("username_temp", valor)
+			if err := tx.Table("core_personas").Where("id = ?", personaID).Update("username_temp", valor).Error; err != nil {
+				return err
+			}
 			return tx.Table("core_usuarios").Where("persona_id = ?", personaID).Update("username_temp", valor).Error
 		})
 	}
@@ -201,71 +174,95 @@ func (r *Repository) UpdateFoto(personaID, url string) error {
 	return r.db.Table("core_personas").Where("id = ?", personaID).Update("url_imagen", url).Error
 }
 
-func (r *Repository) SuspendAccount(personaID, usuarioID string) error {
+// SuspendAccount da de baja a la persona y suspende su cuenta web (si tiene), dejando
+// rastro en la auditoría
+func (r *Repository) SuspendAccount(personaID int, audit *models.Auditoria, avisos ...*models.EmailOutbox) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		tx.Table("core_personas").Where("id = ?", personaID).Update("estado", "BAJA")
-		if usuarioID != "" {
-			tx.Table("core_usuarios").Where("id = ?", usuarioID).Update("estado_cuenta", "suspendida")
+		if err := tx.Table("core_personas").Where("id = ?", personaID).Update("estado", "BAJA").Error; err != nil {
+			return err
 		}
-		return nil
+		// security_updated_at invalida de inmediato las llaves emitidas antes de la suspensión
+		if err := tx.Table("core_usuarios").Where("persona_id = ?", personaID).Updates(map[string]interface{}{"estado_cuenta": "suspendida", "security_updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		if err := insertAuditoria(tx, audit); err != nil {
+			return err
+		}
+		return insertEmails(tx, avisos...)
 	})
 }
 
-func (r *Repository) SaveSecurityLog(titulo, desc string) error {
-	return r.db.Table("core_seguridad_info").Create(map[string]interface{}{
-		"contenido": titulo, "descripcion_larga": desc, "updated_at": time.Now(),
-	}).Error
-}
-
-func (r *Repository) GetLatestSecurityInfo() (map[string]interface{}, error) {
-	var info struct {
-		Contenido string
-		UpdatedAt time.Time
-	}
-	err := r.db.Table("core_seguridad_info").Order("updated_at desc").First(&info).Error
-
-	if err != nil {
-		if err.Error() != "record not found" {
-			monitor.TripCircuit() // Solo disparamos el breaker si es un error de conexión, no si la tabla está vacía
+// ReactivateAccount devuelve la persona a ALTA y su cuenta web a 'activa', dejando rastro en la auditoría
+func (r *Repository) ReactivateAccount(personaID int, audit *models.Auditoria, avisos ...*models.EmailOutbox) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("core_personas").Where("id = ?", personaID).Updates(map[string]interface{}{"estado": "ALTA", "fecha_baja": nil}).Error; err != nil {
+			return err
 		}
-		return nil, err
-	}
-
-	monitor.ResetFailures()
-	return map[string]interface{}{"contenido": info.Contenido, "updated_at": info.UpdatedAt}, nil
+		if err := tx.Table("core_usuarios").Where("persona_id = ?", personaID).Updates(map[string]interface{}{"estado_cuenta": "activa", "security_updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		if err := insertAuditoria(tx, audit); err != nil {
+			return err
+		}
+		return insertEmails(tx, avisos...)
+	})
 }
 
 // Lista de destinatarios para boletín
 type Destinatario struct {
+	PersonaID          int
 	Email              string
 	NombreCompleto     string
 	Username           string
 	CongregacionNombre string
+	Idioma             string
 }
 
-func (r *Repository) GetActiveMembersForBroadcast() ([]Destinatario, error) {
+// GetActiveMembersForBroadcast: Miembros activos con email que no dieron de baja la categoría por email
+func (r *Repository) GetActiveMembersForBroadcast(categoria string) ([]Destinatario, error) {
 	var lista []Destinatario
 	err := r.db.Table("core_personas").
-		Select("core_personas.email, core_personas.apellido_nombre as nombre_completo, core_personas.username_temp as username, core_congregaciones.nombre as congregacion_nombre").
+		Select("core_personas.id as persona_id, core_personas.email, core_personas.apellido_nombre as nombre_completo, core_personas.username_temp as username, core_congregaciones.nombre as congregacion_nombre, COALESCE(core_personas.idioma, 'es') as idioma").
 		Joins("JOIN core_congregaciones ON core_congregaciones.id = core_personas.congregacion_id").
 		Where("core_personas.estado = 'ALTA' AND core_personas.email IS NOT NULL AND core_personas.email != ''").
+		Where(filtroSinBaja, categoria, models.CanalNotifEmail).
 		Scan(&lista).Error
 	return lista, err
 }
 
-func (r *Repository) CreateSecurityLog(titulo, desc string) error {
-	return r.db.Table("core_seguridad_info").Create(map[string]interface{}{
-		"contenido": titulo, "descripcion_larga": desc, "updated_at": time.Now(),
-	}).Error
+// GetActiveMembersForPush: IDs de los miembros activos que no dieron de baja la categoría por push
+func (r *Repository) GetActiveMembersForPush(categoria string) ([]int, error) {
+	var ids []int
+	err := r.db.Table("core_personas").
+		Where("core_personas.estado = 'ALTA'").
+		Where(filtroSinBaja, categoria, models.CanalNotifPush).
+		Pluck("core_personas.id", &ids).Error
+	return ids, err
 }
 
 func (r *Repository) UpdatePassword(personaID string, hash string) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
-		tx.Table("core_usuarios").Where("persona_id = ?", personaID).Updates(map[string]interface{}{"password_hash": hash, "password_changed_at": time.Now()})
+		tx.Table("core_usuarios").Where("persona_id = ?", personaID).Updates(map[string]interface{}{"password_hash": hash, "password_changed_at": time.Now(), "security_updated_at": time.Now()})
 		return tx.Table("core_personas").Where("id = ?", personaID).Updates(map[string]interface{}{"password_hash": hash, "password_changed_at": time.Now()}).Error
 	})
 }
 
+// GetPasswordHashByPersona: Hash vigente (core_usuarios tiene prioridad sobre core_personas)
+func (r *Repository) GetPasswordHashByPersona(personaID int) (string, error) {
+	var hash string
+	err := r.db.Table("core_usuarios").Select("password_hash").Where("persona_id = ? AND password_hash IS NOT NULL", personaID).Limit(1).Scan(&hash).Error
+	if err != nil {
+		return "", err
+	}
+	if hash == "" {
+		err = r.db.Table("core_personas").Select("COALESCE(password_hash, '')").Where("id = ?", personaID).Scan(&hash).Error
+	}
+	if err == nil && hash == "" {
+		err = gorm.ErrRecordNotFound
+	}
+	return hash, err
+}
+
 // --- QUERIES DE SEGURIDAD Y PIN ---
 
 func (r *Repository) CreatePin(pin string, expira time.Time) error {
@@ -287,14 +284,6 @@ func (r *Repository) VerifyPin(pin string) (int, error) {
 	return v.Id, nil
 }
 
-// SaveSecurityInfo guarda un registro simple de información de seguridad
-func (r *Repository) SaveSecurityInfo(contenido string) error {
-	return r.db.Table("core_seguridad_info").Create(map[string]interface{}{
-		"contenido":  contenido,
-		"updated_at": time.Now(),
-	}).Error
-}
-
 // --- QUERIES DE CATÁLOGO ---
 // Busca esta función y reemplázala por esta versión:
 func (r *Repository) GetPublicaciones() ([]models.Publicacion, error) {
@@ -0,0 +1,101 @@
+/**
+ * ARCHIVO: reuniones.go
+ * UBICACIÓN: internal/repository/reuniones.go
+ * DESCRIPCIÓN: Reuniones de la congregación (reu_reuniones) y la foto de presentes
+ * que se guarda al finalizarlas (reu_presentes). Hay a lo sumo una reunión abierta
+ * por congregación (índice único parcial sobre finalizada_at IS NULL).
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GetAncianoCongregacion devuelve la congregación de la persona si es anciano en
+// ALTA (situacion_1) o admin local; "" en otro caso
+func (r *Repository) GetAncianoCongregacion(personaID int) string {
+	if congID := r.GetAdminCongregacion(personaID); congID != "" {
+		return congID
+	}
+	var congID string
+	r.db.Table("core_personas").Select("congregacion_id").
+		Where("id = ? AND estado = 'ALTA' AND situacion_1 ILIKE ?", personaID, "anciano%").
+		Scan(&congID)
+	return congID
+}
+
+// GetOpenMeeting: Reunión sin finalizar de la congregación
+func (r *Repository) GetOpenMeeting(congID string) (*models.Reunion, error) {
+	var m models.Reunion
+	err := r.db.Table("reu_reuniones").
+		Where("congregacion_id = ? AND finalizada_at IS NULL", congID).
+		First(&m).Error
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// CreateMeeting abre una reunión (el índice único rechaza una segunda abierta)
+func (r *Repository) CreateMeeting(m *models.Reunion) error {
+	return r.db.Table("reu_reuniones").Create(m).Error
+}
+
+// EndMeeting cierra la reunión y guarda como presentes a las personas indicadas que
+// son de la congregación; 'presentes' queda con cuántas se registraron
+func (r *Repository) EndMeeting(id int, congID string, finalizadaPor int, personas []int) (*models.Reunion, error) {
+	var m models.Reunion
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Table("reu_reuniones").Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND congregacion_id = ? AND finalizada_at IS NULL", id, congID).
+			First(&m).Error
+		if err != nil {
+			return err
+		}
+
+		presentes := 0
+		if len(personas) > 0 {
+			res := tx.Exec(`INSERT INTO reu_presentes (reunion_id, persona_id)
+				SELECT ?, id FROM core_personas WHERE id IN ? AND congregacion_id = ?
+				ON CONFLICT DO NOTHING`, id, personas, congID)
+			if res.Error != nil {
+				return res.Error
+			}
+			presentes = int(res.RowsAffected)
+		}
+
+		ahora := time.Now().UTC()
+		m.FinalizadaAt, m.FinalizadaPor, m.Presentes = &ahora, &finalizadaPor, &presentes
+		return tx.Table("reu_reuniones").Where("id = ?", id).Updates(map[string]interface{}{
+			"finalizada_at": ahora, "finalizada_por": finalizadaPor, "presentes": presentes,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// GetMeetingRecord: Reunión de la congregación con sus presentes registrados
+func (r *Repository) GetMeetingRecord(id int, congID string) (*models.RegistroReunion, error) {
+	reg := &models.RegistroReunion{Asistentes: []models.PresenteReunion{}}
+	err := r.db.Table("reu_reuniones").
+		Where("id = ? AND congregacion_id = ?", id, congID).
+		First(&reg.Reunion).Error
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.Table("reu_presentes").
+		Select("core_personas.id as persona_id, core_personas.apellido_nombre as nombre, core_personas.grupo").
+		Joins("JOIN core_personas ON core_personas.id = reu_presentes.persona_id").
+		Where("reu_presentes.reunion_id = ?", id).
+		Order("core_personas.apellido_nombre").
+		Scan(&reg.Asistentes).Error
+	return reg, err
+}
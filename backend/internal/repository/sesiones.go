@@ -0,0 +1,145 @@
+/**
+ * ARCHIVO: sesiones.go
+ * UBICACIÓN: internal/repository/sesiones.go
+ * DESCRIPCIÓN: Persistencia de sesiones por dispositivo (core_sesiones).
+ * Permite listar, renovar y revocar sesiones individuales.
+ */
+
+package repository
+
+import (
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+)
+
+// CreateSession registra un nuevo dispositivo tras un login exitoso
+func (r *Repository) CreateSession(s *models.Sesion) error {
+	return r.db.Table("core_sesiones").Create(map[string]interface{}{
+		"id":            s.ID,
+		"persona_id":    s.PersonaID,
+		"usuario_id":    s.UsuarioID,
+		"user_agent":    s.UserAgent,
+		"ip":            s.IP,
+		"creado_at":     s.CreadoAt,
+		"ultimo_uso_at": s.UltimoUsoAt,
+		"expira_at":     s.ExpiraAt,
+	}).Error
+}
+
+// GetActiveSession devuelve la sesión solo si no fue revocada ni expiró
+func (r *Repository) GetActiveSession(id string) (*models.Sesion, error) {
+	var s models.Sesion
+	err := r.db.Table("core_sesiones").
+		Where("id = ? AND revocada_at IS NULL AND expira_at > ?", id, time.Now().UTC()).
+		First(&s).Error
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// TouchSession actualiza la última actividad y la IP del dispositivo
+func (r *Repository) TouchSession(id, ip string) error {
+	return r.db.Table("core_sesiones").Where("id = ?", id).Updates(map[string]interface{}{
+		"ultimo_uso_at": time.Now().UTC(), "ip": ip,
+	}).Error
+}
+
+// ListActiveSessions trae los dispositivos conectados de una persona (más recientes primero)
+func (r *Repository) ListActiveSessions(personaID int) ([]models.Sesion, error) {
+	var lista []models.Sesion
+	err := r.db.Table("core_sesiones").
+		Where("persona_id = ? AND revocada_at IS NULL AND expira_at > ?", personaID, time.Now().UTC()).
+		Order("ultimo_uso_at desc").
+		Find(&lista).Error
+	return lista, err
+}
+
+// RevokeSession cierra una sesión perteneciente a la persona indicada.
+// Devuelve cuántas filas cambiaron para distinguir "no existe" de "ya revocada".
+func (r *Repository) RevokeSession(id string, personaID, revocadaPor int) (int64, error) {
+	res := r.db.Table("core_sesiones").
+		Where("id = ? AND persona_id = ? AND revocada_at IS NULL", id, personaID).
+		Updates(map[string]interface{}{"revocada_at": time.Now().UTC(), "revocada_por": revocadaPor})
+	return res.RowsAffected, res.Error
+}
+
+// GetAdminCongregacion devuelve la congregación que administra la persona ("" si no es admin local)
+func (r *Repository) GetAdminCongregacion(personaID int) string {
+	var congID string
+	r.db.Table("core_usuarios").Select("congregacion_id").
+		Where("persona_id = ? AND es_admin_local = true", personaID).
+		Order("creado_at, id").Limit(1).
+		Scan(&congID)
+	return congID
+}
+
+// EsAdminLocalDe indica si la persona tiene una cuenta de admin local en esa congregación
+func (r *Repository) EsAdminLocalDe(personaID int, congID string) bool {
+	var n int64
+	r.db.Table("core_usuarios").
+		Where("persona_id = ? AND congregacion_id = ? AND es_admin_local = true", personaID, congID).
+		Count(&n)
+	return n > 0
+}
+
+// GetModulosUsuario devuelve los módulos que el usuario tiene permitidos en la congregación
+func (r *Repository) GetModulosUsuario(usuarioID, congID string) ([]string, error) {
+	var modulos []string
+	err := r.db.Table("core_permisos_modulos").
+		Distinct("modulo_id").
+		Where("usuario_id = ? AND congregacion_id = ? AND modulo_id IS NOT NULL", usuarioID, congID).
+		Pluck("modulo_id", &modulos).Error
+	return modulos, err
+}
+
+// GetUsernamesByPersona devuelve los alias de login de la persona (core_usuarios y core_personas)
+func (r *Repository) GetUsernamesByPersona(personaID int) []string {
+	var nombres []string
+	r.db.Raw(`
+		SELECT username_temp FROM core_usuarios WHERE persona_id = ? AND username_temp IS NOT NULL
+		UNION
+		SELECT username_temp FROM core_personas WHERE id = ? AND username_temp IS NOT NULL
+	`, personaID, personaID).Scan(&nombres)
+	return nombres
+}
+
+// GetPersonaCongregacion devuelve la congregación a la que pertenece la persona
+func (r *Repository) GetPersonaCongregacion(personaID int) string {
+	var congID string
+	r.db.Table("core_personas").Select("congregacion_id").Where("id = ?", personaID).Scan(&congID)
+	return congID
+}
+
+// EstadoSeguridad reúne los datos que invalidan llaves ya emitidas
+type EstadoSeguridad struct {
+	Estado                   string     // core_personas.estado (ALTA/BAJA)
+	EstadoCuenta             string     // core_usuarios.estado_cuenta (activa/suspendida)
+	PasswordChangedAt        *time.Time // Último cambio de clave de la persona
+	UsuarioPasswordChangedAt *time.Time // Último cambio de clave de la cuenta web
+	SecurityUpdatedAt        *time.Time // Último evento de seguridad (suspensión, clave, etc.)
+}
+
+// GetEstadoSeguridad consulta el estado de la persona y de sus cuentas web (si tiene).
+// Con varias cuentas se agregan todas: basta una suspendida, y cuenta el cambio de
+// clave o evento de seguridad más reciente de cualquiera.
+func (r *Repository) GetEstadoSeguridad(personaID int) (*EstadoSeguridad, error) {
+	var e EstadoSeguridad
+	err := r.db.Table("core_personas").
+		Select(`
+			core_personas.estado,
+			CASE WHEN bool_or(core_usuarios.estado_cuenta = 'suspendida') THEN 'suspendida' ELSE 'activa' END as estado_cuenta,
+			core_personas.password_changed_at,
+			max(core_usuarios.password_changed_at) as usuario_password_changed_at,
+			max(core_usuarios.security_updated_at) as security_updated_at
+		`).
+		Joins("LEFT JOIN core_usuarios ON core_usuarios.persona_id = core_personas.id").
+		Where("core_personas.id = ?", personaID).
+		Group("core_personas.id").
+		Take(&e).Error
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
@@ -0,0 +1,70 @@
+/**
+ * ARCHIVO: telefonos.go
+ * UBICACIÓN: internal/repository/telefonos.go
+ * DESCRIPCIÓN: Acceso a core_personas.telefono_e164 (columna indexada con el
+ * teléfono normalizado) y al país por defecto de cada congregación.
+ */
+
+package repository
+
+import "gorm.io/gorm"
+
+// GetPersonasByTelefono busca por igualdad sobre el índice cualquiera de las formas E.164
+// del número (máximo 2: basta para detectar ambigüedad)
+func (r *Repository) GetPersonasByTelefono(variantes []string) ([]int, error) {
+	var ids []int
+	err := r.db.Table("core_personas").Where("telefono_e164 IN ?", variantes).Limit(2).Pluck("id", &ids).Error
+	return ids, err
+}
+
+// GetCodigoPaisPersona devuelve el país por defecto (ISO alfa-2) de la congregación de la persona
+func (r *Repository) GetCodigoPaisPersona(personaID int) string {
+	var pais string
+	r.db.Table("core_personas").
+		Select("core_congregaciones.codigo_pais").
+		Joins("JOIN core_congregaciones ON core_congregaciones.id = core_personas.congregacion_id").
+		Where("core_personas.id = ?", personaID).
+		Scan(&pais)
+	return pais
+}
+
+// UpdateContacto guarda el teléfono tal como se escribió y su forma E.164 (NULL si no se pudo normalizar)
+func (r *Repository) UpdateContacto(personaID int, contacto, e164 string) error {
+	return r.db.Table("core_personas").Where("id = ?", personaID).Updates(map[string]interface{}{
+		"contacto":      contacto,
+		"telefono_e164": nullable(e164),
+	}).Error
+}
+
+// ContactoPendiente: Fila a normalizar por el comando de backfill
+type ContactoPendiente struct {
+	ID         int
+	Contacto   string
+	CodigoPais string
+}
+
+// ListContactosParaNormalizar recorre core_personas por lotes ordenados por id (paginación por cursor)
+func (r *Repository) ListContactosParaNormalizar(desdeID, lote int) ([]ContactoPendiente, error) {
+	var filas []ContactoPendiente
+	err := r.db.Table("core_personas").
+		Select("core_personas.id, core_personas.contacto, COALESCE(core_congregaciones.codigo_pais, '') as codigo_pais").
+		Joins("LEFT JOIN core_congregaciones ON core_congregaciones.id = core_personas.congregacion_id").
+		Where("core_personas.id > ? AND core_personas.contacto IS NOT NULL AND core_personas.contacto <> ''", desdeID).
+		Order("core_personas.id").
+		Limit(lote).
+		Scan(&filas).Error
+	return filas, err
+}
+
+// SetTelefonoE164 actualiza solo la columna normalizada
+func (r *Repository) SetTelefonoE164(personaID int, e164 string) error {
+	return r.db.Table("core_personas").Where("id = ?", personaID).Update("telefono_e164", nullable(e164)).Error
+}
+
+// nullable convierte la cadena vacía en NULL
+func nullable(v string) interface{} {
+	if v == "" {
+		return gorm.Expr("NULL")
+	}
+	return v
+}
@@ -13,7 +13,6 @@ import (
 	"net/http"
 
 	_ "gestion-congregacion/backend/docs"
-	"gestion-congregacion/backend/internal/ws"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
@@ -23,30 +22,88 @@ func RegisterRoutes(mux *http.ServeMux, svc *service.Service) {
 
 	// --- DOCUMENTACIÓN Y TIEMPO REAL ---
 	mux.Handle("/swagger/", httpSwagger.WrapHandler)
-	mux.HandleFunc("/ws", ws.WsHandler)
+	mux.Handle("GET /ws", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.WsHandler(svc))))
+	mux.Handle("GET /api/eventos", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.SseHandler(svc))))
+	mux.Handle("POST /api/eventos/{conexion}", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.ComandoTiempoRealHandler(svc))))
+	mux.HandleFunc("GET /api/eventos/esquema", handlers.EsquemaEventosHandler)
 
 	// --- RUTAS PÚBLICAS ---
 	mux.HandleFunc("/api/publicaciones", handlers.GetPublicaciones(svc))
 	mux.HandleFunc("/api/login-final", handlers.LoginFinalHandler(svc))
-	mux.HandleFunc("/api/identify-user", handlers.IdentifyUserHandler(svc))
-	mux.HandleFunc("/api/request-pin", handlers.RequestPinHandler(svc))
-	mux.HandleFunc("/api/verify-pin", handlers.VerifyPinHandler(svc))
-	mux.HandleFunc("/api/recover-user-id", handlers.RecoverByPersonaIDHandler(svc))
-	mux.HandleFunc("/api/send-username-real", handlers.SendUsernameRealHandler(svc))
 	mux.HandleFunc("/api/seguridad-info", handlers.GetSeguridadInfoHandler(svc))
-	mux.HandleFunc("/api/reset-password", handlers.ResetPasswordHandler(svc))
+
+	// Recuperación de acceso (respuesta uniforme: no revela si la cuenta existe)
+	mux.HandleFunc("POST /api/recovery/start", handlers.RecoveryStartHandler(svc))
+	mux.HandleFunc("POST /api/recovery/verify", handlers.RecoveryVerifyHandler(svc))
+	mux.HandleFunc("POST /api/reset-password", handlers.ResetPasswordHandler(svc))
+
+	// Baja de avisos desde el correo (enlace firmado, sin sesión)
+	mux.HandleFunc("GET /api/notificaciones/baja", handlers.BajaNotificacionHandler(svc))
+	mux.HandleFunc("POST /api/notificaciones/baja", handlers.BajaNotificacionHandler(svc))
 
 	// --- RUTAS PROTEGIDAS (Middleware Aplicado) ---
 	// Perfil
-	mux.Handle("/api/update-profile", handlers.AuthMiddleware(http.HandlerFunc(handlers.UpdateProfileDataHandler(svc))))
-	mux.Handle("/api/upload-foto", handlers.AuthMiddleware(http.HandlerFunc(handlers.UploadFotoHandler(svc))))
-	mux.Handle("/api/suspender-cuenta", handlers.AuthMiddleware(http.HandlerFunc(handlers.SuspenderCuentaHandler(svc))))
+	mux.Handle("/api/update-profile", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.UpdateProfileDataHandler(svc))))
+	mux.Handle("/api/upload-foto", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.UploadFotoHandler(svc))))
+	mux.Handle("POST /api/change-password", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.ChangePasswordHandler(svc))))
+	mux.Handle("/api/request-pin", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.RequestPinHandler(svc))))
+	mux.Handle("/api/verify-pin", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.VerifyPinHandler(svc))))
+	mux.Handle("/api/suspender-cuenta", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.SuspenderCuentaHandler(svc))))
+
+	// Preferencias de notificación (categoría × canal)
+	mux.Handle("GET /api/preferencias", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.ListPreferenciasHandler(svc))))
+	mux.Handle("POST /api/preferencias", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.UpdatePreferenciaHandler(svc))))
 
-	mux.HandleFunc("/api/logout", handlers.LogoutHandler)
+	// Dispositivos conectados (sesiones propias)
+	mux.Handle("GET /api/sesiones", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.ListSesionesHandler(svc))))
+	mux.Handle("POST /api/sesiones/revocar", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.RevocarSesionHandler(svc))))
+
+	mux.HandleFunc("/api/logout", handlers.LogoutHandler(svc))
 
 	// Administración de Seguridad
-	mux.Handle("/api/broadcast-seguridad", handlers.AuthMiddleware(http.HandlerFunc(handlers.BroadcastSeguridadUpdateHandler(svc))))
-	mux.Handle("/api/save-seguridad-info", handlers.AuthMiddleware(http.HandlerFunc(handlers.SaveSeguridadInfoHandler(svc))))
+	mux.Handle("/api/broadcast-seguridad", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.BroadcastSeguridadUpdateHandler(svc))))
+	mux.Handle("GET /api/boletines", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.ListBoletinesHandler(svc))))
+	mux.Handle("POST /api/boletines", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.CreateBoletinHandler(svc))))
+	mux.Handle("GET /api/boletines/{id}", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.GetBoletinHandler(svc))))
+	mux.Handle("PUT /api/boletines/{id}", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.UpdateBoletinHandler(svc))))
+	mux.Handle("DELETE /api/boletines/{id}", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.DeleteBoletinHandler(svc))))
+	mux.Handle("GET /api/boletines/{id}/historial", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.HistorialBoletinHandler(svc))))
+	mux.Handle("POST /api/boletines/{id}/publicar", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.PublicarBoletinHandler(svc))))
+	mux.Handle("POST /api/boletines/{id}/acuse", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.AcuseBoletinHandler(svc))))
+	mux.Handle("GET /api/boletines/{id}/lecturas", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.LecturasBoletinHandler(svc))))
+	mux.Handle("POST /api/boletines/{id}/recordatorio", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.RecordatorioBoletinHandler(svc))))
+	mux.Handle("GET /api/boletines/{id}/difusion", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.DifusionProgresoHandler(svc))))
+	mux.Handle("/api/save-seguridad-info", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.SaveSeguridadInfoHandler(svc))))
+
+	// Administración de Miembros (solo es_admin_local de la misma congregación)
+	mux.Handle("GET /api/admin/sesiones", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.AdminListSesionesHandler(svc))))
+	mux.Handle("POST /api/admin/sesiones/revocar", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.AdminRevocarSesionHandler(svc))))
+	mux.Handle("POST /api/admin/reactivar-cuenta", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.AdminReactivarCuentaHandler(svc))))
+	mux.Handle("POST /api/admin/desbloquear-cuenta", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.AdminDesbloquearCuentaHandler(svc))))
+	mux.Handle("GET /api/admin/tiempo-real", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.MetricasTiempoRealHandler(svc))))
+	mux.Handle("GET /api/admin/auditoria", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.AdminAuditoriaHandler(svc))))
+
+	// Mostrador de literatura (admin local): cada pedido que queda listo avisa al hermano
+	mux.Handle("POST /api/publicaciones/stock", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.LlegadaStockHandler(svc))))
+	mux.Handle("POST /api/publicaciones/pedidos/{id}/apartar", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.ApartarPedidoHandler(svc))))
+
+	// Reuniones: presencia en vivo y registro de presentes (abrir, finalizar y registro: ancianos)
+	mux.Handle("POST /api/reuniones", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.IniciarReunionHandler(svc))))
+	mux.Handle("GET /api/reuniones/en-curso", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.ReunionEnCursoHandler(svc))))
+	mux.Handle("GET /api/reuniones/{id}", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.RegistroReunionHandler(svc))))
+	mux.Handle("POST /api/reuniones/{id}/finalizar", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.FinalizarReunionHandler(svc))))
+
+	// Asistencia a las reuniones (ancianos, admins locales y quienes editan el módulo de reuniones)
+	mux.Handle("GET /api/asistencia", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.ListAsistenciaHandler(svc))))
+	mux.Handle("PUT /api/asistencia", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.RegistrarAsistenciaHandler(svc))))
+	mux.Handle("DELETE /api/asistencia/{id}", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.DeleteAsistenciaHandler(svc))))
+	mux.Handle("GET /api/asistencia/informe", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.InformeAsistenciaHandler(svc))))
+
+	// Informe mensual de servicio (pendientes: superintendentes de grupo; totales: secretarios)
+	mux.Handle("GET /api/informes/mio", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.MiInformeHandler(svc))))
+	mux.Handle("PUT /api/informes/mio", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.EntregarInformeHandler(svc))))
+	mux.Handle("GET /api/informes/pendientes", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.InformesPendientesHandler(svc))))
+	mux.Handle("GET /api/informes/totales", handlers.AuthMiddleware(svc, http.HandlerFunc(handlers.TotalesInformesHandler(svc))))
 
 	// Utilitarios
 	mux.HandleFunc("/api/upload-backend", handlers.HandleFileUpload(svc))
@@ -0,0 +1,143 @@
+/**
+ * ARCHIVO: acuses.go
+ * UBICACIÓN: internal/service/acuses.go
+ * DESCRIPCIÓN: Quién leyó cada boletín. Abrir un boletín lo marca como leído y el
+ * botón "Entendido" lo confirma. Los admins locales ven el porcentaje de su
+ * congregación por grupo y pueden recordar el boletín a quienes no lo confirmaron.
+ */
+
+package service
+
+import (
+	"errors"
+	"log"
+	"math"
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/plantillas"
+	"gestion-congregacion/backend/internal/ws"
+)
+
+// Recordatorios de boletines sin confirmar
+const (
+	RecordatorioDiasPorDefecto = 7
+	MaxDiasRecordatorio        = 365
+)
+
+var ErrDiasInvalidos = errors.New("los días deben estar entre 1 y 365")
+
+// AcknowledgeBulletin registra la lectura (o la confirmación) de un boletín publicado
+func (s *Service) AcknowledgeBulletin(personaID, boletinID int, confirmar bool) error {
+	if _, err := s.repo.GetBulletin(boletinID, false); err != nil {
+		return boletinNoEncontrado(err)
+	}
+	return s.repo.RegisterAcknowledgement(boletinID, personaID, confirmar)
+}
+
+// porcentaje con un decimal (0 si no hay miembros)
+func porcentaje(parte, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return math.Round(float64(parte)*1000/float64(total)) / 10
+}
+
+// totalizarLecturas completa los porcentajes de cada grupo y devuelve el total de la congregación
+func totalizarLecturas(grupos []models.LecturaGrupo) models.LecturaGrupo {
+	var total models.LecturaGrupo
+	for i := range grupos {
+		g := &grupos[i]
+		g.PorcentajeLeido = porcentaje(g.Leidos, g.Miembros)
+		g.PorcentajeConfirmado = porcentaje(g.Confirmados, g.Miembros)
+		total.Miembros += g.Miembros
+		total.Leidos += g.Leidos
+		total.Confirmados += g.Confirmados
+	}
+	total.PorcentajeLeido = porcentaje(total.Leidos, total.Miembros)
+	total.PorcentajeConfirmado = porcentaje(total.Confirmados, total.Miembros)
+	return total
+}
+
+// GetAcknowledgementStats: Lecturas del boletín en la congregación del admin, por grupo
+func (s *Service) GetAcknowledgementStats(adminPersonaID, boletinID int) (*models.LecturasBoletin, error) {
+	congAdmin := s.repo.GetAdminCongregacion(adminPersonaID)
+	if congAdmin == "" {
+		return nil, ErrSinPermiso
+	}
+	if _, err := s.repo.GetBulletin(boletinID, false); err != nil {
+		return nil, boletinNoEncontrado(err)
+	}
+
+	grupos, err := s.repo.GetAcknowledgementStats(boletinID, congAdmin)
+	if err != nil {
+		return nil, err
+	}
+	return &models.LecturasBoletin{
+		BoletinID:      boletinID,
+		CongregacionID: congAdmin,
+		Congregacion:   totalizarLecturas(grupos),
+		Grupos:         grupos,
+	}, nil
+}
+
+// RemindUnacknowledged vuelve a avisar (correo y push, según preferencias) a los miembros de
+// la congregación que no confirmaron el boletín pasados 'dias' desde su publicación o desde
+// el último recordatorio. Devuelve a cuántos se les recordó.
+func (s *Service) RemindUnacknowledged(adminPersonaID, boletinID, dias int) (int, error) {
+	congAdmin := s.repo.GetAdminCongregacion(adminPersonaID)
+	if congAdmin == "" {
+		return 0, ErrSinPermiso
+	}
+	if dias == 0 {
+		dias = RecordatorioDiasPorDefecto
+	}
+	if dias < 0 || dias > MaxDiasRecordatorio {
+		return 0, ErrDiasInvalidos
+	}
+	b, err := s.repo.GetBulletin(boletinID, false)
+	if err != nil {
+		return 0, boletinNoEncontrado(err)
+	}
+
+	antes := time.Now().UTC().AddDate(0, 0, -dias)
+	if b.PublicadoAt != nil && b.PublicadoAt.After(antes) {
+		return 0, nil // Todavía no pasó el plazo desde la publicación
+	}
+	pendientes, err := s.repo.GetUnacknowledged(boletinID, congAdmin, antes)
+	if err != nil {
+		return 0, err
+	}
+
+	personas := make([]int, 0, len(pendientes))
+	var avisos []*models.EmailOutbox
+	for _, u := range pendientes {
+		personas = append(personas, u.PersonaID)
+		if u.Email == "" || !s.acepta(u.PersonaID, models.CategoriaSeguridad, models.CanalNotifEmail) {
+			continue
+		}
+		baja := URLBaja(u.PersonaID, models.CategoriaSeguridad, models.CanalNotifEmail)
+		m, err := plantillas.Render(plantillas.RecordatorioSeguridad, u.Idioma, plantillas.Datos{Nombre: u.NombreCompleto, Titulo: b.Titulo, Descripcion: b.DescripcionLarga, BajaURL: baja})
+		if err != nil {
+			return 0, err
+		}
+		aviso := nuevoCorreo(u.Email, m.Asunto, m.HTML, m.Texto, false)
+		aviso.PersonaID = &u.PersonaID
+		aviso.BajaURL = baja
+		avisos = append(avisos, aviso)
+	}
+
+	if err := s.repo.RegisterReminders(boletinID, adminPersonaID, personas, avisos); err != nil {
+		return 0, err
+	}
+	for _, id := range personas {
+		if s.acepta(id, models.CategoriaSeguridad, models.CanalNotifPush) {
+			ws.SendToPersona(id, ws.NuevoEvento(ws.EventoRecordatorioSeguridad, ws.RecordatorioSeguridad{
+				BoletinID: boletinID,
+				Titulo:    b.Titulo,
+			}))
+		}
+	}
+	log.Printf("🔔 Boletín %d recordado a %d miembros (%d por correo)", boletinID, len(personas), len(avisos))
+	return len(personas), nil
+}
@@ -0,0 +1,177 @@
+/**
+ * ARCHIVO: asistencia.go
+ * UBICACIÓN: internal/service/asistencia.go
+ * DESCRIPCIÓN: Registro de la concurrencia a las reuniones (presencial y por video)
+ * que el secretario informa cada mes. Los promedios mensuales se calculan acá para
+ * que la pantalla y la exportación CSV muestren exactamente los mismos números.
+ */
+
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+)
+
+// Los informes abarcan como mucho dos años de servicio
+const MaxMesesInforme = 24
+
+var (
+	ErrAsistenciaInvalida     = errors.New("la asistencia necesita una fecha AAAA-MM-DD no futura, un tipo de reunión válido y cantidades no negativas")
+	ErrPeriodoInvalido        = errors.New("el período debe indicarse como AAAA-MM, con 'desde' anterior a 'hasta' y a lo sumo 24 meses")
+	ErrAsistenciaNoEncontrada = errors.New("el registro de asistencia no existe")
+)
+
+// validarAsistencia interpreta la fecha (se acepta hasta mañana por las zonas horarias)
+func validarAsistencia(fecha, tipo string, presencial, video int, hoy time.Time) (time.Time, error) {
+	f, err := time.Parse(time.DateOnly, fecha)
+	if err != nil || f.After(hoy.AddDate(0, 0, 1)) {
+		return time.Time{}, ErrAsistenciaInvalida
+	}
+	if tipo != models.ReunionEntreSemana && tipo != models.ReunionFinDeSemana {
+		return time.Time{}, ErrAsistenciaInvalida
+	}
+	if presencial < 0 || video < 0 {
+		return time.Time{}, ErrAsistenciaInvalida
+	}
+	return f, nil
+}
+
+// periodoAsistencia convierte los meses AAAA-MM en el rango [desde, hasta). Sin meses
+// se usa el año de servicio en curso (septiembre a agosto).
+func periodoAsistencia(desde, hasta string, hoy time.Time) (time.Time, time.Time, error) {
+	if desde == "" && hasta == "" {
+		anio := hoy.Year()
+		if hoy.Month() < time.September {
+			anio--
+		}
+		inicio := time.Date(anio, time.September, 1, 0, 0, 0, 0, time.UTC)
+		return inicio, inicio.AddDate(1, 0, 0), nil
+	}
+	d, err1 := time.Parse("2006-01", desde)
+	h, err2 := time.Parse("2006-01", hasta)
+	if err1 != nil || err2 != nil || h.Before(d) {
+		return time.Time{}, time.Time{}, ErrPeriodoInvalido
+	}
+	fin := h.AddDate(0, 1, 0)
+	if fin.After(d.AddDate(0, MaxMesesInforme, 0)) {
+		return time.Time{}, time.Time{}, ErrPeriodoInvalido
+	}
+	return d, fin, nil
+}
+
+// promediarAsistencia agrupa los registros por mes y tipo de reunión, ordenados
+func promediarAsistencia(registros []models.Asistencia) []models.PromedioAsistencia {
+	indice := make(map[[2]string]*models.PromedioAsistencia)
+	for _, a := range registros {
+		clave := [2]string{a.Fecha.Format("2006-01"), a.Tipo}
+		p := indice[clave]
+		if p == nil {
+			p = &models.PromedioAsistencia{Mes: clave[0], Tipo: clave[1]}
+			indice[clave] = p
+		}
+		p.Reuniones++
+		p.TotalPresencial += a.Presencial
+		p.TotalVideo += a.Video
+	}
+
+	meses := make([]models.PromedioAsistencia, 0, len(indice))
+	for _, p := range indice {
+		p.Total = p.TotalPresencial + p.TotalVideo
+		p.PromedioPresencial = promedio(p.TotalPresencial, p.Reuniones)
+		p.PromedioVideo = promedio(p.TotalVideo, p.Reuniones)
+		p.Promedio = promedio(p.Total, p.Reuniones)
+		meses = append(meses, *p)
+	}
+	sort.Slice(meses, func(i, j int) bool {
+		if meses[i].Mes != meses[j].Mes {
+			return meses[i].Mes < meses[j].Mes
+		}
+		return meses[i].Tipo < meses[j].Tipo
+	})
+	return meses
+}
+
+// promedio redondeado al entero más cercano, como se informa la asistencia
+func promedio(total, reuniones int) int {
+	if reuniones == 0 {
+		return 0
+	}
+	return int(math.Round(float64(total) / float64(reuniones)))
+}
+
+// SaveAttendance registra (o corrige) la asistencia de una reunión de la congregación
+func (s *Service) SaveAttendance(personaID int, fecha, tipo string, presencial, video int) (*models.Asistencia, error) {
+	congID := s.repo.GetSecretarioCongregacion(personaID)
+	if congID == "" {
+		return nil, ErrSinPermiso
+	}
+	f, err := validarAsistencia(fecha, tipo, presencial, video, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	a := &models.Asistencia{
+		CongregacionID: congID, Fecha: f, Tipo: tipo, Presencial: presencial, Video: video,
+		RegistradoPor: personaID, UpdatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.SaveAttendance(a); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// ListAttendance: Registros de la congregación en el período (meses AAAA-MM)
+func (s *Service) ListAttendance(personaID int, desde, hasta string) ([]models.Asistencia, error) {
+	congID := s.repo.GetSecretarioCongregacion(personaID)
+	if congID == "" {
+		return nil, ErrSinPermiso
+	}
+	d, h, err := periodoAsistencia(desde, hasta, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.ListAttendance(congID, d, h)
+}
+
+// DeleteAttendance borra un registro cargado por error
+func (s *Service) DeleteAttendance(personaID, id int) error {
+	congID := s.repo.GetSecretarioCongregacion(personaID)
+	if congID == "" {
+		return ErrSinPermiso
+	}
+	n, err := s.repo.DeleteAttendance(id, congID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrAsistenciaNoEncontrada
+	}
+	return nil
+}
+
+// GetAttendanceReport: Promedios mensuales por tipo de reunión para el informe
+func (s *Service) GetAttendanceReport(personaID int, desde, hasta string) (*models.InformeAsistencia, error) {
+	congID := s.repo.GetSecretarioCongregacion(personaID)
+	if congID == "" {
+		return nil, ErrSinPermiso
+	}
+	d, h, err := periodoAsistencia(desde, hasta, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	registros, err := s.repo.ListAttendance(congID, d, h)
+	if err != nil {
+		return nil, err
+	}
+	return &models.InformeAsistencia{
+		CongregacionID: congID,
+		Desde:          d.Format("2006-01"),
+		Hasta:          h.AddDate(0, -1, 0).Format("2006-01"),
+		Meses:          promediarAsistencia(registros),
+	}, nil
+}
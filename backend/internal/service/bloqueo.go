@@ -0,0 +1,142 @@
+/**
+ * ARCHIVO: bloqueo.go
+ * UBICACIÓN: internal/service/bloqueo.go
+ * DESCRIPCIÓN: Bloqueo anti fuerza bruta por cuenta (complementa el contador por IP).
+ * Los contadores se indexan por un HMAC del nombre de usuario (LIMITES_SECRET) y se
+ * aplican igual exista o no la cuenta, para no revelar qué usuarios están registrados.
+ */
+
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/plantillas"
+)
+
+// Política de bloqueo por cuenta
+const (
+	FallosAntesDeRetraso = 3                // A partir de aquí cada intento exige una espera creciente
+	FallosParaBloqueo    = 10               // Al llegar aquí la cuenta queda bloqueada temporalmente
+	DuracionBloqueo      = 15 * time.Minute // Duración del bloqueo temporal
+	VentanaFallos        = time.Hour        // Los fallos se olvidan tras una hora sin intentos
+)
+
+// ErrEspera indica que la cuenta debe esperar antes de un nuevo intento
+type ErrEspera struct {
+	Espera time.Duration
+}
+
+func (e *ErrEspera) Error() string {
+	return fmt.Sprintf("SISTEMA: Demasiados intentos fallidos. Intente nuevamente en %d segundos.", int(e.Espera.Seconds()))
+}
+
+// retrasoPorFallos calcula la espera obligatoria según la cantidad de fallos acumulados
+// (1s, 2s, 4s ... 64s y luego el bloqueo completo)
+func retrasoPorFallos(fallos int) time.Duration {
+	switch {
+	case fallos < FallosAntesDeRetraso:
+		return 0
+	case fallos >= FallosParaBloqueo:
+		return DuracionBloqueo
+	default:
+		return time.Duration(1<<(fallos-FallosAntesDeRetraso)) * time.Second
+	}
+}
+
+// secretoLimites: LIMITES_SECRET indexa los bloqueos y límites por cuenta. No debe
+// rotar: cambiarlo reinicia todos los contadores (por eso no se usa JWT_SECRET, que
+// rota con su 'kid'); sin definirla se usa JWT_SECRET, como antes.
+func secretoLimites() []byte {
+	if s := os.Getenv("LIMITES_SECRET"); s != "" {
+		return []byte(s)
+	}
+	return []byte(os.Getenv("JWT_SECRET"))
+}
+
+// claveCuenta ofusca el nombre de usuario para que Redis no exponga qué cuentas se atacan
+func claveCuenta(username string) string {
+	mac := hmac.New(sha256.New, secretoLimites())
+	mac.Write([]byte(strings.TrimSpace(strings.ToLower(username))))
+	return hex.EncodeToString(mac.Sum(nil))[:32]
+}
+
+// esperaPendiente devuelve cuánto falta para que la cuenta pueda volver a intentar
+func (s *Service) esperaPendiente(ctx context.Context, username string) time.Duration {
+	ttl, err := s.rdb.TTL(ctx, "lock_user:"+claveCuenta(username)).Result()
+	if err != nil || ttl <= 0 {
+		return 0
+	}
+	return ttl
+}
+
+// registrarFalloCuenta suma un fallo a la cuenta y aplica el retraso correspondiente.
+// 'u' es nil cuando el usuario no existe: el contador se aplica igual.
+func (s *Service) registrarFalloCuenta(ctx context.Context, username string, u *models.Usuario, ip string) {
+	clave := claveCuenta(username)
+	fallos, err := s.rdb.Incr(ctx, "failed_user:"+clave).Result()
+	if err != nil {
+		return
+	}
+	s.rdb.Expire(ctx, "failed_user:"+clave, VentanaFallos)
+
+	if espera := retrasoPorFallos(int(fallos)); espera > 0 {
+		s.rdb.Set(ctx, "lock_user:"+clave, "1", espera)
+	}
+
+	// Avisamos al titular solo una vez, al entrar en bloqueo
+	if int(fallos) == FallosParaBloqueo && u != nil && u.Email != "" {
+		log.Printf("🔒 CUENTA BLOQUEADA: '%s' tras %d intentos fallidos", username, fallos)
+		go s.notificarBloqueo(u, ip)
+	}
+}
+
+// limpiarFallosCuenta reinicia los contadores tras un login exitoso o un desbloqueo
+func (s *Service) limpiarFallosCuenta(ctx context.Context, username string) {
+	clave := claveCuenta(username)
+	s.rdb.Del(ctx, "failed_user:"+clave, "lock_user:"+clave)
+}
+
+// notificarBloqueo avisa al titular que su cuenta fue bloqueada temporalmente
+func (s *Service) notificarBloqueo(u *models.Usuario, ip string) {
+	m, err := plantillas.Render(plantillas.Bloqueo, u.Idioma, plantillas.Datos{
+		Nombre:   u.NombreCompleto,
+		Intentos: FallosParaBloqueo,
+		IP:       ip,
+		Minutos:  int(DuracionBloqueo.Minutes()),
+	})
+	if err == nil {
+		err = s.outbox.EnqueueEmail(nuevoCorreo(u.Email, m.Asunto, m.HTML, m.Texto, false))
+	}
+	if err != nil {
+		log.Println("❌ Error al notificar bloqueo:", err)
+	}
+}
+
+// UnlockAccount: Un admin local levanta el bloqueo por intentos fallidos de un miembro
+func (s *Service) UnlockAccount(adminPersonaID, miembroPersonaID int) error {
+	if err := s.authorizeAdminOver(adminPersonaID, miembroPersonaID); err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for _, username := range s.repo.GetUsernamesByPersona(miembroPersonaID) {
+		s.limpiarFallosCuenta(ctx, username)
+	}
+
+	return s.repo.SaveAuditoria(&models.Auditoria{
+		ActorPersonaID: adminPersonaID,
+		Accion:         models.AccionDesbloqueo,
+		PersonaID:      miembroPersonaID,
+		CongregacionID: s.repo.GetPersonaCongregacion(miembroPersonaID),
+	})
+}
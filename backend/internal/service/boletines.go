@@ -0,0 +1,311 @@
+/**
+ * ARCHIVO: boletines.go
+ * UBICACIÓN: internal/service/boletines.go
+ * DESCRIPCIÓN: Boletines de seguridad: borradores, edición con historial, baja y
+ * publicación. Publicar un boletín (nuevo o borrador) lanza su difusión por correo
+ * y el aviso en tiempo real. Solo los admins locales escriben o ven borradores, y
+ * cada boletín lo edita, borra o publica solo la congregación que lo creó (guardada
+ * en el boletín). Los boletines anteriores, sin congregación, los gestiona cualquier
+ * admin local.
+ */
+
+package service
+
+import (
+	"errors"
+	"log"
+	"strings"
+
+	"gestion-congregacion/backend/internal/markdown"
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/plantillas"
+	"gestion-congregacion/backend/internal/ws"
+
+	"gorm.io/gorm"
+)
+
+// Paginación de boletines
+const (
+	BoletinesPorPagina    = 20
+	MaxBoletinesPorPagina = 100
+	MaxLongitudTitulo     = 200
+)
+
+var (
+	ErrBoletinNoEncontrado = errors.New("el boletín no existe")
+	ErrBoletinInvalido     = errors.New("el boletín necesita un título (máx. 200 caracteres)")
+	ErrBoletinPublicado    = errors.New("el boletín ya fue publicado")
+)
+
+// PaginaBoletines: Respuesta del listado
+type PaginaBoletines struct {
+	Boletines []models.Boletin `json:"boletines"`
+	Total     int64            `json:"total"`
+	Pagina    int              `json:"pagina"`
+	PorPagina int              `json:"por_pagina"`
+}
+
+// esEditorBoletines: Cualquier admin local redacta boletines y ve los borradores
+func (s *Service) esEditorBoletines(personaID int) bool {
+	return s.repo.GetAdminCongregacion(personaID) != ""
+}
+
+// congregacionBoletin: Congregación que administrará un boletín nuevo ("" si el editor no es admin local)
+func (s *Service) congregacionBoletin(editor int) *string {
+	congAdmin := s.repo.GetAdminCongregacion(editor)
+	if congAdmin == "" {
+		return nil
+	}
+	return &congAdmin
+}
+
+// puedeGestionarBoletin: Un boletín lo gestiona un admin local de su congregación; los
+// anteriores a congregacion_id (congBoletin "") quedan a cargo de cualquier admin local
+func puedeGestionarBoletin(congBoletin string, esAdminDe func(congID string) bool) bool {
+	return congBoletin == "" || esAdminDe(congBoletin)
+}
+
+// autorizarBoletin: Un boletín existente solo lo edita, borra o publica un admin local
+// de la congregación que lo creó (la difusión llega a todas las congregaciones)
+func (s *Service) autorizarBoletin(editor, id int) error {
+	if !s.esEditorBoletines(editor) {
+		return ErrSinPermiso
+	}
+	congBoletin, err := s.repo.GetCongregacionBoletin(id)
+	if err != nil {
+		return boletinNoEncontrado(err)
+	}
+	esAdminDe := func(congID string) bool { return s.repo.EsAdminLocalDe(editor, congID) }
+	if !puedeGestionarBoletin(congBoletin, esAdminDe) {
+		return ErrSinPermiso
+	}
+	return nil
+}
+
+// boletinNoEncontrado traduce el "record not found" de GORM
+func boletinNoEncontrado(err error) error {
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrBoletinNoEncontrado
+	}
+	return err
+}
+
+// validarBoletin limpia espacios y exige título
+func validarBoletin(titulo, desc string) (string, string, error) {
+	titulo, desc = strings.TrimSpace(titulo), strings.TrimSpace(desc)
+	if titulo == "" || len([]rune(titulo)) > MaxLongitudTitulo {
+		return "", "", ErrBoletinInvalido
+	}
+	return titulo, desc, nil
+}
+
+// nuevoBoletin valida y guarda junto al Markdown su HTML saneado
+func nuevoBoletin(titulo, desc, estado string) (*models.Boletin, error) {
+	titulo, desc, err := validarBoletin(titulo, desc)
+	if err != nil {
+		return nil, err
+	}
+	return &models.Boletin{Titulo: titulo, DescripcionLarga: desc, DescripcionHTML: markdown.Render(desc), Estado: estado}, nil
+}
+
+// ListBulletins: Los miembros ven los publicados; los admins también los borradores
+func (s *Service) ListBulletins(personaID, pagina, porPagina int) (*PaginaBoletines, error) {
+	if pagina < 1 {
+		pagina = 1
+	}
+	if porPagina < 1 {
+		porPagina = BoletinesPorPagina
+	}
+	if porPagina > MaxBoletinesPorPagina {
+		porPagina = MaxBoletinesPorPagina
+	}
+
+	lista, total, err := s.repo.ListBulletins(s.esEditorBoletines(personaID), pagina, porPagina)
+	if err != nil {
+		return nil, err
+	}
+	return &PaginaBoletines{Boletines: lista, Total: total, Pagina: pagina, PorPagina: porPagina}, nil
+}
+
+// GetBulletin: Un boletín (los borradores solo para admins). Abrir uno publicado cuenta como leído.
+func (s *Service) GetBulletin(personaID, id int) (*models.Boletin, error) {
+	b, err := s.repo.GetBulletin(id, s.esEditorBoletines(personaID))
+	if err != nil {
+		return nil, boletinNoEncontrado(err)
+	}
+	if b.Estado == models.BoletinPublicado {
+		if err := s.repo.RegisterAcknowledgement(id, personaID, false); err != nil {
+			log.Println("❌ Error al registrar la lectura del boletín:", err)
+		}
+	}
+	return b, nil
+}
+
+// GetBulletinHistory: Versiones guardadas de un boletín (solo admins)
+func (s *Service) GetBulletinHistory(personaID, id int) ([]models.VersionBoletin, error) {
+	if !s.esEditorBoletines(personaID) {
+		return nil, ErrSinPermiso
+	}
+	if _, err := s.repo.GetBulletin(id, true); err != nil {
+		return nil, boletinNoEncontrado(err)
+	}
+	return s.repo.GetBulletinHistory(id)
+}
+
+// CreateBulletin guarda un borrador o, si 'publicar', lo publica con su difusión
+func (s *Service) CreateBulletin(editor int, titulo, desc string, publicar bool) (*models.Boletin, *models.Difusion, error) {
+	if publicar {
+		d, err := s.BroadcastSecurity(editor, titulo, desc)
+		if err != nil {
+			return nil, nil, err
+		}
+		b, err := s.repo.GetBulletin(d.BoletinID, true)
+		return b, d, err
+	}
+
+	cong := s.congregacionBoletin(editor)
+	if cong == nil {
+		return nil, nil, ErrSinPermiso
+	}
+	b, err := nuevoBoletin(titulo, desc, models.BoletinBorrador)
+	if err != nil {
+		return nil, nil, err
+	}
+	b.CongregacionID = cong
+	return b, nil, s.repo.CreateBulletin(b, editor)
+}
+
+// UpdateBulletin edita un boletín (publicado o no); no vuelve a difundirlo
+func (s *Service) UpdateBulletin(editor, id int, titulo, desc string) (*models.Boletin, error) {
+	if err := s.autorizarBoletin(editor, id); err != nil {
+		return nil, err
+	}
+	titulo, desc, err := validarBoletin(titulo, desc)
+	if err != nil {
+		return nil, err
+	}
+	b, err := s.repo.UpdateBulletin(id, titulo, desc, markdown.Render(desc), editor)
+	return b, boletinNoEncontrado(err)
+}
+
+// DeleteBulletin: Baja lógica de un boletín
+func (s *Service) DeleteBulletin(editor, id int) error {
+	if err := s.autorizarBoletin(editor, id); err != nil {
+		return err
+	}
+	return boletinNoEncontrado(s.repo.DeleteBulletin(id, editor))
+}
+
+// PublishBulletin publica un borrador y lanza su difusión
+func (s *Service) PublishBulletin(editor, id int) (*models.Difusion, error) {
+	if err := s.autorizarBoletin(editor, id); err != nil {
+		return nil, err
+	}
+
+	var titulo string
+	d, err := s.repo.PublishBulletin(id, editor, func(b *models.Boletin) ([]*models.EmailOutbox, error) {
+		titulo = b.Titulo
+		return s.avisosBoletin(b.Titulo, b.DescripcionLarga)
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		// No existe o ya no es borrador
+		if _, errGet := s.repo.GetBulletin(id, true); errGet == nil {
+			return nil, ErrBoletinPublicado
+		}
+		return nil, ErrBoletinNoEncontrado
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s.alertarBoletin(titulo)
+	return d, nil
+}
+
+// BroadcastSecurity: Publica un boletín nuevo y registra su difusión a cada miembro activo.
+// Los correos quedan en el outbox y el worker los entrega a la tasa de DIFUSION_CORREOS_POR_MINUTO.
+func (s *Service) BroadcastSecurity(creadoPor int, titulo, desc string) (*models.Difusion, error) {
+	cong := s.congregacionBoletin(creadoPor)
+	if cong == nil {
+		return nil, ErrSinPermiso
+	}
+	b, err := nuevoBoletin(titulo, desc, models.BoletinPublicado)
+	if err != nil {
+		return nil, err
+	}
+	b.CongregacionID = cong
+	avisos, err := s.avisosBoletin(b.Titulo, b.DescripcionLarga)
+	if err != nil {
+		return nil, err
+	}
+
+	// 1. Persistencia en base de datos (el boletín, la difusión y sus correos, juntos)
+	d, err := s.repo.CreateBroadcast(b, creadoPor, avisos)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Notificación Push en tiempo real vía WebSocket
+	s.alertarBoletin(b.Titulo)
+	return d, nil
+}
+
+// avisosBoletin arma un correo localizado por cada miembro activo que no dio de baja la categoría
+func (s *Service) avisosBoletin(titulo, desc string) ([]*models.EmailOutbox, error) {
+	lista, err := s.repo.GetActiveMembersForBroadcast(models.CategoriaSeguridad)
+	if err != nil {
+		log.Println("❌ Error al obtener destinatarios:", err)
+		return nil, err
+	}
+
+	avisos := make([]*models.EmailOutbox, 0, len(lista))
+	for _, u := range lista {
+		// Plantilla localizada: el título se escapa y la descripción (Markdown) pasa por internal/markdown
+		baja := URLBaja(u.PersonaID, models.CategoriaSeguridad, models.CanalNotifEmail)
+		m, err := plantillas.Render(plantillas.AvisoSeguridad, u.Idioma, plantillas.Datos{Nombre: u.NombreCompleto, Titulo: titulo, Descripcion: desc, BajaURL: baja})
+		if err != nil {
+			return nil, err
+		}
+		aviso := nuevoCorreo(u.Email, m.Asunto, m.HTML, m.Texto, false)
+		aviso.PersonaID = &u.PersonaID
+		aviso.BajaURL = baja
+		avisos = append(avisos, aviso)
+	}
+	return avisos, nil
+}
+
+// alertarBoletin avisa en tiempo real que hay un boletín nuevo, solo a los miembros
+// que no dieron de baja la categoría de seguridad por push
+func (s *Service) alertarBoletin(titulo string) {
+	personas, err := s.repo.GetActiveMembersForPush(models.CategoriaSeguridad)
+	if err != nil {
+		log.Printf("⚠️ No se pudo avisar por push el boletín %q: %v", titulo, err)
+		return
+	}
+	evento := ws.NuevoEvento(ws.EventoAlertaSeguridad, ws.AlertaSeguridad{
+		Titulo:  titulo,
+		Mensaje: "Se ha publicado una nueva actualización de seguridad.",
+	})
+	for _, id := range personas {
+		ws.SendToPersona(id, evento)
+	}
+}
+
+// AddSecurityInfo publica una nota sin difusión por correo ('desc' en Markdown, opcional)
+func (s *Service) AddSecurityInfo(editor int, cont, desc string) error {
+	cong := s.congregacionBoletin(editor)
+	if cong == nil {
+		return ErrSinPermiso
+	}
+	bol, err := nuevoBoletin(cont, desc, models.BoletinPublicado)
+	if err != nil {
+		return err
+	}
+	bol.CongregacionID = cong
+	return s.repo.CreateBulletin(bol, editor)
+}
+
+// GetSecurityBulletin: Último boletín publicado (título y descripción larga)
+func (s *Service) GetSecurityBulletin() (*models.Boletin, error) {
+	return s.repo.GetLatestSecurityInfo()
+}
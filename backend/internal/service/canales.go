@@ -0,0 +1,90 @@
+/**
+ * ARCHIVO: canales.go
+ * UBICACIÓN: internal/service/canales.go
+ * DESCRIPCIÓN: Entrega de códigos y datos de acceso por el canal preferido del
+ * miembro (email, SMS o WhatsApp). Si el canal telefónico no está habilitado, no hay
+ * teléfono válido o el proveedor falla, se recurre al correo registrado.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"gestion-congregacion/backend/internal/mensajeria"
+	"gestion-congregacion/backend/internal/plantillas"
+	"gestion-congregacion/backend/internal/repository"
+)
+
+var ErrSinCanal = errors.New("no hay un correo ni un teléfono registrado para enviar el código")
+
+// canalPara decide por dónde se entrega; "" si el miembro no tiene ningún medio utilizable
+func (s *Service) canalPara(c *repository.ContactoRecuperacion) mensajeria.Canal {
+	preferido := mensajeria.Canal(c.Canal)
+	if _, habilitado := s.telefonos[preferido]; habilitado && c.Telefono != "" {
+		return preferido
+	}
+	if c.Email != "" {
+		return mensajeria.CanalEmail
+	}
+	return ""
+}
+
+// canalTelefonico: Canal telefónico habilitado para el número del miembro, empezando por
+// su preferido (luego SMS y WhatsApp); "" si no tiene teléfono o no hay ninguno habilitado
+func (s *Service) canalTelefonico(c *repository.ContactoRecuperacion) mensajeria.Canal {
+	if c.Telefono == "" {
+		return ""
+	}
+	for _, canal := range []mensajeria.Canal{mensajeria.Canal(c.Canal), mensajeria.CanalSMS, mensajeria.CanalWhatsApp} {
+		if _, habilitado := s.telefonos[canal]; habilitado && canal != mensajeria.CanalEmail {
+			return canal
+		}
+	}
+	return ""
+}
+
+// destinoEnmascarado muestra al usuario adónde se envió el código sin revelarlo completo
+func destinoEnmascarado(c *repository.ContactoRecuperacion, canal mensajeria.Canal) string {
+	if canal == mensajeria.CanalEmail {
+		return enmascararEmail(c.Email)
+	}
+	return enmascararTelefono(c.Telefono)
+}
+
+// asteriscosTelefono: Siempre los mismos, así el largo no distingue un número de otro
+const asteriscosTelefono = 7
+
+// enmascararTelefono conserva el código de país y los últimos 4 dígitos: "+54*******4567"
+func enmascararTelefono(e164 string) string {
+	if len(e164) < 8 {
+		return "***"
+	}
+	return e164[:3] + strings.Repeat("*", asteriscosTelefono) + e164[len(e164)-4:]
+}
+
+// entregar envía el mensaje por el canal elegido (SMS/WhatsApp usan el texto plano);
+// ante un fallo telefónico recurre al email.
+// El correo no se envía aquí: se encola en el outbox y lo entrega el worker.
+func (s *Service) entregar(c *repository.ContactoRecuperacion, canal mensajeria.Canal, m *plantillas.Correo) {
+	if n, ok := s.telefonos[canal]; ok && canal != mensajeria.CanalEmail {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		err := n.Send(ctx, c.Telefono, m.Texto)
+		if err == nil {
+			return
+		}
+		log.Printf("❌ Error al enviar por %s a persona %d: %v", canal, c.PersonaID, err)
+		if c.Email == "" {
+			return
+		}
+	}
+
+	if err := s.outbox.EnqueueEmail(nuevoCorreo(c.Email, m.Asunto, m.HTML, m.Texto, true)); err != nil {
+		log.Println("❌ Error al encolar correo de seguridad:", err)
+	}
+}
@@ -0,0 +1,50 @@
+/**
+ * ARCHIVO: cuentas.go
+ * UBICACIÓN: internal/service/cuentas.go
+ * DESCRIPCIÓN: Administración del estado de las cuentas de miembros.
+ * Toda acción queda registrada en core_auditoria.
+ */
+
+package service
+
+import (
+	"strings"
+
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/plantillas"
+)
+
+// ReactivateAccount: Un admin local devuelve el acceso a un miembro suspendido o dado de baja
+func (s *Service) ReactivateAccount(adminPersonaID, miembroPersonaID int, motivo string) error {
+	if err := s.authorizeAdminOver(adminPersonaID, miembroPersonaID); err != nil {
+		return err
+	}
+	return s.repo.ReactivateAccount(miembroPersonaID, &models.Auditoria{
+		ActorPersonaID: adminPersonaID,
+		Accion:         models.AccionReactivacion,
+		PersonaID:      miembroPersonaID,
+		CongregacionID: s.repo.GetPersonaCongregacion(miembroPersonaID),
+		Detalle:        strings.TrimSpace(motivo),
+	}, s.avisoCuenta(miembroPersonaID, plantillas.CuentaReactivada))
+}
+
+// avisoCuenta arma el correo al titular sobre un cambio de estado (nil si no tiene email)
+func (s *Service) avisoCuenta(personaID int, tipo plantillas.Tipo) *models.EmailOutbox {
+	contacto, err := s.repo.GetContactoByPersona(personaID)
+	if err != nil || contacto.Email == "" {
+		return nil
+	}
+	m, err := plantillas.Render(tipo, contacto.Idioma, plantillas.Datos{Nombre: contacto.NombreCompleto})
+	if err != nil {
+		return nil
+	}
+	return nuevoCorreo(contacto.Email, m.Asunto, m.HTML, m.Texto, false)
+}
+
+// GetAuditTrail: Historial de acciones administrativas sobre un miembro
+func (s *Service) GetAuditTrail(adminPersonaID, miembroPersonaID int) ([]models.Auditoria, error) {
+	if err := s.authorizeAdminOver(adminPersonaID, miembroPersonaID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListAuditoria(miembroPersonaID)
+}
@@ -0,0 +1,82 @@
+/**
+ * ARCHIVO: difusiones.go
+ * UBICACIÓN: internal/service/difusiones.go
+ * DESCRIPCIÓN: Control de tasa y seguimiento de las difusiones masivas. La tasa se
+ * reparte con una ventana por minuto en Redis, así varias instancias del worker
+ * juntas no superan DIFUSION_CORREOS_POR_MINUTO.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// TasaDifusionPorDefecto: Correos de difusión por minuto si no se configura la variable
+const TasaDifusionPorDefecto = 60
+
+// ErrDifusionNoEncontrada: El boletín no existe o se publicó sin difusión
+var ErrDifusionNoEncontrada = errors.New("el boletín no tiene difusión registrada")
+
+// TasaDifusion lee DIFUSION_CORREOS_POR_MINUTO (valores inválidos usan el defecto)
+func TasaDifusion() int {
+	if n, err := strconv.Atoi(os.Getenv("DIFUSION_CORREOS_POR_MINUTO")); err == nil && n > 0 {
+		return n
+	}
+	return TasaDifusionPorDefecto
+}
+
+// cupoDisponible: Cuánto de lo reservado cabe en la ventana ('usados' ya incluye la reserva)
+func cupoDisponible(tasa, reservados int, usados int64) int {
+	exceso := int(usados) - tasa
+	if exceso <= 0 {
+		return reservados
+	}
+	if exceso >= reservados {
+		return 0
+	}
+	return reservados - exceso
+}
+
+// reservarCupoDifusion reserva hasta 'lote' envíos en la ventana del minuto actual.
+// Sin Redis no se difunde: los correos esperan en el outbox (los transaccionales siguen).
+func (s *Service) reservarCupoDifusion(ctx context.Context, lote int) (string, int) {
+	ventana := fmt.Sprintf("difusion_cupo:%d", time.Now().Unix()/60)
+	usados, err := s.rdb.IncrBy(ctx, ventana, int64(lote)).Result()
+	if err != nil {
+		return "", 0
+	}
+	s.rdb.Expire(ctx, ventana, 2*time.Minute)
+	return ventana, cupoDisponible(TasaDifusion(), lote, usados)
+}
+
+// devolverCupoDifusion libera la parte de la reserva que no se usó
+func (s *Service) devolverCupoDifusion(ctx context.Context, ventana string, n int) {
+	if ventana == "" || n <= 0 {
+		return
+	}
+	s.rdb.DecrBy(ctx, ventana, int64(n))
+}
+
+// GetBroadcastProgress: Avance y fallos de la difusión de un boletín (solo admins locales;
+// el detalle de fallos muestra únicamente a los miembros de su congregación)
+func (s *Service) GetBroadcastProgress(adminPersonaID, boletinID int) (*models.ProgresoDifusion, error) {
+	congAdmin := s.repo.GetAdminCongregacion(adminPersonaID)
+	if congAdmin == "" {
+		return nil, ErrSinPermiso
+	}
+	p, err := s.repo.GetBroadcastProgress(boletinID, congAdmin)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDifusionNoEncontrada
+	}
+	return p, err
+}
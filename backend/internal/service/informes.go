@@ -0,0 +1,223 @@
+/**
+ * ARCHIVO: informes.go
+ * UBICACIÓN: internal/service/informes.go
+ * DESCRIPCIÓN: Informe mensual de servicio. Cada miembro entrega el suyo (participó,
+ * cursos bíblicos y, si es precursor, horas); el superintendente de grupo ve quiénes
+ * faltan en su grupo y el secretario obtiene los totales de la congregación por categoría.
+ */
+
+package service
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// Un informe puede entregarse o corregirse hasta un año después del mes informado
+	MaxMesesAtrasoInforme = 12
+	MaxLongitudComentario = 500
+)
+
+var (
+	ErrInformeInvalido     = errors.New("el informe necesita un mes AAAA-MM terminado del último año, cantidades no negativas y horas solo para precursores")
+	ErrMesInvalido         = errors.New("el mes debe indicarse como AAAA-MM y ya debe haber terminado")
+	ErrInformeNoEncontrado = errors.New("no hay informe para ese mes")
+	ErrSinGrupo            = errors.New("indique el grupo: la persona no tiene grupo asignado")
+	ErrPersonaNoHabilitada = errors.New("solo los miembros en ALTA pueden entregar el informe")
+)
+
+// DatosInforme: Lo que entrega el miembro
+type DatosInforme struct {
+	Mes               string `json:"mes"` // AAAA-MM
+	Participo         bool   `json:"participo"`
+	EstudiosBiblicos  int    `json:"estudios_biblicos"`
+	Horas             *int   `json:"horas"`
+	PrecursorAuxiliar bool   `json:"precursor_auxiliar"` // Fue precursor auxiliar ese mes
+	Comentarios       string `json:"comentarios"`
+}
+
+// mesInforme interpreta AAAA-MM (vacío: el mes anterior, que es el que se informa).
+// Solo se aceptan meses terminados: el en curso todavía no se puede informar.
+func mesInforme(mes string, hoy time.Time) (time.Time, error) {
+	anterior := time.Date(hoy.Year(), hoy.Month(), 1, 0, 0, 0, 0, time.UTC).AddDate(0, -1, 0)
+	if mes == "" {
+		return anterior, nil
+	}
+	m, err := time.Parse("2006-01", mes)
+	if err != nil || m.After(anterior) {
+		return time.Time{}, ErrMesInvalido
+	}
+	return m, nil
+}
+
+// categoriaInforme: Regular/especial/auxiliar salen de situacion_2; el resto es
+// publicador salvo que haya sido precursor auxiliar ese mes
+func categoriaInforme(situacion2 string, auxiliar bool) string {
+	s := strings.ToLower(situacion2)
+	switch {
+	case strings.Contains(s, "regular"):
+		return models.CategoriaPrecursorRegular
+	case strings.Contains(s, "especial"):
+		return models.CategoriaPrecursorEspecial
+	case strings.Contains(s, "auxiliar"), auxiliar:
+		return models.CategoriaPrecursorAuxiliar
+	default:
+		return models.CategoriaPublicador
+	}
+}
+
+// nuevoInforme valida lo que entregó el miembro. Dirigir un curso o informar horas
+// implica haber participado.
+func nuevoInforme(d DatosInforme, situacion2 string, hoy time.Time) (*models.InformeServicio, error) {
+	mes, err := mesInforme(d.Mes, hoy)
+	if err != nil || d.Mes == "" {
+		return nil, ErrInformeInvalido
+	}
+	actual := time.Date(hoy.Year(), hoy.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if mes.Before(actual.AddDate(0, -MaxMesesAtrasoInforme, 0)) {
+		return nil, ErrInformeInvalido
+	}
+	categoria := categoriaInforme(situacion2, d.PrecursorAuxiliar)
+	if d.EstudiosBiblicos < 0 || (d.Horas != nil && (*d.Horas < 0 || categoria == models.CategoriaPublicador)) {
+		return nil, ErrInformeInvalido
+	}
+	comentarios := strings.TrimSpace(d.Comentarios)
+	if len([]rune(comentarios)) > MaxLongitudComentario {
+		return nil, ErrInformeInvalido
+	}
+
+	return &models.InformeServicio{
+		Mes:              mes,
+		Categoria:        categoria,
+		Participo:        d.Participo || d.EstudiosBiblicos > 0 || (d.Horas != nil && *d.Horas > 0),
+		EstudiosBiblicos: d.EstudiosBiblicos,
+		Horas:            d.Horas,
+		Comentarios:      comentarios,
+	}, nil
+}
+
+// completarCategorias devuelve todas las categorías en orden, en cero las que no tuvieron informes
+func completarCategorias(filas []models.TotalCategoria) []models.TotalCategoria {
+	porCategoria := make(map[string]models.TotalCategoria, len(filas))
+	for _, f := range filas {
+		porCategoria[f.Categoria] = f
+	}
+	lista := make([]models.TotalCategoria, len(models.CategoriasInforme))
+	for i, c := range models.CategoriasInforme {
+		lista[i] = porCategoria[c]
+		lista[i].Categoria = c
+	}
+	return lista
+}
+
+// SubmitServiceReport: El miembro entrega (o corrige) su propio informe del mes
+func (s *Service) SubmitServiceReport(personaID int, d DatosInforme) (*models.InformeServicio, error) {
+	p, err := s.repo.GetDatosServicio(personaID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPersonaNoHabilitada
+	}
+	if err != nil {
+		return nil, err
+	}
+	if p.Estado != "ALTA" || p.CongregacionID == "" {
+		return nil, ErrPersonaNoHabilitada
+	}
+
+	ahora := time.Now().UTC()
+	i, err := nuevoInforme(d, p.Situacion2, ahora)
+	if err != nil {
+		return nil, err
+	}
+	i.CongregacionID, i.PersonaID, i.Grupo = p.CongregacionID, personaID, p.Grupo
+	i.EnviadoAt, i.UpdatedAt = ahora, ahora
+	if err := s.repo.SaveServiceReport(i); err != nil {
+		return nil, err
+	}
+	return i, nil
+}
+
+// GetOwnServiceReport: El informe que entregó el miembro para el mes
+func (s *Service) GetOwnServiceReport(personaID int, mes string) (*models.InformeServicio, error) {
+	m, err := mesInforme(mes, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	i, err := s.repo.GetServiceReport(personaID, m)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInformeNoEncontrado
+	}
+	return i, err
+}
+
+// GetMissingReports: Quiénes no entregaron el informe del mes en un grupo. El
+// superintendente ve solo su grupo; el secretario puede pedir cualquiera (grupo nil:
+// el propio).
+func (s *Service) GetMissingReports(personaID int, mes string, grupo *int) (*models.PendientesGrupo, error) {
+	congID := s.repo.GetSecretarioInformes(personaID)
+	congGrupo, propio := s.repo.GetSuperintendenteGrupo(personaID)
+	switch {
+	case congID != "":
+		if grupo == nil {
+			if p, err := s.repo.GetDatosServicio(personaID); err == nil {
+				grupo = p.Grupo
+			}
+		}
+	case congGrupo != "":
+		if grupo != nil && *grupo != *propio {
+			return nil, ErrSinPermiso
+		}
+		congID, grupo = congGrupo, propio
+	default:
+		return nil, ErrSinPermiso
+	}
+	if grupo == nil {
+		return nil, ErrSinGrupo
+	}
+
+	m, err := mesInforme(mes, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	pendientes, entregados, err := s.repo.GetMissingReports(congID, *grupo, m)
+	if err != nil {
+		return nil, err
+	}
+	return &models.PendientesGrupo{
+		Mes: m.Format("2006-01"), Grupo: *grupo, Entregados: int(entregados), Pendientes: pendientes,
+	}, nil
+}
+
+// GetReportTotals: Totales de la congregación por categoría para el informe del secretario
+func (s *Service) GetReportTotals(personaID int, mes string) (*models.TotalesInformes, error) {
+	congID := s.repo.GetSecretarioInformes(personaID)
+	if congID == "" {
+		return nil, ErrSinPermiso
+	}
+	m, err := mesInforme(mes, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	filas, err := s.repo.GetReportTotals(congID, m)
+	if err != nil {
+		return nil, err
+	}
+	pendientes, err := s.repo.CountMissingReports(congID, m)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &models.TotalesInformes{
+		CongregacionID: congID, Mes: m.Format("2006-01"),
+		Categorias: completarCategorias(filas), Pendientes: int(pendientes),
+	}
+	for _, c := range t.Categorias {
+		t.Entregados += c.Informes
+	}
+	return t, nil
+}
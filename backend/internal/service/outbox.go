@@ -0,0 +1,132 @@
+/**
+ * ARCHIVO: outbox.go
+ * UBICACIÓN: internal/service/outbox.go
+ * DESCRIPCIÓN: Worker de la bandeja de salida de correos. Toma lotes pendientes de
+ * core_email_outbox, los entrega con el EmailSender inyectado y reintenta con
+ * espera exponencial; al agotar los intentos el correo pasa a dead-letter ('fallido').
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gestion-congregacion/backend/internal/correo"
+	"gestion-congregacion/backend/internal/models"
+)
+
+// Política de entrega
+const (
+	MaxIntentosEmail   = 8                // Luego el correo pasa a dead-letter
+	EsperaBaseEmail    = 30 * time.Second // Primer reintento; luego se duplica
+	EsperaMaximaEmail  = time.Hour
+	IntervaloOutbox    = 5 * time.Second
+	LoteOutbox         = 50
+	ReservaOutbox      = 2 * time.Minute // Si el worker muere con el lote tomado, otro lo retoma
+	TimeoutEnvioCorreo = 20 * time.Second
+)
+
+// bandejaCorreos: Lo que el servicio (encolar) y el worker (entregar) necesitan de core_email_outbox
+type bandejaCorreos interface {
+	EnqueueEmail(emails ...*models.EmailOutbox) error
+	ClaimPendingEmails(lote, cupoDifusion int, reserva time.Duration) ([]models.EmailOutbox, error)
+	MarkEmailSent(e *models.EmailOutbox) error
+	MarkEmailRetry(e *models.EmailOutbox, proximo time.Time, causa string) error
+	MarkEmailDead(e *models.EmailOutbox, causa string) error
+	CompleteBroadcasts() error
+}
+
+// nuevoCorreo arma una fila del outbox; 'sensible' borra el cuerpo tras la entrega (PINs)
+func nuevoCorreo(para, asunto, html, texto string, sensible bool) *models.EmailOutbox {
+	return &models.EmailOutbox{Destinatario: para, Asunto: asunto, HTML: html, Texto: texto, Sensible: sensible}
+}
+
+// esperaReintento: 30s, 1m, 2m, 4m... con tope de una hora
+func esperaReintento(intentos int) time.Duration {
+	espera := EsperaBaseEmail
+	for i := 1; i < intentos && espera < EsperaMaximaEmail; i++ {
+		espera *= 2
+	}
+	if espera > EsperaMaximaEmail {
+		return EsperaMaximaEmail
+	}
+	return espera
+}
+
+// aDeadLetter decide si un fallo es definitivo (error permanente o intentos agotados)
+func aDeadLetter(intentosPrevios int, err error) bool {
+	return errors.Is(err, correo.ErrPermanente) || intentosPrevios+1 >= MaxIntentosEmail
+}
+
+// StartEmailWorker procesa el outbox hasta que se cancele el contexto
+func (s *Service) StartEmailWorker(ctx context.Context) {
+	ticker := time.NewTicker(IntervaloOutbox)
+	defer ticker.Stop()
+	for {
+		s.vaciarOutbox(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// vaciarOutbox entrega todo lo vencido antes de volver a esperar
+func (s *Service) vaciarOutbox(ctx context.Context) {
+	for ctx.Err() == nil && s.procesarOutbox(ctx) {
+	}
+}
+
+// procesarOutbox entrega un lote e indica si puede quedar más vencido: el lote
+// transaccional vino lleno o la difusión usó todo su cupo. Un lote trae hasta
+// LoteOutbox transaccionales más los masivos, así que su largo no alcanza para saberlo.
+func (s *Service) procesarOutbox(ctx context.Context) bool {
+	ventana, cupo := s.reservarCupoDifusion(ctx, LoteOutbox)
+	emails, err := s.outbox.ClaimPendingEmails(LoteOutbox, cupo, ReservaOutbox)
+	if err != nil {
+		log.Println("❌ Error al leer el outbox de correos:", err)
+		s.devolverCupoDifusion(ctx, ventana, LoteOutbox)
+		return false
+	}
+
+	// Lo reservado y no usado vuelve a la ventana para otras instancias
+	masivos := 0
+	for _, e := range emails {
+		if e.DifusionID != nil {
+			masivos++
+		}
+	}
+	s.devolverCupoDifusion(ctx, ventana, LoteOutbox-masivos)
+	quedan := len(emails)-masivos >= LoteOutbox || (cupo > 0 && masivos >= cupo)
+
+	for i := range emails {
+		e := &emails[i]
+		envioCtx, cancel := context.WithTimeout(ctx, TimeoutEnvioCorreo)
+		err := s.correo.Send(envioCtx, correo.Mensaje{Para: []string{e.Destinatario}, Asunto: e.Asunto, HTML: e.HTML, Texto: e.Texto, BajaURL: e.BajaURL})
+		cancel()
+
+		switch {
+		case err == nil:
+			err = s.outbox.MarkEmailSent(e)
+		case aDeadLetter(e.Intentos, err):
+			log.Printf("☠️ Correo %d a dead-letter tras %d intentos: %v", e.ID, e.Intentos+1, err)
+			err = s.outbox.MarkEmailDead(e, err.Error())
+		default:
+			err = s.outbox.MarkEmailRetry(e, time.Now().UTC().Add(esperaReintento(e.Intentos+1)), err.Error())
+		}
+		if err != nil {
+			log.Printf("❌ Error al actualizar el correo %d del outbox: %v", e.ID, err)
+		}
+	}
+
+	if masivos > 0 {
+		if err := s.outbox.CompleteBroadcasts(); err != nil {
+			log.Println("❌ Error al cerrar difusiones:", err)
+		}
+	}
+	return quedan
+}
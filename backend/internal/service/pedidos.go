@@ -0,0 +1,147 @@
+/**
+ * ARCHIVO: pedidos.go
+ * UBICACIÓN: internal/service/pedidos.go
+ * DESCRIPCIÓN: Aviso "su literatura está lista". Cuando un pedido pasa a 'listo'
+ * (llegó stock o el siervo lo apartó) se avisa al hermano por correo (outbox),
+ * push (WebSocket) y SMS/WhatsApp según sus preferencias de la categoría literatura.
+ * Los avisos se arman después del commit: si el reparto se deshace, nadie recibe nada.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gestion-congregacion/backend/internal/mensajeria"
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/plantillas"
+	"gestion-congregacion/backend/internal/repository"
+	"gestion-congregacion/backend/internal/ws"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCantidadInvalida   = errors.New("la cantidad debe ser mayor a cero")
+	ErrPedidoNoDisponible = errors.New("el pedido no existe o ya no está en espera")
+)
+
+// acepta: Punto único que consultan los emisores antes de avisar a un miembro
+func (s *Service) acepta(personaID int, categoria, canal string) bool {
+	return s.repo.AceptaNotificacion(personaID, categoria, canal)
+}
+
+// RegisterStockArrival: El admin local registra la llegada de una publicación y se
+// apartan los pedidos en espera que alcanzan a cubrirse
+func (s *Service) RegisterStockArrival(adminPersonaID int, publicacionID string, cantidad int) ([]models.Pedido, error) {
+	congAdmin := s.repo.GetAdminCongregacion(adminPersonaID)
+	if congAdmin == "" {
+		return nil, ErrSinPermiso
+	}
+	if cantidad <= 0 || publicacionID == "" {
+		return nil, ErrCantidadInvalida
+	}
+
+	listos, err := s.repo.RegisterStockArrival(congAdmin, publicacionID, cantidad)
+	if err != nil {
+		return nil, err
+	}
+	s.avisarLiteraturaLista(listos...)
+	return listos, nil
+}
+
+// SetAsideOrder: El admin local aparta un pedido puntual de su congregación
+func (s *Service) SetAsideOrder(adminPersonaID, pedidoID int) (*models.Pedido, error) {
+	congAdmin := s.repo.GetAdminCongregacion(adminPersonaID)
+	if congAdmin == "" {
+		return nil, ErrSinPermiso
+	}
+
+	p, err := s.repo.SetAsideOrder(congAdmin, pedidoID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPedidoNoDisponible
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.avisarLiteraturaLista(*p)
+	return p, nil
+}
+
+// avisarLiteraturaLista encola los correos de los pedidos ya confirmados como listos y
+// envía el push y el SMS/WhatsApp de cada uno
+func (s *Service) avisarLiteraturaLista(listos ...models.Pedido) {
+	avisos := make([]*models.EmailOutbox, 0, len(listos))
+	for _, p := range listos {
+		avisos = append(avisos, s.avisoLiteratura(p))
+	}
+	if err := s.outbox.EnqueueEmail(avisos...); err != nil {
+		log.Println("❌ Error al encolar los avisos de literatura:", err)
+	}
+	for _, p := range listos {
+		s.notificarLiteraturaLista(p)
+	}
+}
+
+// avisoLiteratura arma el correo del pedido listo (nil: el hermano no lo recibe por email)
+func (s *Service) avisoLiteratura(p models.Pedido) *models.EmailOutbox {
+	if !s.acepta(p.PersonaID, models.CategoriaLiteratura, models.CanalNotifEmail) {
+		return nil
+	}
+	contacto, err := s.repo.GetContactoByPersona(p.PersonaID)
+	if err != nil || contacto.Email == "" {
+		return nil
+	}
+
+	baja := URLBaja(p.PersonaID, models.CategoriaLiteratura, models.CanalNotifEmail)
+	m, err := plantillas.Render(plantillas.LiteraturaLista, contacto.Idioma, datosLiteratura(contacto, p, baja))
+	if err != nil {
+		log.Println("❌ Error al armar el aviso de literatura:", err)
+		return nil
+	}
+	aviso := nuevoCorreo(contacto.Email, m.Asunto, m.HTML, m.Texto, false)
+	aviso.PersonaID = &p.PersonaID
+	aviso.BajaURL = baja
+	return aviso
+}
+
+// datosLiteratura: Valores de la plantilla literatura_lista
+func datosLiteratura(c *repository.ContactoRecuperacion, p models.Pedido, baja string) plantillas.Datos {
+	return plantillas.Datos{Nombre: c.NombreCompleto, Publicacion: p.NombrePublicacion, Cantidad: p.Cantidad, BajaURL: baja}
+}
+
+// notificarLiteraturaLista envía el push y, si el canal preferido del hermano es
+// telefónico, el SMS/WhatsApp (el correo va por el outbox)
+func (s *Service) notificarLiteraturaLista(p models.Pedido) {
+	if s.acepta(p.PersonaID, models.CategoriaLiteratura, models.CanalNotifPush) {
+		ws.SendToPersona(p.PersonaID, ws.NuevoEvento(ws.EventoLiteraturaLista, ws.LiteraturaLista{
+			PedidoID:    p.ID,
+			Publicacion: p.NombrePublicacion,
+			Cantidad:    p.Cantidad,
+		}))
+	}
+
+	contacto, err := s.repo.GetContactoByPersona(p.PersonaID)
+	if err != nil {
+		return
+	}
+	canal := s.canalPara(contacto)
+	if canal == "" || canal == mensajeria.CanalEmail || !s.acepta(p.PersonaID, models.CategoriaLiteratura, string(canal)) {
+		return
+	}
+
+	m, err := plantillas.Render(plantillas.LiteraturaLista, contacto.Idioma, datosLiteratura(contacto, p, ""))
+	if err != nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+		defer cancel()
+		if err := s.telefonos[canal].Send(ctx, contacto.Telefono, m.Texto); err != nil {
+			log.Printf("❌ Error al avisar por %s el pedido %d: %v", canal, p.ID, err)
+		}
+	}()
+}
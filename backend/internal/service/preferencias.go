@@ -0,0 +1,138 @@
+/**
+ * ARCHIVO: preferencias.go
+ * UBICACIÓN: internal/service/preferencias.go
+ * DESCRIPCIÓN: Preferencias de notificación por categoría y canal, y enlaces de baja
+ * firmados (HMAC) que permiten desactivar una categoría en un clic desde el correo,
+ * sin iniciar sesión. Los mensajes de la cuenta (PIN, bloqueo) no se pueden desactivar.
+ */
+
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gestion-congregacion/backend/internal/auth"
+	"gestion-congregacion/backend/internal/models"
+)
+
+// AppURLPorDefecto coincide con el origen permitido por defecto en main.go
+const AppURLPorDefecto = "https://gestion-congregacion.vercel.app"
+
+var (
+	ErrPreferenciaInvalida = errors.New("categoría o canal de notificación inválido")
+	ErrBajaInvalida        = errors.New("enlace de baja inválido")
+)
+
+// preferenciaValida: La categoría y el canal deben ser de los conocidos
+func preferenciaValida(categoria, canal string) bool {
+	return slices.Contains(models.CategoriasNotificacion, categoria) && slices.Contains(models.CanalesNotificacion, canal)
+}
+
+// ListPreferencias devuelve la matriz completa categoría × canal (lo no guardado, activo)
+func (s *Service) ListPreferencias(personaID int) ([]models.PreferenciaNotificacion, error) {
+	guardadas, err := s.repo.GetPreferencias(personaID)
+	if err != nil {
+		return nil, err
+	}
+
+	lista := make([]models.PreferenciaNotificacion, 0, len(models.CategoriasNotificacion)*len(models.CanalesNotificacion))
+	for _, cat := range models.CategoriasNotificacion {
+		for _, canal := range models.CanalesNotificacion {
+			p := models.PreferenciaNotificacion{Categoria: cat, Canal: canal, Activo: true}
+			for _, g := range guardadas {
+				if g.Categoria == cat && g.Canal == canal {
+					p = g
+				}
+			}
+			lista = append(lista, p)
+		}
+	}
+	return lista, nil
+}
+
+// UpdatePreferencia: El miembro activa o desactiva una categoría en un canal
+func (s *Service) UpdatePreferencia(personaID int, categoria, canal string, activo bool) error {
+	if !preferenciaValida(categoria, canal) {
+		return ErrPreferenciaInvalida
+	}
+	return s.repo.SetPreferencia(personaID, categoria, canal, activo)
+}
+
+// secretoBaja: UNSUBSCRIBE_SECRET firma los enlaces de baja. Es independiente de
+// JWT_SECRET para que rotar las llaves de sesión no rompa enlaces que no vencen;
+// sin definirla se usa JWT_SECRET, como antes.
+func secretoBaja() []byte {
+	if s := os.Getenv("UNSUBSCRIBE_SECRET"); s != "" {
+		return []byte(s)
+	}
+	return []byte(os.Getenv("JWT_SECRET"))
+}
+
+// firmaBaja: HMAC de los datos del enlace (prefijo propio para no reutilizar otras firmas)
+func firmaBaja(secreto []byte, datos string) string {
+	mac := hmac.New(sha256.New, secreto)
+	mac.Write([]byte("baja:" + datos))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// firmaBajaValida acepta la firma del secreto vigente y la de cualquier llave JWT,
+// actual o anterior, con la que se firmaron los enlaces ya enviados
+func firmaBajaValida(datos, firma string) bool {
+	for _, secreto := range append([][]byte{secretoBaja()}, auth.Secretos()...) {
+		if len(secreto) > 0 && hmac.Equal([]byte(firma), []byte(firmaBaja(secreto, datos))) {
+			return true
+		}
+	}
+	return false
+}
+
+// TokenBaja: "<persona>.<categoria>.<canal>.<firma>". No vence: un enlace de baja debe
+// funcionar aunque el correo se abra meses después.
+func TokenBaja(personaID int, categoria, canal string) string {
+	datos := fmt.Sprintf("%d.%s.%s", personaID, categoria, canal)
+	return datos + "." + firmaBaja(secretoBaja(), datos)
+}
+
+// leerTokenBaja valida la firma y devuelve a quién y qué dar de baja
+func leerTokenBaja(token string) (int, string, string, error) {
+	partes := strings.Split(token, ".")
+	if len(partes) != 4 {
+		return 0, "", "", ErrBajaInvalida
+	}
+	datos := strings.Join(partes[:3], ".")
+	if !firmaBajaValida(datos, partes[3]) {
+		return 0, "", "", ErrBajaInvalida
+	}
+	personaID, err := strconv.Atoi(partes[0])
+	if err != nil || !preferenciaValida(partes[1], partes[2]) {
+		return 0, "", "", ErrBajaInvalida
+	}
+	return personaID, partes[1], partes[2], nil
+}
+
+// URLBaja arma el enlace público de baja (APP_URL sirve /api a través del proxy del frontend)
+func URLBaja(personaID int, categoria, canal string) string {
+	base := strings.TrimRight(os.Getenv("APP_URL"), "/")
+	if base == "" {
+		base = AppURLPorDefecto
+	}
+	return base + "/api/notificaciones/baja?t=" + url.QueryEscape(TokenBaja(personaID, categoria, canal))
+}
+
+// Unsubscribe aplica un enlace de baja (desde el correo o por List-Unsubscribe-Post)
+func (s *Service) Unsubscribe(token string) error {
+	personaID, categoria, canal, err := leerTokenBaja(token)
+	if err != nil {
+		return err
+	}
+	return s.repo.SetPreferencia(personaID, categoria, canal, false)
+}
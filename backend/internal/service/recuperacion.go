@@ -0,0 +1,402 @@
+/**
+ * ARCHIVO: recuperacion.go
+ * UBICACIÓN: internal/service/recuperacion.go
+ * DESCRIPCIÓN: Recuperación de usuario/contraseña sin enumeración de cuentas.
+ * La respuesta es idéntica exista o no el miembro, el PIN solo viaja a un medio
+ * registrado (correo o teléfono, que se devuelve enmascarado; sin cuenta se devuelve un
+ * señuelo con la misma forma) y cada cuenta objetivo tiene su propio límite de solicitudes.
+ */
+
+package service
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gestion-congregacion/backend/internal/auth"
+	"gestion-congregacion/backend/internal/mensajeria"
+	"gestion-congregacion/backend/internal/plantillas"
+	"gestion-congregacion/backend/internal/repository"
+	"gestion-congregacion/backend/internal/telefono"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Política del flujo de recuperación
+const (
+	RecuperacionTTL         = 15 * time.Minute // Vigencia del PIN y del ticket
+	MaxSolicitudesPorCuenta = 3                // Solicitudes por cuenta objetivo dentro de la ventana
+	VentanaSolicitudes      = time.Hour
+	MaxIntentosPin          = 5 // Al superarlo el ticket se destruye
+)
+
+// Tipos de recuperación
+const (
+	RecuperarUsuario = "user"
+	RecuperarClave   = "pass"
+)
+
+var (
+	ErrRecuperacionInvalida  = errors.New("el código es incorrecto o expiró")
+	ErrDemasiadasSolicitudes = errors.New("SISTEMA: Demasiadas solicitudes para esta cuenta. Intente más tarde.")
+	ErrClaveDebil            = errors.New("la contraseña no cumple los requisitos de seguridad")
+	ErrClaveActualIncorrecta = errors.New("la contraseña actual no es correcta")
+)
+
+// SolicitudRecuperacion: Datos que el miembro ingresa para identificarse
+type SolicitudRecuperacion struct {
+	Tipo      string `json:"tipo"`     // "user" o "pass"
+	Username  string `json:"username"` // Obligatorio solo para "pass"
+	Metodo    string `json:"metodo"`   // "id_cong" o "phone"
+	PersonaID string `json:"persona_id"`
+	NumCong   string `json:"numero_congregacion"`
+	Telefono  string `json:"telefono"`
+}
+
+// RespuestaRecuperacion: Siempre tiene la misma forma, exista o no la cuenta
+type RespuestaRecuperacion struct {
+	RecoveryID string `json:"recovery_id"`
+	Destino    string `json:"destino"` // Correo o teléfono enmascarado (real o señuelo)
+}
+
+// directorioRecuperacion: Las búsquedas de miembros que hace el flujo de recuperación
+type directorioRecuperacion interface {
+	GetContactoByIDAndCong(personaID, numCong string) (*repository.ContactoRecuperacion, error)
+	GetContactoByPersona(personaID int) (*repository.ContactoRecuperacion, error)
+	GetPersonasByTelefono(variantes []string) ([]int, error)
+	GetUsernamesByPersona(personaID int) []string
+}
+
+var soloDigitos = regexp.MustCompile(`\D`)
+
+// identificadorObjetivo normaliza los datos que identifican a la cuenta buscada
+func (req SolicitudRecuperacion) identificadorObjetivo() string {
+	if req.Metodo == "phone" {
+		if e164, err := telefono.Normalizar(req.Telefono, telefono.PaisRecuperacion()); err == nil {
+			return "phone|" + telefono.Variantes(e164)[0]
+		}
+		return "phone|" + soloDigitos.ReplaceAllString(req.Telefono, "")
+	}
+	return "id_cong|" + strings.TrimSpace(req.PersonaID) + "|" + strings.TrimSpace(req.NumCong)
+}
+
+// StartRecovery identifica al miembro y, si existe, envía un PIN a su correo registrado
+func (s *Service) StartRecovery(req SolicitudRecuperacion) (*RespuestaRecuperacion, error) {
+	ctx := context.Background()
+	clave := claveCuenta(req.identificadorObjetivo())
+
+	// 1. LÍMITE POR CUENTA OBJETIVO (se aplica igual aunque la cuenta no exista)
+	n, err := s.rdb.Incr(ctx, "recovery_target:"+clave).Result()
+	if err == nil && n == 1 {
+		s.rdb.Expire(ctx, "recovery_target:"+clave, VentanaSolicitudes)
+	}
+	if n > MaxSolicitudesPorCuenta {
+		return nil, ErrDemasiadasSolicitudes
+	}
+
+	id, err := tokenAleatorio()
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. RESOLVER LA CUENTA (sin revelar el resultado)
+	contacto := s.resolverRecuperacion(req)
+	var canal mensajeria.Canal
+	if contacto != nil {
+		canal = s.canalRecuperacion(req, contacto)
+	}
+	if canal == "" {
+		log.Println("⚠️ RECUPERACIÓN: Datos sin coincidencia (se responde con señuelo)")
+		return &RespuestaRecuperacion{RecoveryID: id, Destino: s.destinoSenuelo(req, clave)}, nil
+	}
+
+	// 3. TICKET + PIN: Solo el hash del PIN se guarda en Redis
+	pin, err := generarPIN()
+	if err != nil {
+		return nil, err
+	}
+	key := "recovery:" + id
+	s.rdb.HSet(ctx, key, map[string]interface{}{
+		"persona_id": contacto.PersonaID,
+		"tipo":       req.Tipo,
+		"pin":        hashPIN(id, pin),
+		"intentos":   0,
+		"verificado": 0,
+	})
+	s.rdb.Expire(ctx, key, RecuperacionTTL)
+
+	go s.enviarPinRecuperacion(contacto, canal, pin)
+
+	return &RespuestaRecuperacion{RecoveryID: id, Destino: destinoEnmascarado(contacto, canal)}, nil
+}
+
+// canalRecuperacion: Quien se identificó con su teléfono recibe el código en ese número
+// si hay un canal telefónico habilitado (así la respuesta tiene forma de teléfono exista
+// o no la cuenta); si no, por su canal preferido
+func (s *Service) canalRecuperacion(req SolicitudRecuperacion, c *repository.ContactoRecuperacion) mensajeria.Canal {
+	if req.Metodo == "phone" {
+		if canal := s.canalTelefonico(c); canal != "" {
+			return canal
+		}
+	}
+	return s.canalPara(c)
+}
+
+// resolverRecuperacion busca al miembro; para "pass" el usuario indicado debe pertenecerle
+func (s *Service) resolverRecuperacion(req SolicitudRecuperacion) *repository.ContactoRecuperacion {
+	var contacto *repository.ContactoRecuperacion
+	switch req.Metodo {
+	case "id_cong":
+		contacto, _ = s.contactos.GetContactoByIDAndCong(req.PersonaID, req.NumCong)
+	case "phone":
+		if personaID, ok := s.buscarPorTelefono(req.Telefono); ok {
+			contacto, _ = s.contactos.GetContactoByPersona(personaID)
+		}
+	}
+	if contacto == nil {
+		return nil
+	}
+
+	if req.Tipo == RecuperarClave {
+		username := strings.TrimSpace(strings.ToLower(req.Username))
+		for _, alias := range s.contactos.GetUsernamesByPersona(contacto.PersonaID) {
+			if strings.ToLower(alias) == username && username != "" {
+				return contacto
+			}
+		}
+		return nil
+	}
+	return contacto
+}
+
+// buscarPorTelefono normaliza el número a E.164 y lo busca por igualdad en el índice
+// (en Argentina, con y sin el 9 de celular).
+// Un teléfono compartido (ej: fijo familiar) es ambiguo y se trata como sin coincidencia.
+func (s *Service) buscarPorTelefono(tel string) (int, bool) {
+	e164, err := telefono.Normalizar(tel, telefono.PaisRecuperacion())
+	if err != nil {
+		return 0, false
+	}
+	ids, err := s.contactos.GetPersonasByTelefono(telefono.Variantes(e164))
+	if err != nil || len(ids) != 1 {
+		return 0, false
+	}
+	return ids[0], true
+}
+
+// VerifyRecovery valida el PIN del ticket. Para "user" envía el alias al correo registrado;
+// para "pass" deja el ticket habilitado para CompleteRecovery.
+func (s *Service) VerifyRecovery(recoveryID, pin string) (string, error) {
+	ctx := context.Background()
+	key := "recovery:" + recoveryID
+
+	datos, err := s.rdb.HGetAll(ctx, key).Result()
+	if err != nil || len(datos) == 0 {
+		return "", ErrRecuperacionInvalida
+	}
+
+	intentos, _ := s.rdb.HIncrBy(ctx, key, "intentos", 1).Result()
+	if intentos > MaxIntentosPin {
+		s.rdb.Del(ctx, key)
+		return "", ErrRecuperacionInvalida
+	}
+
+	if !hmac.Equal([]byte(hashPIN(recoveryID, pin)), []byte(datos["pin"])) {
+		return "", ErrRecuperacionInvalida
+	}
+
+	personaID, _ := strconv.Atoi(datos["persona_id"])
+	if datos["tipo"] != RecuperarClave {
+		s.rdb.Del(ctx, key)
+		if contacto, err := s.repo.GetContactoByPersona(personaID); err == nil {
+			if canal := s.canalPara(contacto); canal != "" {
+				go s.enviarUsuarioRecuperado(contacto, canal)
+			}
+		}
+		return RecuperarUsuario, nil
+	}
+
+	s.rdb.HSet(ctx, key, "verificado", 1)
+	return RecuperarClave, nil
+}
+
+// CompleteRecovery guarda la nueva contraseña de un ticket ya verificado
+func (s *Service) CompleteRecovery(recoveryID, nuevaClave string) error {
+	ctx := context.Background()
+	key := "recovery:" + recoveryID
+
+	datos, err := s.rdb.HGetAll(ctx, key).Result()
+	if err != nil || datos["verificado"] != "1" || datos["tipo"] != RecuperarClave {
+		return ErrRecuperacionInvalida
+	}
+	if !claveSegura(nuevaClave) {
+		return ErrClaveDebil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(nuevaClave), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.UpdatePassword(datos["persona_id"], string(hash)); err != nil {
+		return err
+	}
+	s.rdb.Del(ctx, key)
+
+	// El titular demostró su identidad: levantamos cualquier bloqueo por intentos fallidos
+	personaID, _ := strconv.Atoi(datos["persona_id"])
+	for _, username := range s.repo.GetUsernamesByPersona(personaID) {
+		s.limpiarFallosCuenta(ctx, username)
+	}
+	return nil
+}
+
+// ChangePassword: Cambio de clave desde el perfil (requiere la clave actual).
+// Devuelve llaves nuevas para que la sesión actual sobreviva a la invalidación por password_changed_at.
+func (s *Service) ChangePassword(claims *auth.Claims, actual, nueva string) (string, string, error) {
+	hashActual, err := s.repo.GetPasswordHashByPersona(claims.PersonaID)
+	if err != nil || bcrypt.CompareHashAndPassword([]byte(hashActual), []byte(actual)) != nil {
+		return "", "", ErrClaveActualIncorrecta
+	}
+	if !claveSegura(nueva) {
+		return "", "", ErrClaveDebil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(nueva), bcrypt.DefaultCost)
+	if err != nil {
+		return "", "", err
+	}
+	if err := s.repo.UpdatePassword(strconv.Itoa(claims.PersonaID), string(hash)); err != nil {
+		return "", "", err
+	}
+
+	access, err := auth.GenerarAccessToken(claims.Identidad())
+	if err != nil {
+		return "", "", err
+	}
+	refresh, err := auth.GenerarRefreshToken(claims.Identidad())
+	return access, refresh, err
+}
+
+// claveSegura replica los requisitos del frontend (8+ caracteres, mayúscula, número y símbolo)
+func claveSegura(p string) bool {
+	return len(p) >= 8 &&
+		regexp.MustCompile(`[A-Z]`).MatchString(p) &&
+		regexp.MustCompile(`[0-9]`).MatchString(p) &&
+		regexp.MustCompile(`[^a-zA-Z0-9]`).MatchString(p)
+}
+
+// generarPIN crea un código de 6 dígitos criptográficamente seguro
+func generarPIN() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n), nil
+}
+
+// tokenAleatorio genera el identificador opaco del ticket de recuperación
+func tokenAleatorio() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashPIN vincula el PIN a su ticket para que no pueda reutilizarse en otro
+func hashPIN(recoveryID, pin string) string {
+	sum := sha256.Sum256([]byte(recoveryID + ":" + strings.TrimSpace(pin)))
+	return hex.EncodeToString(sum[:])
+}
+
+// enmascararEmail oculta el correo registrado: "j****n@dominio.com"
+func enmascararEmail(email string) string {
+	nombre, dominio, ok := strings.Cut(email, "@")
+	if !ok || nombre == "" {
+		return "***"
+	}
+	if len(nombre) <= 2 {
+		return nombre[:1] + "*@" + dominio
+	}
+	return nombre[:1] + strings.Repeat("*", len(nombre)-2) + nombre[len(nombre)-1:] + "@" + dominio
+}
+
+// destinoSenuelo imita el destino que tendría una cuenta real con los mismos datos. Sin
+// canales telefónicos habilitados, todo código va por correo. Con ellos, quien dio su
+// teléfono lo ve enmascarado (como una cuenta real, ver canalRecuperacion) y quien dio
+// su ID ve un correo o un teléfono según la clave, porque una cuenta real puede preferir
+// SMS/WhatsApp: ninguna de las dos formas prueba que la cuenta exista.
+func (s *Service) destinoSenuelo(req SolicitudRecuperacion, clave string) string {
+	if len(s.telefonos) == 0 {
+		return emailSenuelo(clave)
+	}
+	if req.Metodo == "phone" {
+		// Un número que no se normaliza nunca encuentra cuenta: cualquier forma sirve
+		if e164, err := telefono.Normalizar(req.Telefono, telefono.PaisRecuperacion()); err == nil {
+			return enmascararTelefono(e164)
+		}
+		return emailSenuelo(clave)
+	}
+	if b, _ := hex.DecodeString(clave); len(b) > 4 && b[4]%2 == 0 {
+		return telefonoSenuelo(clave)
+	}
+	return emailSenuelo(clave)
+}
+
+// telefonoSenuelo genera un teléfono enmascarado verosímil y estable del país de recuperación
+func telefonoSenuelo(clave string) string {
+	b, _ := hex.DecodeString(clave)
+	if len(b) < 8 {
+		return "***"
+	}
+	codigo := telefono.CodigoPais(telefono.PaisRecuperacion())
+	if codigo == "" {
+		codigo = telefono.CodigoPais(telefono.PaisPorDefecto)
+	}
+	numero := fmt.Sprintf("+%s9%02d%04d", codigo, int(b[5])%100, (int(b[6])<<8|int(b[7]))%10000)
+	return enmascararTelefono(numero)
+}
+
+// emailSenuelo genera un correo enmascarado verosímil y estable para datos sin coincidencia,
+// de modo que repetir la consulta no delate que la cuenta no existe
+func emailSenuelo(clave string) string {
+	dominios := []string{"gmail.com", "hotmail.com", "yahoo.com", "outlook.com"}
+	b, _ := hex.DecodeString(clave)
+	if len(b) < 4 {
+		return "***"
+	}
+	largo := 5 + int(b[0])%6
+	nombre := string(rune('a'+b[1]%26)) + strings.Repeat("x", largo-2) + string(rune('a'+b[2]%26))
+	return enmascararEmail(nombre + "@" + dominios[int(b[3])%len(dominios)])
+}
+
+// enviarPinRecuperacion envía el PIN únicamente a un medio registrado del miembro
+func (s *Service) enviarPinRecuperacion(c *repository.ContactoRecuperacion, canal mensajeria.Canal, pin string) {
+	m, err := plantillas.Render(plantillas.PIN, c.Idioma, plantillas.Datos{PIN: pin, Minutos: int(RecuperacionTTL.Minutes())})
+	if err != nil {
+		log.Println("❌ Error al armar el PIN de recuperación:", err)
+		return
+	}
+	s.entregar(c, canal, m)
+}
+
+// enviarUsuarioRecuperado envía el alias de acceso a un medio registrado
+func (s *Service) enviarUsuarioRecuperado(c *repository.ContactoRecuperacion, canal mensajeria.Canal) {
+	m, err := plantillas.Render(plantillas.UsuarioRecuperado, c.Idioma, plantillas.Datos{Nombre: c.NombreCompleto, Usuario: c.Username, PersonaID: c.PersonaID})
+	if err != nil {
+		log.Println("❌ Error al armar los datos de acceso:", err)
+		return
+	}
+	s.entregar(c, canal, m)
+}
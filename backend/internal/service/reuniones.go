@@ -0,0 +1,120 @@
+/**
+ * ARCHIVO: reuniones.go
+ * UBICACIÓN: internal/service/reuniones.go
+ * DESCRIPCIÓN: Presencia en las reuniones. Un anciano abre la reunión y los miembros
+ * conectados entran a la sala de su congregación; los ancianos ven el total en vivo
+ * y, al finalizar, los presentes quedan guardados como registro de asistencia.
+ */
+
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/ws"
+
+	"gorm.io/gorm"
+)
+
+// Plazo para leer la presencia en vivo al finalizar una reunión
+const esperaPresencia = 3 * time.Second
+
+var (
+	ErrTipoReunionInvalido = errors.New("el tipo de reunión debe ser 'entre_semana' o 'fin_de_semana'")
+	ErrReunionEnCurso      = errors.New("ya hay una reunión en curso en la congregación")
+	ErrReunionNoEncontrada = errors.New("la reunión no existe o ya finalizó")
+)
+
+// EsAnciano: Ancianos y admins locales ven la presencia y manejan las reuniones
+func (s *Service) EsAnciano(personaID int) bool {
+	return s.repo.GetAncianoCongregacion(personaID) != ""
+}
+
+// StartMeeting abre la reunión de la congregación del anciano y avisa a sus miembros
+// conectados para que entren a la sala
+func (s *Service) StartMeeting(ancianoID int, tipo string) (*models.Reunion, error) {
+	congID := s.repo.GetAncianoCongregacion(ancianoID)
+	if congID == "" {
+		return nil, ErrSinPermiso
+	}
+	if tipo != models.ReunionEntreSemana && tipo != models.ReunionFinDeSemana {
+		return nil, ErrTipoReunionInvalido
+	}
+	if _, err := s.repo.GetOpenMeeting(congID); err == nil {
+		return nil, ErrReunionEnCurso
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	m := &models.Reunion{CongregacionID: congID, Tipo: tipo, IniciadaAt: time.Now().UTC(), IniciadaPor: ancianoID}
+	if err := s.repo.CreateMeeting(m); err != nil {
+		return nil, err
+	}
+	ws.SendToCongregation(congID, ws.NuevoEvento(ws.EventoReunionIniciada, ws.Reunion{
+		ReunionID: m.ID, Tipo: m.Tipo, Sala: ws.SalaReunion(congID),
+	}))
+	return m, nil
+}
+
+// GetCurrentMeeting: Reunión en curso de la congregación del miembro, con la sala a
+// la que debe entrar. Los ancianos reciben además cuántos están presentes ahora.
+func (s *Service) GetCurrentMeeting(personaID int) (*models.PresenciaReunion, error) {
+	congID := s.repo.GetPersonaCongregacion(personaID)
+	if congID == "" {
+		return nil, ErrReunionNoEncontrada
+	}
+	m, err := s.repo.GetOpenMeeting(congID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReunionNoEncontrada
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := &models.PresenciaReunion{Reunion: *m, Sala: ws.SalaReunion(congID)}
+	if s.repo.GetAncianoCongregacion(personaID) == congID {
+		ctx, cancel := context.WithTimeout(context.Background(), esperaPresencia)
+		defer cancel()
+		n := len(ws.Presentes(ctx, congID))
+		p.Presentes = &n
+	}
+	return p, nil
+}
+
+// EndMeeting cierra la reunión y guarda la foto de los presentes como su asistencia
+func (s *Service) EndMeeting(ancianoID, reunionID int) (*models.Reunion, error) {
+	congID := s.repo.GetAncianoCongregacion(ancianoID)
+	if congID == "" {
+		return nil, ErrSinPermiso
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), esperaPresencia)
+	defer cancel()
+	m, err := s.repo.EndMeeting(reunionID, congID, ancianoID, ws.Presentes(ctx, congID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReunionNoEncontrada
+	}
+	if err != nil {
+		return nil, err
+	}
+	ws.SendToCongregation(congID, ws.NuevoEvento(ws.EventoReunionFinalizada, ws.Reunion{
+		ReunionID: m.ID, Tipo: m.Tipo, Sala: ws.SalaReunion(congID), Presentes: m.Presentes,
+	}))
+	return m, nil
+}
+
+// GetMeetingRecord: Asistencia registrada de una reunión (solo ancianos de la congregación)
+func (s *Service) GetMeetingRecord(ancianoID, reunionID int) (*models.RegistroReunion, error) {
+	congID := s.repo.GetAncianoCongregacion(ancianoID)
+	if congID == "" {
+		return nil, ErrSinPermiso
+	}
+	reg, err := s.repo.GetMeetingRecord(reunionID, congID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrReunionNoEncontrada
+	}
+	return reg, err
+}
@@ -12,62 +12,110 @@ import (
 	"strings"
 
 	"context"
-	"crypto/rand"
-	"encoding/json"
-	"fmt"
 	"log"
-	"math/big"
-	"net/http"
-	"net/url"
-	"os"
-	"regexp"
 	"time"
 
 	"gestion-congregacion/backend/internal/auth"
+	"gestion-congregacion/backend/internal/captcha"
+	"gestion-congregacion/backend/internal/correo"
+	"gestion-congregacion/backend/internal/mensajeria"
 	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/plantillas"
 	"gestion-congregacion/backend/internal/repository"
-	"gestion-congregacion/backend/internal/ws"
+	"gestion-congregacion/backend/internal/telefono"
 
 	"github.com/microcosm-cc/bluemonday"
 	"github.com/redis/go-redis/v9"
-	"github.com/resend/resend-go/v2"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// ErrTelefonoInvalido: El contacto no se pudo llevar a E.164 con el país de la congregación
+var ErrTelefonoInvalido = errors.New("el teléfono no es válido: incluya el código de área o el código de país (+)")
+
+// ErrCanalInvalido: Solo se aceptan los canales de mensajería conocidos
+var ErrCanalInvalido = errors.New("canal de notificación inválido")
+
+// ErrIdiomaInvalido: El idioma debe ser uno de los que ofrece el frontend
+var ErrIdiomaInvalido = errors.New("idioma no soportado")
+
+// ErrCampoNoEditable: El perfil solo permite editar los campos de camposPerfil
+var ErrCampoNoEditable = errors.New("ese dato del perfil no se puede editar")
+
+// ErrValorInvalido: El valor contenía un intento de inyección
+var ErrValorInvalido = errors.New("el valor contiene contenido no permitido")
+
+// camposPerfil: Columnas de core_personas que el miembro puede cambiar en su perfil
+var camposPerfil = map[string]bool{
+	"username":        true,
+	"email":           true,
+	"contacto":        true,
+	"canal_preferido": true,
+	"idioma":          true,
+}
+
+// hashSenuelo iguala el costo de bcrypt cuando el usuario no existe
+var hashSenuelo, _ = bcrypt.GenerateFromPassword([]byte("usuario-inexistente"), bcrypt.DefaultCost)
+
 type Service struct {
-	repo *repository.Repository
-	rdb  *redis.Client
+	repo      *repository.Repository
+	rdb       *redis.Client
+	captcha   captcha.Verifier
+	telefonos map[mensajeria.Canal]mensajeria.Notifier
+	correo    correo.EmailSender
+	outbox    bandejaCorreos         // El repositorio; las pruebas del worker lo reemplazan
+	contactos directorioRecuperacion // El repositorio; las pruebas de recuperación lo reemplazan
 }
 
-func NewService(repo *repository.Repository, rdb *redis.Client) *Service {
-	return &Service{repo: repo, rdb: rdb}
+// NewService recibe el verificador de CAPTCHA inyectado (Turnstile, hCaptcha o Fake en pruebas)
+// y los canales telefónicos habilitados (SMS/WhatsApp; un mapa vacío deja solo el email).
+// El EmailSender solo lo usa el worker del outbox: el resto del servicio encola correos.
+func NewService(repo *repository.Repository, rdb *redis.Client, captchaVerifier captcha.Verifier, telefonos map[mensajeria.Canal]mensajeria.Notifier, emailSender correo.EmailSender) *Service {
+	return &Service{repo: repo, rdb: rdb, captcha: captchaVerifier, telefonos: telefonos, correo: emailSender, outbox: repo, contactos: repo}
 }
 
 // --- LÓGICA DE IDENTIDAD ---
 
+// exigirCaptcha: Con 3 o más logins fallidos desde la IP, el intento necesita un
+// token que acepte el verificador inyectado
+func (s *Service) exigirCaptcha(ctx context.Context, captchaToken, ip string) error {
+	failedAttempts, _ := s.rdb.Get(ctx, "failed_login:"+ip).Int()
+	if failedAttempts < 3 {
+		return nil
+	}
+	if captchaToken == "" {
+		log.Printf("⚠️ LOGIN RECHAZADO: Falta Captcha para IP %s", ip)
+		return errors.New("SISTEMA: Comportamiento sospechoso. Resuelva el CAPTCHA.")
+	}
+	if err := s.captcha.Verify(ctx, captchaToken, ip); err != nil {
+		log.Printf("⚠️ LOGIN RECHAZADO: Captcha inválido para IP %s (%v)", ip, err)
+		return errors.New("SISTEMA: CAPTCHA no válido o expirado.")
+	}
+	return nil
+}
+
 // Authenticate: Lógica de Login Blindada con Sistema de Doble Llave (Refresh Tokens)
-func (s *Service) Authenticate(username, password, captchaToken, ip string) (*models.Usuario, string, string, error) {
+func (s *Service) Authenticate(username, password, captchaToken, ip, userAgent string) (*models.Usuario, string, string, error) {
 	username = strings.TrimSpace(strings.ToLower(username))
 	ctx := context.Background()
 
 	// 1. LÓGICA DE CAPTCHA DINÁMICO
-	failedAttempts, _ := s.rdb.Get(ctx, "failed_login:"+ip).Int()
+	if err := s.exigirCaptcha(ctx, captchaToken, ip); err != nil {
+		return nil, "", "", err
+	}
 
-	if failedAttempts >= 3 {
-		if captchaToken == "" {
-			log.Printf("⚠️ LOGIN RECHAZADO: Falta Captcha para IP %s", ip)
-			return nil, "", "", errors.New("SISTEMA: Comportamiento sospechoso. Resuelva el CAPTCHA.")
-		}
-		if !s.ValidarTurnstile(captchaToken) {
-			log.Printf("⚠️ LOGIN RECHAZADO: Captcha inválido para IP %s", ip)
-			return nil, "", "", errors.New("SISTEMA: CAPTCHA no válido o expirado.")
-		}
+	// 1B. BLOQUEO POR CUENTA: Frena la adivinanza distribuida (muchas IPs contra un usuario)
+	if espera := s.esperaPendiente(ctx, username); espera > 0 {
+		log.Printf("⚠️ LOGIN RECHAZADO: Cuenta en espera por intentos fallidos (IP %s)", ip)
+		return nil, "", "", &ErrEspera{Espera: espera}
 	}
 
 	// 2. BUSCAR USUARIO
 	u, err := s.repo.GetUserForLogin(username)
 	if err != nil {
+		// Comparación señuelo: el tiempo de respuesta no revela si el usuario existe
+		bcrypt.CompareHashAndPassword(hashSenuelo, []byte(password))
 		log.Printf("⚠️ LOGIN RECHAZADO: Usuario '%s' no encontrado", username)
+		s.registrarFalloCuenta(ctx, username, nil, ip)
 		return nil, "", "", errors.New("el usuario o la contraseña no son correctos")
 	}
 
@@ -79,19 +127,41 @@ func (s *Service) Authenticate(username, password, captchaToken, ip string) (*mo
 		// Incrementamos fallos en Redis para disparar el CAPTCHA en el próximo intento
 		s.rdb.Incr(ctx, "failed_login:"+ip)
 		s.rdb.Expire(ctx, "failed_login:"+ip, 30*time.Minute)
+		s.registrarFalloCuenta(ctx, username, u, ip)
 		return nil, "", "", errors.New("el usuario o la contraseña no son correctos")
 	}
 
-	// SI EL LOGIN ES EXITOSO: Reseteamos los fallos de esta IP
+	// SI EL LOGIN ES EXITOSO: Reseteamos los fallos de esta IP y de esta cuenta
 	s.rdb.Del(ctx, "failed_login:"+ip)
+	s.limpiarFallosCuenta(ctx, username)
+
+	// 3B. ESTADO DE LA CUENTA: Solo se revela tras validar la contraseña,
+	// así un tercero no puede usarlo para averiguar qué cuentas existen.
+	if u.Estado == "BAJA" || u.EstadoCuenta == "suspendida" {
+		log.Printf("⚠️ LOGIN RECHAZADO: Cuenta inactiva para usuario '%s'", username)
+		return nil, "", "", ErrCuentaSuspendida
+	}
 
-	// 4. GENERACIÓN DE LLAVES (Tokens)
-	accessToken, err := auth.GenerarAccessToken(u.ID)
+	// 4. REGISTRO DEL DISPOSITIVO (core_sesiones)
+	sesion, err := s.createSession(u, ip, userAgent)
+	if err != nil {
+		log.Println("❌ Error al registrar sesión:", err)
+		return nil, "", "", errors.New("error al iniciar sesión")
+	}
+
+	// 5. GENERACIÓN DE LLAVES (Tokens)
+	identidad := auth.Identidad{
+		UsuarioID:      u.ID,
+		SesionID:       sesion.ID,
+		PersonaID:      u.PersonaID,
+		CongregacionID: u.CongregacionID,
+	}
+	accessToken, err := auth.GenerarAccessToken(identidad)
 	if err != nil {
 		return nil, "", "", errors.New("error al generar llave de acceso")
 	}
 
-	refreshToken, err := auth.GenerarRefreshToken(u.ID)
+	refreshToken, err := auth.GenerarRefreshToken(identidad)
 	if err != nil {
 		return nil, "", "", errors.New("error al generar llave de refresco")
 	}
@@ -99,89 +169,36 @@ func (s *Service) Authenticate(username, password, captchaToken, ip string) (*mo
 	return u, accessToken, refreshToken, nil
 }
 
-func (s *Service) RecoverAccount(pID, num, tel, met string) (string, error) {
-	if met == "id_cong" {
-		return s.repo.FindEmailByIDAndCong(pID, num)
+// --- SEGURIDAD DIGITAL ---
+
+// ProcessPinRequest: PIN de verificación (MFA del perfil) enviado por el canal preferido del miembro
+func (s *Service) ProcessPinRequest(personaID int) error {
+	contacto, err := s.repo.GetContactoByPersona(personaID)
+	if err != nil {
+		return ErrSinCanal
 	}
-	contactos, _ := s.repo.GetAllContactsForRecovery()
-	for _, v := range contactos {
-		re := regexp.MustCompile(`\D`)
-		nums := re.ReplaceAllString(v.Contacto, "")
-		if len(nums) >= 8 && nums[len(nums)-8:] == tel {
-			return v.Email, nil
-		}
+	canal := s.canalPara(contacto)
+	if canal == "" {
+		return ErrSinCanal
 	}
-	return "", errors.New("no encontrado")
-}
-
-func (s *Service) SendAccessData(email string) error {
-	u, err := s.repo.GetUserByEmail(email)
+	pin, err := generarPIN()
+	if err != nil {
+		return err
+	}
+	m, err := plantillas.Render(plantillas.PIN, contacto.Idioma, plantillas.Datos{PIN: pin})
 	if err != nil {
 		return err
 	}
-	go func() {
-		client := resend.NewClient(os.Getenv("RESEND_API_KEY"))
-		html := fmt.Sprintf("<h2>Acceso:</h2><p>Usuario: %s</p><p>ID: %d</p>", u.Username, u.PersonaID)
-		params := &resend.SendEmailRequest{From: "Seguridad <onboarding@resend.dev>", To: []string{email}, Subject: "Datos de Acceso", Html: html}
-		client.Emails.Send(params)
-	}()
-	return nil
-}
-
-// --- SEGURIDAD DIGITAL ---
 
-func (s *Service) ProcessPinRequest(email, user, cong string) error {
-	pinNum, _ := rand.Int(rand.Reader, big.NewInt(1000000))
-	pin := fmt.Sprintf("%06d", pinNum)
 	s.repo.InvalidateOldPines()
-	s.repo.SavePin(pin)
-	go func() {
-		client := resend.NewClient(os.Getenv("RESEND_API_KEY"))
-		html := fmt.Sprintf("<h1>Código de Verificación</h1><p>Su código es: <b>%s</b></p>", pin)
-		params := &resend.SendEmailRequest{From: "Seguridad <onboarding@resend.dev>", To: []string{email}, Subject: "Código: " + pin, Html: html}
-		client.Emails.Send(params)
-	}()
-	return nil
-}
-
-// BroadcastSecurity: Guarda boletín, avisa por WebSocket y envía Emails masivos
-func (s *Service) BroadcastSecurity(titulo, desc string) error {
-	// 1. Persistencia en base de datos
-	if err := s.repo.SaveSecurityLog(titulo, desc); err != nil {
+	if canal == mensajeria.CanalEmail {
+		// El PIN y su correo se confirman juntos en la misma transacción
+		return s.repo.SavePin(pin, nuevoCorreo(contacto.Email, m.Asunto, m.HTML, m.Texto, true))
+	}
+	if err := s.repo.SavePin(pin); err != nil {
 		return err
 	}
-
-	// 2. Notificación Push en tiempo real vía WebSocket
-	ws.Broadcast(map[string]string{
-		"tipo":   "ALERTA_SEGURIDAD",
-		"titulo": titulo,
-		"msg":    "Se ha publicado una nueva actualización de seguridad.",
-	})
-
-	// 3. Notificación por Email Masivo (Asíncrona)
-	go func() {
-		lista, err := s.repo.GetActiveMembersForBroadcast()
-		if err != nil {
-			log.Println("❌ Error al obtener destinatarios:", err)
-			return
-		}
-
-		client := resend.NewClient(os.Getenv("RESEND_API_KEY"))
-		for _, u := range lista {
-			// Usamos tu template profesional de aviso de seguridad
-			html := fmt.Sprintf(`<h3>Aviso de Seguridad</h3><p>Hola <b>%s</b>,</p><p>%s</p>`, u.NombreCompleto, desc)
-
-			params := &resend.SendEmailRequest{
-				From:    "Seguridad Local <onboarding@resend.dev>",
-				To:      []string{u.Email},
-				Subject: "⚠️ " + titulo,
-				Html:    html,
-			}
-			client.Emails.Send(params)
-			time.Sleep(150 * time.Millisecond) // Blindaje anti-spam
-		}
-	}()
-
+	go s.entregar(contacto, canal, m)
 	return nil
 }
 
@@ -190,108 +207,66 @@ func (s *Service) UpdateUserFoto(personaID, url string) error {
 	return s.repo.UpdateFoto(personaID, fixedURL)
 }
 
-func (s *Service) AddSecurityInfo(cont string) error { return s.repo.SaveSecurityLog(cont, "") }
-
-// GetSecurityBulletin: Trae la info más reciente
-func (s *Service) GetSecurityBulletin() (map[string]interface{}, error) {
-	return s.repo.GetLatestSecurityInfo()
-}
-
 func (s *Service) GetCatalog() ([]models.Publicacion, error) { return s.repo.GetPublicaciones() }
 
-func (s *Service) UpdateProfile(pID, campo, valor string) error {
+// UpdateProfile: El miembro cambia un dato de su propio perfil (solo campos de camposPerfil)
+func (s *Service) UpdateProfile(personaID int, campo, valor string) error {
+	if !camposPerfil[campo] {
+		return ErrCampoNoEditable
+	}
+
 	// SANITIZACIÓN INDUSTRIAL: No confiamos en lo que envíe el cliente
 	p := bluemonday.StrictPolicy()
 	cleanValue := p.Sanitize(valor)
 
 	// Bloqueo preventivo de inyección de etiquetas comunes en campos de texto
 	if strings.Contains(cleanValue, "javascript:") {
-		return errors.New("intento de inyección detectado")
+		return ErrValorInvalido
 	}
 
-	return s.repo.UpdateProfileField(pID, campo, cleanValue)
-}
-
-func (s *Service) SuspendUser(pID, uID string) error { return s.repo.SuspendAccount(pID, uID) }
-
-// VerifyPin: Valida y consume el PIN (lo marca como usado)
-func (s *Service) VerifyPin(pin string) error {
-	if pin == "" {
-		return errors.New("el PIN es obligatorio")
+	if campo == "canal_preferido" && !mensajeria.Canal(cleanValue).Valido() {
+		return ErrCanalInvalido
 	}
-	return s.repo.ConsumePin(pin)
-}
-
-func (s *Service) IdentifyUser(user string) error {
-	if s.repo.UserExists(user) {
-		return nil
+	if campo == "idioma" && !plantillas.Valido(cleanValue) {
+		return ErrIdiomaInvalido
 	}
-	return errors.New("no encontrado")
-}
 
-/**
- * NUEVA FUNCIÓN EN EL SERVICE
- * ValidarTurnstile verifica el token con los servidores de Cloudflare.
- */
-/**
- * ValidarTurnstile: Verifica el token con Cloudflare.
- * Incluye logs detallados para depuración en tiempo real.
- */
-func (s *Service) ValidarTurnstile(token string) bool {
-	secretKey := os.Getenv("TURNSTILE_SECRET_KEY")
-
-	if secretKey == "" {
-		log.Println("⚠️  ALERTA SEGURIDAD: TURNSTILE_SECRET_KEY no encontrada en .env")
-		return false
-	}
-
-	// Usamos url.Values para codificar correctamente los parámetros
-	data := url.Values{}
-	data.Set("secret", secretKey)
-	data.Set("response", token)
-
-	resp, err := http.PostForm("https://challenges.cloudflare.com/turnstile/v0/siteverify", data)
-	if err != nil {
-		log.Println("❌ ERROR de red al validar con Cloudflare:", err)
-		return false
+	// El teléfono se guarda también normalizado (E.164) con el país de la congregación
+	if campo == "contacto" {
+		pais := s.repo.GetCodigoPaisPersona(personaID)
+		if pais == "" {
+			pais = telefono.PaisRecuperacion()
+		}
+		e164, err := telefono.Normalizar(cleanValue, pais)
+		if err != nil && cleanValue != "" {
+			return ErrTelefonoInvalido
+		}
+		return s.repo.UpdateContacto(personaID, cleanValue, e164)
 	}
-	defer resp.Body.Close()
 
-	var result struct {
-		Success    bool     `json:"success"`
-		ErrorCodes []string `json:"error-codes"`
-		Hostname   string   `json:"hostname"`
-	}
+	return s.repo.UpdateProfileField(personaID, campo, cleanValue)
+}
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		log.Println("❌ ERROR al leer respuesta de Cloudflare:", err)
-		return false
+// SuspendUser: Baja de la cuenta, registrada en la auditoría. Cada miembro puede darse
+// de baja a sí mismo; suspender a otro exige ser admin local de su congregación.
+func (s *Service) SuspendUser(actorPersonaID, personaID int) error {
+	if personaID != actorPersonaID {
+		if err := s.authorizeAdminOver(actorPersonaID, personaID); err != nil {
+			return err
+		}
 	}
+	return s.repo.SuspendAccount(personaID, &models.Auditoria{
+		ActorPersonaID: actorPersonaID,
+		Accion:         models.AccionSuspension,
+		PersonaID:      personaID,
+		CongregacionID: s.repo.GetPersonaCongregacion(personaID),
+	}, s.avisoCuenta(personaID, plantillas.CuentaSuspendida))
+}
 
-	if !result.Success {
-		log.Printf("🚫 Captcha RECHAZADO. Errores: %v | Hostname: %s", result.ErrorCodes, result.Hostname)
-		return false
+// VerifyPin: Valida y consume el PIN (lo marca como usado)
+func (s *Service) VerifyPin(pin string) error {
+	if pin == "" {
+		return errors.New("el PIN es obligatorio")
 	}
-
-	log.Println("✅ Captcha VALIDADO correctamente por Cloudflare")
-	return true
+	return s.repo.ConsumePin(pin)
 }
-
-func (s *Service) UpdatePasswordByUsername(username, newPassword string) error {
-    // 1. Cifrar la nueva contraseña
-    hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
-    if err != nil {
-        return err
-    }
-
-    // 2. Buscar al usuario para obtener su PersonaID
-    u, err := s.repo.GetUserForLogin(username)
-    if err != nil {
-        return fmt.Errorf("el usuario %s no existe", username)
-    }
-
-    // 3. Actualizar usando el PersonaID (que es un int en el modelo)
-    // service.go tenga import "fmt"
-    personaIDStr := fmt.Sprintf("%d", u.PersonaID)
-    return s.repo.UpdatePassword(personaIDStr, string(hashedPassword))
-}
\ No newline at end of file
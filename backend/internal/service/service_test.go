@@ -7,8 +7,26 @@
 package service
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"gestion-congregacion/backend/internal/captcha"
+	"gestion-congregacion/backend/internal/correo"
+	"gestion-congregacion/backend/internal/mensajeria"
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
 )
 
 func TestSanitizeInput(t *testing.T) {
@@ -21,4 +39,702 @@ func TestSanitizeInput(t *testing.T) {
 	if result != expected {
 		t.Errorf("FALLO EN LÓGICA: Sanitización incorrecta. Se obtuvo: %s", result)
 	}
-}
\ No newline at end of file
+}
+
+func TestNewSesionIDFormat(t *testing.T) {
+	// El ID de sesión debe ser un UUID v4 válido para la columna core_sesiones.id
+	id, err := newSesionID()
+	if err != nil {
+		t.Fatalf("FALLO: No se pudo generar el ID de sesión: %v", err)
+	}
+
+	uuidV4 := regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`)
+	if !uuidV4.MatchString(id) {
+		t.Errorf("FALLO DE FORMATO: El ID de sesión no es un UUID v4: %s", id)
+	}
+}
+
+func TestEmitidaAntesDeCambioDeSeguridad(t *testing.T) {
+	// Objetivo: Una llave emitida antes de un cambio de clave/suspensión debe ser rechazada
+	cambio := time.Date(2026, 7, 1, 12, 0, 0, 500000000, time.UTC)
+
+	if !emitidaAntesDe(cambio.Add(-time.Minute), nil, &cambio) {
+		t.Errorf("FALLO DE SEGURIDAD: Se aceptó una llave emitida antes del cambio de clave")
+	}
+	// El 'iat' se trunca a segundos: una llave emitida en el mismo segundo sigue siendo válida
+	if emitidaAntesDe(cambio.Truncate(time.Second), &cambio) {
+		t.Errorf("FALLO DE CONTRATO: Se rechazó una llave emitida en el mismo segundo del cambio")
+	}
+	if emitidaAntesDe(cambio.Add(time.Minute), nil, nil) {
+		t.Errorf("FALLO DE CONTRATO: Sin eventos de seguridad ninguna llave debe rechazarse")
+	}
+}
+
+func TestRetrasoProgresivoPorCuenta(t *testing.T) {
+	// Objetivo: Los primeros fallos no penalizan; luego la espera crece hasta el bloqueo
+	casos := map[int]time.Duration{
+		1:                        0,
+		FallosAntesDeRetraso:     time.Second,
+		FallosAntesDeRetraso + 2: 4 * time.Second,
+		FallosParaBloqueo:        DuracionBloqueo,
+		FallosParaBloqueo + 5:    DuracionBloqueo,
+	}
+	for fallos, esperado := range casos {
+		if got := retrasoPorFallos(fallos); got != esperado {
+			t.Errorf("FALLO DE POLÍTICA: %d fallos deberían esperar %v, se obtuvo %v", fallos, esperado, got)
+		}
+	}
+}
+
+func TestClaveCuentaNoExponeUsuario(t *testing.T) {
+	// La clave en Redis no debe contener el usuario y debe ignorar mayúsculas/espacios
+	t.Setenv("JWT_SECRET", "secreto-de-prueba")
+	clave := claveCuenta("  Elias.Garcia ")
+	if strings.Contains(clave, "elias") || clave != claveCuenta("elias.garcia") {
+		t.Errorf("FALLO DE PRIVACIDAD: Clave de bloqueo inesperada: %s", clave)
+	}
+
+	// Con LIMITES_SECRET, rotar JWT_SECRET no reinicia bloqueos ni límites
+	t.Setenv("LIMITES_SECRET", "secreto-de-limites")
+	antes := claveCuenta("elias.garcia")
+	t.Setenv("JWT_SECRET", "secreto-rotado")
+	if claveCuenta("elias.garcia") != antes {
+		t.Error("FALLO DE SEGURIDAD: Rotar JWT_SECRET cambió la clave de bloqueo de la cuenta")
+	}
+}
+
+func TestEnmascararEmail(t *testing.T) {
+	// Objetivo: El correo registrado nunca se devuelve completo
+	casos := map[string]string{
+		"juan@mail.com": "j**n@mail.com",
+		"al@mail.com":   "a*@mail.com",
+		"sin-arroba":    "***",
+	}
+	for email, esperado := range casos {
+		if got := enmascararEmail(email); got != esperado {
+			t.Errorf("FALLO DE PRIVACIDAD: %s se enmascaró como %s (esperado %s)", email, got, esperado)
+		}
+	}
+}
+
+func TestEmailSenueloEstable(t *testing.T) {
+	// Repetir la consulta con los mismos datos debe mostrar el mismo destino falso
+	t.Setenv("JWT_SECRET", "secreto-de-prueba")
+	clave := claveCuenta("id_cong|999|1234")
+	senuelo := emailSenuelo(clave)
+	if senuelo != emailSenuelo(clave) || !strings.Contains(senuelo, "**") {
+		t.Errorf("FALLO DE ENUMERACIÓN: Señuelo inestable o sin enmascarar: %s", senuelo)
+	}
+}
+
+func TestClaveSegura(t *testing.T) {
+	if claveSegura("corta1!") || claveSegura("sinmayuscula1!") || !claveSegura("Segura#2026") {
+		t.Errorf("FALLO DE POLÍTICA: Requisitos de contraseña no aplicados")
+	}
+}
+
+func TestCanalPreferidoConRespaldoEmail(t *testing.T) {
+	// Objetivo: El canal telefónico solo se usa si está habilitado y hay teléfono válido
+	s := &Service{telefonos: map[mensajeria.Canal]mensajeria.Notifier{
+		mensajeria.CanalSMS: mensajeria.NewFake(mensajeria.CanalSMS),
+	}}
+	casos := []struct {
+		contacto repository.ContactoRecuperacion
+		esperado mensajeria.Canal
+	}{
+		{repository.ContactoRecuperacion{Canal: "sms", Telefono: "+5491141234567", Email: "a@b.com"}, mensajeria.CanalSMS},
+		{repository.ContactoRecuperacion{Canal: "sms", Email: "a@b.com"}, mensajeria.CanalEmail},
+		{repository.ContactoRecuperacion{Canal: "whatsapp", Telefono: "+5491141234567", Email: "a@b.com"}, mensajeria.CanalEmail},
+		{repository.ContactoRecuperacion{Canal: "email"}, ""},
+	}
+	for _, c := range casos {
+		if got := s.canalPara(&c.contacto); got != c.esperado {
+			t.Errorf("canalPara(%+v) = %q; se esperaba %q", c.contacto, got, c.esperado)
+		}
+	}
+}
+
+func TestEntregarPorSMS(t *testing.T) {
+	sms := mensajeria.NewFake(mensajeria.CanalSMS)
+	s := &Service{telefonos: map[mensajeria.Canal]mensajeria.Notifier{mensajeria.CanalSMS: sms}}
+	c := &repository.ContactoRecuperacion{PersonaID: 7, Canal: "sms", Telefono: "+5491141234567"}
+
+	s.enviarPinRecuperacion(c, s.canalPara(c), "123456")
+
+	enviados := sms.Enviados()
+	if len(enviados) != 1 || enviados[0].Telefono != c.Telefono || !strings.Contains(enviados[0].Texto, "123456") {
+		t.Errorf("El PIN debía enviarse por SMS al teléfono registrado: %+v", enviados)
+	}
+	if got := destinoEnmascarado(c, mensajeria.CanalSMS); got != "+54*******4567" {
+		t.Errorf("FALLO DE PRIVACIDAD: Teléfono enmascarado como %s", got)
+	}
+}
+
+func TestEsperaReintentoExponencial(t *testing.T) {
+	casos := map[int]time.Duration{
+		1:  EsperaBaseEmail,
+		2:  2 * EsperaBaseEmail,
+		4:  8 * EsperaBaseEmail,
+		20: EsperaMaximaEmail,
+	}
+	for intentos, esperado := range casos {
+		if got := esperaReintento(intentos); got != esperado {
+			t.Errorf("FALLO DE POLÍTICA: intento %d debería esperar %v, se obtuvo %v", intentos, esperado, got)
+		}
+	}
+}
+
+func TestDeadLetterDelOutbox(t *testing.T) {
+	temporal := errors.New("timeout")
+	permanente := fmt.Errorf("%w: 550 buzón inexistente", correo.ErrPermanente)
+
+	if aDeadLetter(0, temporal) {
+		t.Errorf("Un fallo temporal en el primer intento debe reintentarse")
+	}
+	if !aDeadLetter(0, permanente) {
+		t.Errorf("Un fallo permanente debe ir directo a dead-letter")
+	}
+	if !aDeadLetter(MaxIntentosEmail-1, temporal) {
+		t.Errorf("Al agotar los intentos el correo debe ir a dead-letter")
+	}
+}
+
+func TestCupoDifusionRespetaLaTasa(t *testing.T) {
+	casos := []struct {
+		usados   int64
+		esperado int
+	}{
+		{usados: 50, esperado: 50}, // ventana vacía: se concede el lote entero
+		{usados: 80, esperado: 30}, // ya había 30 usados: quedaban 30 de 60
+		{usados: 130, esperado: 0}, // ventana agotada
+		{usados: 60, esperado: 50}, // justo en el límite
+	}
+	for _, c := range casos {
+		if got := cupoDisponible(60, 50, c.usados); got != c.esperado {
+			t.Errorf("FALLO DE TASA: con %d usados se esperaban %d envíos, se obtuvo %d", c.usados, c.esperado, got)
+		}
+	}
+}
+
+func TestTasaDifusionConfigurable(t *testing.T) {
+	t.Setenv("DIFUSION_CORREOS_POR_MINUTO", "200")
+	if got := TasaDifusion(); got != 200 {
+		t.Errorf("Se esperaba la tasa configurada (200), se obtuvo %d", got)
+	}
+	t.Setenv("DIFUSION_CORREOS_POR_MINUTO", "cero")
+	if got := TasaDifusion(); got != TasaDifusionPorDefecto {
+		t.Errorf("Un valor inválido debe usar la tasa por defecto, se obtuvo %d", got)
+	}
+}
+
+// bandejaFalsa: Outbox en memoria que respeta los límites de ClaimPendingEmails
+// (hasta 'lote' transaccionales más hasta 'cupo' de difusión)
+type bandejaFalsa struct {
+	mu                       sync.Mutex
+	transaccionales, masivos []models.EmailOutbox
+	enviados                 int
+}
+
+func (b *bandejaFalsa) EnqueueEmail(emails ...*models.EmailOutbox) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, e := range emails {
+		if e != nil {
+			b.transaccionales = append(b.transaccionales, *e)
+		}
+	}
+	return nil
+}
+
+func (b *bandejaFalsa) ClaimPendingEmails(lote, cupo int, _ time.Duration) ([]models.EmailOutbox, error) {
+	nt, nm := min(lote, len(b.transaccionales)), min(cupo, len(b.masivos))
+	lista := append(append([]models.EmailOutbox{}, b.transaccionales[:nt]...), b.masivos[:nm]...)
+	b.transaccionales, b.masivos = b.transaccionales[nt:], b.masivos[nm:]
+	return lista, nil
+}
+func (b *bandejaFalsa) MarkEmailSent(*models.EmailOutbox) error                     { b.enviados++; return nil }
+func (b *bandejaFalsa) MarkEmailRetry(*models.EmailOutbox, time.Time, string) error { return nil }
+func (b *bandejaFalsa) MarkEmailDead(*models.EmailOutbox, string) error             { return nil }
+func (b *bandejaFalsa) CompleteBroadcasts() error                                   { return nil }
+
+func correosPendientes(n int, difusion *int64) []models.EmailOutbox {
+	lista := make([]models.EmailOutbox, n)
+	for i := range lista {
+		lista[i] = models.EmailOutbox{ID: int64(i + 1), Destinatario: fmt.Sprintf("m%d@example.org", i), DifusionID: difusion}
+	}
+	return lista
+}
+
+func TestOutboxSeVaciaConMasivosEnElLote(t *testing.T) {
+	// Un lote lleno trae más de LoteOutbox correos (transaccionales + masivos): el
+	// worker no debe esperar al próximo tick con transaccionales vencidos
+	difusion := int64(1)
+	for _, transaccionales := range []int{LoteOutbox, 2*LoteOutbox + 20} {
+		b := &bandejaFalsa{transaccionales: correosPendientes(transaccionales, nil), masivos: correosPendientes(5, &difusion)}
+		s := &Service{rdb: redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}), correo: correo.NewFake(), outbox: b}
+
+		s.vaciarOutbox(context.Background())
+		if len(b.transaccionales) != 0 || len(b.masivos) != 0 || b.enviados != transaccionales+5 {
+			t.Errorf("%d transaccionales + 5 masivos: quedaron %d y %d sin tomar (%d enviados)",
+				transaccionales, len(b.transaccionales), len(b.masivos), b.enviados)
+		}
+	}
+
+	// Con la tasa de difusión agotada, el vaciado termina y el resto espera la ventana
+	b := &bandejaFalsa{masivos: correosPendientes(3*TasaDifusionPorDefecto, &difusion)}
+	s := &Service{rdb: redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()}), correo: correo.NewFake(), outbox: b}
+	s.vaciarOutbox(context.Background())
+	if len(b.masivos) == 0 {
+		t.Errorf("La difusión no debía superar su tasa: se enviaron %d", b.enviados)
+	}
+}
+
+func TestTokenBajaFirmado(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secreto-de-prueba")
+
+	token := TokenBaja(42, models.CategoriaSeguridad, models.CanalNotifEmail)
+	personaID, categoria, canal, err := leerTokenBaja(token)
+	if err != nil || personaID != 42 || categoria != models.CategoriaSeguridad || canal != models.CanalNotifEmail {
+		t.Fatalf("El enlace válido debía leerse: %d %s %s %v", personaID, categoria, canal, err)
+	}
+
+	// Cambiar la persona o la categoría invalida la firma
+	alterados := []string{
+		strings.Replace(token, "42.", "43.", 1),
+		strings.Replace(token, models.CategoriaSeguridad, models.CategoriaAnuncios, 1),
+		token + "x",
+		"42.seguridad.email",
+	}
+	for _, a := range alterados {
+		if _, _, _, err := leerTokenBaja(a); !errors.Is(err, ErrBajaInvalida) {
+			t.Errorf("FALLO DE SEGURIDAD: Se aceptó un enlace alterado: %s", a)
+		}
+	}
+
+	// Rotar JWT_SECRET no invalida los enlaces ya enviados
+	t.Setenv("JWT_KEY_ID", "v2")
+	t.Setenv("JWT_SECRET", "secreto-nuevo")
+	t.Setenv("JWT_PREVIOUS_KEYS", "v1:secreto-de-prueba")
+	if _, _, _, err := leerTokenBaja(token); err != nil {
+		t.Errorf("El enlace firmado con la llave anterior debía seguir valiendo: %v", err)
+	}
+
+	// Con UNSUBSCRIBE_SECRET, los enlaces nuevos no dependen de las llaves JWT
+	t.Setenv("UNSUBSCRIBE_SECRET", "secreto-de-bajas")
+	propio := TokenBaja(42, models.CategoriaSeguridad, models.CanalNotifEmail)
+	t.Setenv("JWT_SECRET", "otro-secreto")
+	t.Setenv("JWT_PREVIOUS_KEYS", "")
+	if _, _, _, err := leerTokenBaja(propio); err != nil {
+		t.Errorf("El enlace firmado con UNSUBSCRIBE_SECRET debía valer tras rotar JWT: %v", err)
+	}
+	if _, _, _, err := leerTokenBaja(token); !errors.Is(err, ErrBajaInvalida) {
+		t.Error("Un enlace firmado con una llave retirada no debía aceptarse")
+	}
+
+	t.Setenv("APP_URL", "https://example.org/")
+	if u := URLBaja(42, models.CategoriaSeguridad, models.CanalNotifEmail); !strings.HasPrefix(u, "https://example.org/api/notificaciones/baja?t=42.seguridad.email.") {
+		t.Errorf("URL de baja inesperada: %s", u)
+	}
+}
+
+func TestPreferenciaValida(t *testing.T) {
+	if !preferenciaValida(models.CategoriaLiteratura, models.CanalNotifPush) {
+		t.Errorf("literatura/push es una preferencia válida")
+	}
+	if preferenciaValida("pin", models.CanalNotifEmail) || preferenciaValida(models.CategoriaAnuncios, "fax") {
+		t.Errorf("Solo se aceptan categorías y canales conocidos")
+	}
+}
+
+func TestValidarBoletin(t *testing.T) {
+	titulo, desc, err := validarBoletin("  Estafa por WhatsApp ", " Detalle\n")
+	if err != nil || titulo != "Estafa por WhatsApp" || desc != "Detalle" {
+		t.Fatalf("Se esperaba el boletín limpio, se obtuvo %q %q %v", titulo, desc, err)
+	}
+	if _, _, err := validarBoletin("   ", "sin título"); !errors.Is(err, ErrBoletinInvalido) {
+		t.Errorf("Un boletín sin título debía rechazarse")
+	}
+	if _, _, err := validarBoletin(strings.Repeat("á", MaxLongitudTitulo+1), ""); !errors.Is(err, ErrBoletinInvalido) {
+		t.Errorf("Un título de más de %d caracteres debía rechazarse", MaxLongitudTitulo)
+	}
+}
+
+func TestTotalizarLecturas(t *testing.T) {
+	uno := 1
+	grupos := []models.LecturaGrupo{
+		{Grupo: &uno, Miembros: 3, Leidos: 2, Confirmados: 1},
+		{Grupo: nil, Miembros: 1, Leidos: 1, Confirmados: 1},
+	}
+	total := totalizarLecturas(grupos)
+	if total.Miembros != 4 || total.PorcentajeLeido != 75 || total.PorcentajeConfirmado != 50 {
+		t.Errorf("Total de la congregación inesperado: %+v", total)
+	}
+	if grupos[0].PorcentajeLeido != 66.7 || grupos[0].PorcentajeConfirmado != 33.3 {
+		t.Errorf("Porcentajes del grupo 1 inesperados: %+v", grupos[0])
+	}
+	if porcentaje(0, 0) != 0 {
+		t.Errorf("Un grupo sin miembros debía quedar en 0%%")
+	}
+}
+
+func TestValidarAsistencia(t *testing.T) {
+	hoy := time.Date(2026, 10, 19, 22, 0, 0, 0, time.UTC)
+	f, err := validarAsistencia("2026-10-15", models.ReunionEntreSemana, 80, 12, hoy)
+	if err != nil || !f.Equal(time.Date(2026, 10, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("Se esperaba una asistencia válida, se obtuvo %v %v", f, err)
+	}
+	// Mañana se acepta (otra zona horaria); más adelante no
+	if _, err := validarAsistencia("2026-10-20", models.ReunionFinDeSemana, 0, 0, hoy); err != nil {
+		t.Errorf("La fecha de mañana debía aceptarse: %v", err)
+	}
+	for _, caso := range []struct {
+		fecha, tipo       string
+		presencial, video int
+	}{
+		{"2026-10-22", models.ReunionEntreSemana, 1, 1},
+		{"15/10/2026", models.ReunionEntreSemana, 1, 1},
+		{"2026-10-15", "asamblea", 1, 1},
+		{"2026-10-15", models.ReunionEntreSemana, -1, 1},
+	} {
+		if _, err := validarAsistencia(caso.fecha, caso.tipo, caso.presencial, caso.video, hoy); !errors.Is(err, ErrAsistenciaInvalida) {
+			t.Errorf("Debía rechazarse %+v", caso)
+		}
+	}
+}
+
+func TestPeriodoAsistencia(t *testing.T) {
+	// Sin meses: el año de servicio en curso (septiembre a agosto)
+	d, h, err := periodoAsistencia("", "", time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC))
+	if err != nil || d.Format("2006-01") != "2025-09" || h.Format("2006-01") != "2026-09" {
+		t.Errorf("Año de servicio inesperado: %v %v %v", d, h, err)
+	}
+	d, h, _ = periodoAsistencia("", "", time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC))
+	if d.Format("2006-01") != "2026-09" || h.Format("2006-01") != "2027-09" {
+		t.Errorf("En septiembre empieza un nuevo año de servicio: %v %v", d, h)
+	}
+	d, h, err = periodoAsistencia("2026-01", "2026-01", time.Now())
+	if err != nil || d.Format("2006-01") != "2026-01" || h.Format("2006-01") != "2026-02" {
+		t.Errorf("Un solo mes debía abarcar hasta el siguiente: %v %v %v", d, h, err)
+	}
+	for _, p := range [][2]string{{"2026-05", "2026-04"}, {"2026-5", "2026-06"}, {"2024-01", "2026-01"}, {"2026-01", ""}} {
+		if _, _, err := periodoAsistencia(p[0], p[1], time.Now()); !errors.Is(err, ErrPeriodoInvalido) {
+			t.Errorf("Debía rechazarse el período %v", p)
+		}
+	}
+}
+
+func TestPromediarAsistencia(t *testing.T) {
+	dia := func(m time.Month, d int) time.Time { return time.Date(2026, m, d, 0, 0, 0, 0, time.UTC) }
+	registros := []models.Asistencia{
+		{Fecha: dia(11, 1), Tipo: models.ReunionFinDeSemana, Presencial: 90, Video: 10},
+		{Fecha: dia(10, 7), Tipo: models.ReunionEntreSemana, Presencial: 70, Video: 9},
+		{Fecha: dia(10, 14), Tipo: models.ReunionEntreSemana, Presencial: 75, Video: 10},
+		{Fecha: dia(10, 21), Tipo: models.ReunionEntreSemana, Presencial: 72, Video: 10},
+		{Fecha: dia(10, 11), Tipo: models.ReunionFinDeSemana, Presencial: 100, Video: 5},
+	}
+	meses := promediarAsistencia(registros)
+	if len(meses) != 3 {
+		t.Fatalf("Se esperaban 3 grupos mes/tipo, se obtuvo %+v", meses)
+	}
+	oct := meses[0]
+	if oct.Mes != "2026-10" || oct.Tipo != models.ReunionEntreSemana || oct.Reuniones != 3 {
+		t.Fatalf("Orden o agrupación inesperada: %+v", meses)
+	}
+	// 217/3 = 72,3 → 72; 29/3 = 9,7 → 10; 246/3 = 82
+	if oct.TotalPresencial != 217 || oct.PromedioPresencial != 72 || oct.PromedioVideo != 10 || oct.Promedio != 82 {
+		t.Errorf("Promedios de octubre inesperados: %+v", oct)
+	}
+	if meses[1].Tipo != models.ReunionFinDeSemana || meses[2].Mes != "2026-11" || meses[2].Total != 100 {
+		t.Errorf("Resumen inesperado: %+v", meses)
+	}
+	if len(promediarAsistencia(nil)) != 0 || promedio(5, 0) != 0 {
+		t.Errorf("Sin registros no debía haber promedios")
+	}
+}
+
+func TestCategoriaInforme(t *testing.T) {
+	casos := []struct {
+		situacion2 string
+		auxiliar   bool
+		esperada   string
+	}{
+		{"Regular", false, models.CategoriaPrecursorRegular},
+		{"Precursor especial", true, models.CategoriaPrecursorEspecial},
+		{"Auxiliar continuo", false, models.CategoriaPrecursorAuxiliar},
+		{"", true, models.CategoriaPrecursorAuxiliar},
+		{"", false, models.CategoriaPublicador},
+	}
+	for _, c := range casos {
+		if got := categoriaInforme(c.situacion2, c.auxiliar); got != c.esperada {
+			t.Errorf("%q (auxiliar=%v): se esperaba %s, se obtuvo %s", c.situacion2, c.auxiliar, c.esperada, got)
+		}
+	}
+}
+
+func TestNuevoInforme(t *testing.T) {
+	hoy := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	horas := 50
+
+	i, err := nuevoInforme(DatosInforme{Mes: "2026-09", EstudiosBiblicos: 2, Horas: &horas, Comentarios: "  Buen mes "}, "Regular", hoy)
+	if err != nil || !i.Participo || i.Categoria != models.CategoriaPrecursorRegular || i.Comentarios != "Buen mes" {
+		t.Fatalf("Informe de precursor inesperado: %+v %v", i, err)
+	}
+	if !i.Mes.Equal(time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("El mes debía guardarse como su primer día: %v", i.Mes)
+	}
+
+	rechazados := map[string]DatosInforme{
+		"horas de publicador": {Mes: "2026-09", Participo: true, Horas: &horas},
+		"mes futuro":          {Mes: "2026-11", Participo: true},
+		"mes en curso":        {Mes: "2026-10", Participo: true},
+		"sin mes":             {Participo: true},
+		"más de un año":       {Mes: "2025-09", Participo: true},
+		"cursos negativos":    {Mes: "2026-09", EstudiosBiblicos: -1},
+	}
+	for caso, d := range rechazados {
+		if _, err := nuevoInforme(d, "", hoy); !errors.Is(err, ErrInformeInvalido) {
+			t.Errorf("Debía rechazarse: %s", caso)
+		}
+	}
+	if _, err := nuevoInforme(DatosInforme{Mes: "2025-10"}, "", hoy); err != nil {
+		t.Errorf("Un informe de hace 12 meses todavía se acepta: %v", err)
+	}
+}
+
+func TestMesInformePorDefecto(t *testing.T) {
+	m, err := mesInforme("", time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC))
+	if err != nil || m.Format("2006-01") != "2025-12" {
+		t.Errorf("Sin mes se informa el anterior: %v %v", m, err)
+	}
+	if _, err := mesInforme("2026-02", time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrMesInvalido) {
+		t.Errorf("Un mes futuro debía rechazarse")
+	}
+	// El mes en curso no terminó: ni se informa ni se consulta hasta que termine
+	if _, err := mesInforme("2026-01", time.Date(2026, 1, 31, 23, 0, 0, 0, time.UTC)); !errors.Is(err, ErrMesInvalido) {
+		t.Errorf("El mes en curso debía rechazarse")
+	}
+}
+
+func TestCompletarCategorias(t *testing.T) {
+	totales := completarCategorias([]models.TotalCategoria{
+		{Categoria: models.CategoriaPrecursorRegular, Informes: 3, Participaron: 3, Horas: 150},
+	})
+	if len(totales) != len(models.CategoriasInforme) || totales[0].Categoria != models.CategoriaPublicador || totales[0].Informes != 0 {
+		t.Fatalf("Debían estar todas las categorías en orden: %+v", totales)
+	}
+	if totales[2].Categoria != models.CategoriaPrecursorRegular || totales[2].Horas != 150 {
+		t.Errorf("Se perdieron los totales de los precursores regulares: %+v", totales[2])
+	}
+}
+
+func TestPerfilSoloCamposEditables(t *testing.T) {
+	// Objetivo: Desde el perfil no se pueden tocar columnas como estado o situacion_1
+	s := &Service{}
+	for _, campo := range []string{"estado", "situacion_1", "congregacion_id", "password_hash", ""} {
+		if err := s.UpdateProfile(1, campo, "BAJA"); !errors.Is(err, ErrCampoNoEditable) {
+			t.Errorf("FALLO DE SEGURIDAD: El campo %q debía rechazarse, se obtuvo %v", campo, err)
+		}
+	}
+	if err := s.UpdateProfile(1, "canal_preferido", "paloma"); !errors.Is(err, ErrCanalInvalido) {
+		t.Errorf("Un canal desconocido debía rechazarse, se obtuvo %v", err)
+	}
+	if err := s.UpdateProfile(1, "idioma", "klingon"); !errors.Is(err, ErrIdiomaInvalido) {
+		t.Errorf("Un idioma sin catálogo debía rechazarse, se obtuvo %v", err)
+	}
+}
+
+func TestPuedeGestionarBoletin(t *testing.T) {
+	// El editor es admin local de cong-1 y de cong-3 (dos cuentas)
+	esAdminDe := func(congID string) bool { return congID == "cong-1" || congID == "cong-3" }
+	casos := []struct {
+		congBoletin string
+		puede       bool
+	}{
+		{"cong-1", true},
+		{"cong-3", true}, // Cualquiera de sus cuentas admin, no una elegida al azar
+		{"cong-2", false},
+		{"", true}, // Boletín anterior a congregacion_id: lo gestiona cualquier admin local
+	}
+	for _, c := range casos {
+		if got := puedeGestionarBoletin(c.congBoletin, esAdminDe); got != c.puede {
+			t.Errorf("puedeGestionarBoletin(%q) = %v, se esperaba %v", c.congBoletin, got, c.puede)
+		}
+	}
+}
+
+func TestCaptchaTrasFallos(t *testing.T) {
+	// Objetivo: Con 3 fallos desde la IP el login pasa por el CaptchaVerifier inyectado
+	mr := miniredis.RunT(t)
+	fake := captcha.NewFake("")
+	s := &Service{rdb: redis.NewClient(&redis.Options{Addr: mr.Addr()}), captcha: fake}
+	ctx, ip := context.Background(), "203.0.113.7"
+
+	// Sin fallos previos no se consulta el CAPTCHA
+	if err := s.exigirCaptcha(ctx, "", ip); err != nil || len(fake.Llamadas()) != 0 {
+		t.Fatalf("FALLO: Se exigió el CAPTCHA sin fallos previos: %v (%d llamadas)", err, len(fake.Llamadas()))
+	}
+
+	mr.Set("failed_login:"+ip, "3")
+	if err := s.exigirCaptcha(ctx, "", ip); err == nil || !strings.Contains(err.Error(), "Resuelva el CAPTCHA") {
+		t.Errorf("FALLO DE SEGURIDAD: Sin token debía pedirse el CAPTCHA, se obtuvo: %v", err)
+	}
+	if err := s.exigirCaptcha(ctx, "token-robado", ip); err == nil || !strings.Contains(err.Error(), "CAPTCHA no válido") {
+		t.Errorf("FALLO DE SEGURIDAD: Un token rechazado debía frenar el login, se obtuvo: %v", err)
+	}
+	if err := s.exigirCaptcha(ctx, captcha.TokenDePrueba, ip); err != nil {
+		t.Errorf("FALLO DE FLUJO: Con un CAPTCHA válido se sigue al control de credenciales: %v", err)
+	}
+
+	llamadas := fake.Llamadas()
+	if len(llamadas) != 2 || llamadas[0].Token != "token-robado" || llamadas[1].RemoteIP != ip {
+		t.Errorf("FALLO DE CONTRATO: El verificador debía recibir token e IP de cada intento: %+v", llamadas)
+	}
+}
+
+// directorioFalso: Miembros que encuentra el flujo de recuperación
+type directorioFalso struct {
+	porIDCong map[string]*repository.ContactoRecuperacion // "persona_id|numero_congregacion"
+}
+
+func (d directorioFalso) GetContactoByIDAndCong(personaID, numCong string) (*repository.ContactoRecuperacion, error) {
+	if c, ok := d.porIDCong[personaID+"|"+numCong]; ok {
+		return c, nil
+	}
+	return nil, errors.New("record not found")
+}
+func (d directorioFalso) GetContactoByPersona(personaID int) (*repository.ContactoRecuperacion, error) {
+	for _, c := range d.porIDCong {
+		if c.PersonaID == personaID {
+			return c, nil
+		}
+	}
+	return nil, errors.New("record not found")
+}
+func (d directorioFalso) GetPersonasByTelefono(variantes []string) ([]int, error) {
+	for _, c := range d.porIDCong {
+		if slices.Contains(variantes, c.Telefono) {
+			return []int{c.PersonaID}, nil
+		}
+	}
+	return nil, nil
+}
+func (d directorioFalso) GetUsernamesByPersona(int) []string { return nil }
+
+func TestRecoveryStartResponse(t *testing.T) {
+	// Objetivo: Iniciar la recuperación responde igual exista o no la cuenta (sin enumeración)
+	t.Setenv("JWT_SECRET", "secreto-de-prueba")
+	mr := miniredis.RunT(t)
+	s := &Service{
+		rdb:    redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		outbox: &bandejaFalsa{},
+		contactos: directorioFalso{porIDCong: map[string]*repository.ContactoRecuperacion{
+			"7|1234": {PersonaID: 7, Email: "elias.garcia@gmail.com", Username: "elias", NombreCompleto: "Garcia, Elias", Canal: "email", Idioma: "es"},
+		}},
+	}
+
+	iniciar := func(personaID string) *RespuestaRecuperacion {
+		r, err := s.StartRecovery(SolicitudRecuperacion{Tipo: RecuperarUsuario, Metodo: "id_cong", PersonaID: personaID, NumCong: "1234"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	// Lo único que cambia entre respuestas es el ticket y el correo enmascarado: se
+	// validan sus formatos y se reemplazan para comparar el resto byte a byte
+	normalizar := func(r *RespuestaRecuperacion) string {
+		if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(r.RecoveryID) {
+			t.Errorf("FALLO DE FORMATO: recovery_id inesperado: %q", r.RecoveryID)
+		}
+		if !regexp.MustCompile(`^[a-z]\*+[a-z]@[a-z]+\.com$`).MatchString(r.Destino) {
+			t.Errorf("FALLO DE FORMATO: destino inesperado: %q", r.Destino)
+		}
+		cuerpo, _ := json.Marshal(r)
+		return strings.Replace(strings.Replace(string(cuerpo), r.RecoveryID, "ID", 1), r.Destino, "DESTINO", 1)
+	}
+
+	if a, b := normalizar(iniciar("7")), normalizar(iniciar("8")); a != b {
+		t.Errorf("FALLO DE ENUMERACIÓN: Los cuerpos difieren: %s y %s", a, b)
+	}
+
+	// Solo la cuenta existente abrió un ticket (la otra recibió el señuelo)
+	tickets := 0
+	for _, k := range mr.Keys() {
+		if strings.HasPrefix(k, "recovery:") {
+			tickets++
+		}
+	}
+	if tickets != 1 {
+		t.Errorf("FALLO DE PRUEBA: Se esperaba un único ticket real, hay %d", tickets)
+	}
+
+	// Con SMS habilitado y recuperación por teléfono: la cuenta existe (aunque prefiera el
+	// correo) o no, la respuesta muestra el número ingresado enmascarado
+	sms := mensajeria.NewFake(mensajeria.CanalSMS)
+	s = &Service{
+		rdb:       redis.NewClient(&redis.Options{Addr: mr.Addr()}),
+		outbox:    &bandejaFalsa{},
+		telefonos: map[mensajeria.Canal]mensajeria.Notifier{mensajeria.CanalSMS: sms},
+		contactos: directorioFalso{porIDCong: map[string]*repository.ContactoRecuperacion{
+			"7|1234": {PersonaID: 7, Email: "elias.garcia@gmail.com", Telefono: "+5491141234567", Canal: "email", Idioma: "es"},
+		}},
+	}
+	porTelefono := func(numero string) string {
+		r, err := s.StartRecovery(SolicitudRecuperacion{Tipo: RecuperarUsuario, Metodo: "phone", Telefono: numero})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !regexp.MustCompile(`^\+54\*{7}\d{4}$`).MatchString(r.Destino) {
+			t.Errorf("FALLO DE ENUMERACIÓN: Destino por teléfono con otra forma: %q", r.Destino)
+		}
+		return r.Destino
+	}
+	if real, falso := porTelefono("011 15 4123-4567"), porTelefono("011 15 4123-9999"); real != "+54*******4567" || falso != "+54*******9999" {
+		t.Errorf("FALLO DE ENUMERACIÓN: Se esperaba el número ingresado enmascarado: %q y %q", real, falso)
+	}
+	for i := 0; i < 100 && len(sms.Enviados()) == 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if enviados := sms.Enviados(); len(enviados) != 1 || enviados[0].Telefono != "+5491141234567" {
+		t.Errorf("El PIN debía ir por SMS al número con el que se identificó: %+v", enviados)
+	}
+}
+
+func TestDestinoSenueloImitaAlReal(t *testing.T) {
+	// Por ID, una cuenta real que prefiere SMS muestra un teléfono: los señuelos también
+	// deben mostrar teléfonos (con la misma forma) y correos, y ser estables
+	t.Setenv("JWT_SECRET", "secreto-de-prueba")
+	s := &Service{telefonos: map[mensajeria.Canal]mensajeria.Notifier{mensajeria.CanalSMS: mensajeria.NewFake(mensajeria.CanalSMS)}}
+	real := destinoEnmascarado(&repository.ContactoRecuperacion{Telefono: "+5491141234567"}, mensajeria.CanalSMS)
+	formaTelefono := regexp.MustCompile(`^\+54\*{7}\d{4}$`)
+	formaCorreo := regexp.MustCompile(`^[a-z]\*+[a-z]@[a-z]+\.com$`)
+	if !formaTelefono.MatchString(real) {
+		t.Fatalf("Destino real inesperado: %q", real)
+	}
+
+	telefonos, correos := 0, 0
+	for i := 0; i < 200; i++ {
+		req := SolicitudRecuperacion{Metodo: "id_cong", PersonaID: strconv.Itoa(1000 + i), NumCong: "1234"}
+		clave := claveCuenta(req.identificadorObjetivo())
+		senuelo := s.destinoSenuelo(req, clave)
+		if senuelo != s.destinoSenuelo(req, clave) {
+			t.Fatalf("FALLO DE ENUMERACIÓN: Señuelo inestable para %s", req.PersonaID)
+		}
+		switch {
+		case formaTelefono.MatchString(senuelo):
+			telefonos++
+		case formaCorreo.MatchString(senuelo):
+			correos++
+		default:
+			t.Errorf("FALLO DE ENUMERACIÓN: Señuelo con forma desconocida: %q", senuelo)
+		}
+	}
+	if telefonos == 0 || correos == 0 {
+		t.Errorf("FALLO DE ENUMERACIÓN: Los señuelos deben mezclar teléfonos (%d) y correos (%d)", telefonos, correos)
+	}
+
+	// Sin canales telefónicos ninguna cuenta recibe el código por teléfono: solo correos
+	s.telefonos = nil
+	if senuelo := s.destinoSenuelo(SolicitudRecuperacion{Metodo: "phone", Telefono: "011 15 4123-9999"}, claveCuenta("x")); !formaCorreo.MatchString(senuelo) {
+		t.Errorf("Sin SMS/WhatsApp el señuelo debía ser un correo: %q", senuelo)
+	}
+}
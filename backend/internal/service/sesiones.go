@@ -0,0 +1,198 @@
+/**
+ * ARCHIVO: sesiones.go
+ * UBICACIÓN: internal/service/sesiones.go
+ * DESCRIPCIÓN: Lógica de sesiones por dispositivo. Cada login crea una sesión
+ * revocable; los JWT la referencian mediante el claim 'sid'.
+ */
+
+package service
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"time"
+
+	"gestion-congregacion/backend/internal/auth"
+	"gestion-congregacion/backend/internal/models"
+	"gestion-congregacion/backend/internal/ws"
+)
+
+var (
+	ErrSesionInvalida   = errors.New("sesión expirada o revocada")
+	ErrCuentaSuspendida = errors.New("la cuenta está suspendida o dada de baja. Contacte a un anciano de su congregación")
+	ErrSinPermiso       = errors.New("no tiene permisos sobre este miembro")
+)
+
+// newSesionID genera un UUID v4 para la columna core_sesiones.id
+func newSesionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:]), nil
+}
+
+// createSession registra el dispositivo desde el que se acaba de iniciar sesión
+func (s *Service) createSession(u *models.Usuario, ip, userAgent string) (*models.Sesion, error) {
+	id, err := newSesionID()
+	if err != nil {
+		return nil, err
+	}
+
+	// Las personas sin cuenta en core_usuarios no tienen UUID de usuario
+	var usuarioID *string
+	if u.ID != "" {
+		usuarioID = &u.ID
+	}
+
+	// Recortamos el User-Agent para no guardar cabeceras infladas
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	now := time.Now().UTC()
+	sesion := &models.Sesion{
+		ID:          id,
+		PersonaID:   u.PersonaID,
+		UsuarioID:   usuarioID,
+		UserAgent:   userAgent,
+		IP:          ip,
+		CreadoAt:    now,
+		UltimoUsoAt: now,
+		ExpiraAt:    now.Add(auth.RefreshTokenTTL),
+	}
+	return sesion, s.repo.CreateSession(sesion)
+}
+
+// ModulosDeUsuario: Módulos permitidos al usuario en su congregación (salas del WebSocket)
+func (s *Service) ModulosDeUsuario(usuarioID, congID string) ([]string, error) {
+	if usuarioID == "" || congID == "" {
+		return nil, nil
+	}
+	return s.repo.GetModulosUsuario(usuarioID, congID)
+}
+
+// RealtimeMetrics: Conexiones WebSocket de esta instancia (solo admins locales)
+func (s *Service) RealtimeMetrics(adminPersonaID int) (ws.Metricas, error) {
+	if s.repo.GetAdminCongregacion(adminPersonaID) == "" {
+		return ws.Metricas{}, ErrSinPermiso
+	}
+	return ws.Default.Metricas(), nil
+}
+
+// ValidateSession confirma que la sesión de la llave sigue activa y pertenece a la misma persona
+func (s *Service) ValidateSession(claims *auth.Claims) (*models.Sesion, error) {
+	if claims.SesionID == "" {
+		return nil, ErrSesionInvalida
+	}
+	sesion, err := s.repo.GetActiveSession(claims.SesionID)
+	if err != nil || sesion.PersonaID != claims.PersonaID {
+		return nil, ErrSesionInvalida
+	}
+
+	// La suspensión y los cambios de clave se aplican en cada petición, no solo en el próximo login
+	estado, err := s.repo.GetEstadoSeguridad(sesion.PersonaID)
+	if err != nil {
+		return nil, ErrSesionInvalida
+	}
+	if estado.Estado == "BAJA" || estado.EstadoCuenta == "suspendida" {
+		return nil, ErrCuentaSuspendida
+	}
+	if claims.IssuedAt == nil || emitidaAntesDe(claims.IssuedAt.Time, estado.PasswordChangedAt, estado.UsuarioPasswordChangedAt, estado.SecurityUpdatedAt) {
+		return nil, ErrSesionInvalida
+	}
+	return sesion, nil
+}
+
+// emitidaAntesDe indica si la llave es anterior a alguno de los eventos de seguridad.
+// 'iat' tiene resolución de segundos, por eso truncamos los eventos antes de comparar.
+func emitidaAntesDe(iat time.Time, eventos ...*time.Time) bool {
+	for _, e := range eventos {
+		if e != nil && iat.Before(e.Truncate(time.Second)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RefreshSession valida la llave larga y emite una nueva llave corta para la misma sesión
+func (s *Service) RefreshSession(refreshToken, ip string) (string, error) {
+	claims, err := auth.ValidarRefreshToken(refreshToken)
+	if err != nil {
+		return "", ErrSesionInvalida
+	}
+
+	sesion, err := s.ValidateSession(claims)
+	if err != nil {
+		return "", err
+	}
+
+	s.repo.TouchSession(sesion.ID, ip)
+
+	return auth.GenerarAccessToken(claims.Identidad())
+}
+
+// Logout revoca la sesión actual (el propio usuario la cierra)
+func (s *Service) Logout(sesion *models.Sesion) error {
+	_, err := s.repo.RevokeSession(sesion.ID, sesion.PersonaID, sesion.PersonaID)
+	return err
+}
+
+// ListSessions devuelve los dispositivos conectados de la persona, marcando el actual
+func (s *Service) ListSessions(personaID int, actualID string) ([]models.Sesion, error) {
+	lista, err := s.repo.ListActiveSessions(personaID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range lista {
+		lista[i].Actual = lista[i].ID == actualID
+	}
+	return lista, nil
+}
+
+// RevokeSession permite a un usuario cerrar uno de sus propios dispositivos
+func (s *Service) RevokeSession(personaID int, sesionID string) error {
+	n, err := s.repo.RevokeSession(sesionID, personaID, personaID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSesionInvalida
+	}
+	return nil
+}
+
+// authorizeAdminOver verifica que el admin local pertenezca a la misma congregación que el miembro
+func (s *Service) authorizeAdminOver(adminPersonaID, miembroPersonaID int) error {
+	congAdmin := s.repo.GetAdminCongregacion(adminPersonaID)
+	if congAdmin == "" || congAdmin != s.repo.GetPersonaCongregacion(miembroPersonaID) {
+		return ErrSinPermiso
+	}
+	return nil
+}
+
+// ListMemberSessions: Vista de administrador de los dispositivos de un miembro
+func (s *Service) ListMemberSessions(adminPersonaID, miembroPersonaID int) ([]models.Sesion, error) {
+	if err := s.authorizeAdminOver(adminPersonaID, miembroPersonaID); err != nil {
+		return nil, err
+	}
+	return s.repo.ListActiveSessions(miembroPersonaID)
+}
+
+// RevokeMemberSession: Un admin cierra la sesión de un miembro (ej: teléfono perdido)
+func (s *Service) RevokeMemberSession(adminPersonaID, miembroPersonaID int, sesionID string) error {
+	if err := s.authorizeAdminOver(adminPersonaID, miembroPersonaID); err != nil {
+		return err
+	}
+	n, err := s.repo.RevokeSession(sesionID, miembroPersonaID, adminPersonaID)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrSesionInvalida
+	}
+	return nil
+}
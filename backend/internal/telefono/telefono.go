@@ -0,0 +1,180 @@
+/**
+ * ARCHIVO: telefono.go
+ * UBICACIÓN: internal/telefono/telefono.go
+ * DESCRIPCIÓN: Normalización de números de teléfono al formato internacional E.164
+ * (ej: +5491112345678). Los números nacionales se completan con el país por
+ * defecto de la congregación, lo que permite buscarlos por igualdad en un índice.
+ * Argentina marca los celulares distinto dentro y fuera del país ("0 11 15 ..." y
+ * "+54 9 11 ..."): Normalizar aplica ambas reglas y Variantes da las dos formas que
+ * puede tener guardado el mismo número.
+ */
+
+package telefono
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+// PaisPorDefecto se usa cuando no se conoce la congregación (ej: recuperación de cuenta).
+// Puede cambiarse con TELEFONO_PAIS_POR_DEFECTO.
+const PaisPorDefecto = "AR"
+
+var (
+	ErrVacio           = errors.New("el teléfono está vacío")
+	ErrPaisDesconocido = errors.New("país sin código telefónico configurado")
+	ErrLongitud        = errors.New("el teléfono no tiene una longitud válida")
+)
+
+// plan describe el código de país y el prefijo troncal que se marca dentro del país
+type plan struct {
+	codigo  string
+	troncal string
+}
+
+// planes: Códigos ISO 3166-1 alfa-2 de los países donde hay congregaciones usuarias
+var planes = map[string]plan{
+	"AR": {"54", "0"},
+	"BO": {"591", "0"},
+	"BR": {"55", "0"},
+	"CL": {"56", ""},
+	"CO": {"57", ""},
+	"CR": {"506", ""},
+	"CU": {"53", "0"},
+	"DE": {"49", "0"},
+	"DO": {"1", "1"},
+	"EC": {"593", "0"},
+	"ES": {"34", ""},
+	"FR": {"33", "0"},
+	"GB": {"44", "0"},
+	"GT": {"502", ""},
+	"HN": {"504", ""},
+	"MX": {"52", ""},
+	"NI": {"505", ""},
+	"PA": {"507", ""},
+	"PE": {"51", "0"},
+	"PR": {"1", "1"},
+	"PT": {"351", ""},
+	"PY": {"595", "0"},
+	"SV": {"503", ""},
+	"US": {"1", "1"},
+	"UY": {"598", "0"},
+	"VE": {"58", "0"},
+}
+
+// PaisRecuperacion devuelve el país usado para interpretar números nacionales sin congregación
+func PaisRecuperacion() string {
+	if p := strings.TrimSpace(os.Getenv("TELEFONO_PAIS_POR_DEFECTO")); p != "" {
+		return strings.ToUpper(p)
+	}
+	return PaisPorDefecto
+}
+
+// CodigoPais devuelve el código telefónico del país ("54" para "AR"); "" si no está en planes
+func CodigoPais(pais string) string {
+	return planes[strings.ToUpper(strings.TrimSpace(pais))].codigo
+}
+
+// Normalizar convierte un número escrito libremente a E.164.
+// Acepta "+54 9 11 1234-5678", "0054..." o un número nacional ("011 1234-5678"),
+// en cuyo caso se quita el prefijo troncal y se antepone el código de 'pais'.
+func Normalizar(numero, pais string) (string, error) {
+	numero = strings.TrimSpace(numero)
+	internacional := strings.HasPrefix(numero, "+")
+
+	var digitos strings.Builder
+	for _, r := range numero {
+		if r >= '0' && r <= '9' {
+			digitos.WriteRune(r)
+		}
+	}
+	d := digitos.String()
+	if d == "" {
+		return "", ErrVacio
+	}
+
+	switch {
+	case internacional:
+	case strings.HasPrefix(d, "00"):
+		d = d[2:]
+	default:
+		p, ok := planes[strings.ToUpper(strings.TrimSpace(pais))]
+		if !ok {
+			return "", ErrPaisDesconocido
+		}
+		if p.troncal != "" {
+			d = strings.TrimPrefix(d, p.troncal)
+		}
+		d = p.codigo + d
+	}
+
+	if strings.HasPrefix(d, codigoAR) {
+		d = normalizarAR(d)
+	}
+
+	// E.164: hasta 15 dígitos en total; menos de 8 no alcanza para ningún plan nacional
+	if len(d) < 8 || len(d) > 15 || d[0] == '0' {
+		return "", ErrLongitud
+	}
+	return "+" + d, nil
+}
+
+const (
+	codigoAR = "54"
+	// Número nacional argentino: código de área (2 a 4 dígitos) + abonado = 10 dígitos
+	largoNacionalAR = 10
+)
+
+// normalizarAR lleva un celular argentino a la forma internacional "54 9 <área> <abonado>".
+// Dentro del país se marca "<área> 15 <abonado>": el "15" se quita y se antepone el 9.
+// Sin "15" ni "9" no se sabe si es fijo o celular y el número queda como está.
+func normalizarAR(d string) string {
+	nacional := d[len(codigoAR):]
+	celular := false
+	if len(nacional) == largoNacionalAR+1 && nacional[0] == '9' {
+		nacional, celular = nacional[1:], true
+	}
+	if len(nacional) == largoNacionalAR+2 {
+		if i := posicion15(nacional); i > 0 {
+			nacional, celular = nacional[:i]+nacional[i+2:], true
+		}
+	}
+	if celular {
+		return codigoAR + "9" + nacional
+	}
+	return codigoAR + nacional
+}
+
+// posicion15 ubica el "15" después del código de área (11 en Buenos Aires, si no 3 o 4 dígitos)
+func posicion15(nacional string) int {
+	if strings.HasPrefix(nacional, "11") {
+		if nacional[2:4] == "15" {
+			return 2
+		}
+		return -1
+	}
+	for _, i := range []int{3, 4} {
+		if nacional[i:i+2] == "15" {
+			return i
+		}
+	}
+	return -1
+}
+
+// Variantes devuelve las formas E.164 bajo las que puede estar guardado el número: para
+// Argentina, con y sin el 9 de celular (un "011 ..." nacional no lo lleva); si no, él mismo
+func Variantes(e164 string) []string {
+	d := strings.TrimPrefix(e164, "+")
+	if !strings.HasPrefix(d, codigoAR) {
+		return []string{e164}
+	}
+	nacional := d[len(codigoAR):]
+	if len(nacional) == largoNacionalAR+1 && nacional[0] == '9' {
+		nacional = nacional[1:]
+	}
+	if len(nacional) != largoNacionalAR {
+		return []string{e164}
+	}
+	return []string{"+" + codigoAR + "9" + nacional, "+" + codigoAR + nacional}
+}
@@ -0,0 +1,66 @@
+/**
+ * ARCHIVO: telefono_test.go
+ * UBICACIÓN: backend/internal/telefono/telefono_test.go
+ * DESCRIPCIÓN: Normalización a E.164 (incluidas las reglas de celulares argentinos).
+ */
+
+package telefono
+
+import "testing"
+
+func TestNormalizar(t *testing.T) {
+	casos := []struct {
+		numero, pais, esperado string
+	}{
+		{"011 4123-4567", "AR", "+541141234567"},
+		{"+54 9 11 4123-4567", "UY", "+5491141234567"},
+		{"0054 9 11 4123 4567", "AR", "+5491141234567"},
+		{"099 123 456", "UY", "+59899123456"},
+		{"1 (555) 123-4567", "US", "+15551234567"},
+		{"612 34 56 78", "es", "+34612345678"},
+		// Celulares argentinos: el "15" nacional equivale al 9 internacional
+		{"011 15 4123-4567", "AR", "+5491141234567"},
+		{"(0351) 15 612-3456", "AR", "+5493516123456"},
+		{"02966 15 41-2345", "AR", "+5492966412345"},
+		{"+54 11 15 4123-4567", "AR", "+5491141234567"},
+		{"+54 351 612-3456", "AR", "+543516123456"},
+	}
+	for _, c := range casos {
+		got, err := Normalizar(c.numero, c.pais)
+		if err != nil || got != c.esperado {
+			t.Errorf("Normalizar(%q, %q) = %q, %v; se esperaba %q", c.numero, c.pais, got, err, c.esperado)
+		}
+	}
+}
+
+func TestNormalizarRechazaInvalidos(t *testing.T) {
+	casos := map[string]error{
+		"":                     ErrVacio,
+		"sin numeros":          ErrVacio,
+		"1234":                 ErrLongitud,
+		"+1234567890123456789": ErrLongitud,
+	}
+	for numero, esperado := range casos {
+		if _, err := Normalizar(numero, "AR"); err != esperado {
+			t.Errorf("Normalizar(%q) devolvió %v; se esperaba %v", numero, err, esperado)
+		}
+	}
+	if _, err := Normalizar("4123-4567", "ZZ"); err != ErrPaisDesconocido {
+		t.Errorf("Un país sin plan debe rechazarse, se obtuvo %v", err)
+	}
+}
+
+func TestVariantesArgentina(t *testing.T) {
+	// "011 4123-4567" (sin 9 ni 15) y "+54 9 11 4123-4567" deben encontrarse entre sí
+	fijo, _ := Normalizar("011 4123-4567", "AR")
+	celular, _ := Normalizar("+54 9 11 4123-4567", "AR")
+	for _, e164 := range []string{fijo, celular} {
+		v := Variantes(e164)
+		if len(v) != 2 || v[0] != "+5491141234567" || v[1] != "+541141234567" {
+			t.Errorf("Variantes(%q) = %v", e164, v)
+		}
+	}
+	if v := Variantes("+59899123456"); len(v) != 1 || v[0] != "+59899123456" {
+		t.Errorf("Fuera de Argentina el número no tiene variantes: %v", v)
+	}
+}
@@ -0,0 +1,159 @@
+/**
+ * ARCHIVO: eventos.go
+ * UBICACIÓN: internal/ws/eventos.go
+ * DESCRIPCIÓN: Protocolo de tiempo real. Todo lo que sale del servidor es un Evento
+ * (type, version, id, timestamp, payload) y lo que manda el cliente es un
+ * MensajeCliente (subscribe, unsubscribe, ack). El esquema JSON publicado
+ * (esquema.json, servido en /api/eventos/esquema) describe ambos; si cambia la
+ * forma de un payload, sube su versión aquí y allí.
+ */
+
+package ws
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// VersionProtocolo: Versión del sobre y de los mensajes del cliente
+const VersionProtocolo = 1
+
+// Tipos de evento del servidor
+const (
+	EventoAlertaSeguridad       = "ALERTA_SEGURIDAD"
+	EventoRecordatorioSeguridad = "RECORDATORIO_SEGURIDAD"
+	EventoLiteraturaLista       = "LITERATURA_LISTA"
+	EventoReunionIniciada       = "REUNION_INICIADA"
+	EventoReunionFinalizada     = "REUNION_FINALIZADA"
+	EventoPresencia             = "PRESENCIA"
+
+	// Control: respuestas del hub a la conexión
+	EventoConectado   = "CONECTADO"
+	EventoSuscripcion = "SUSCRIPCION"
+	EventoError       = "ERROR"
+)
+
+// versiones: Versión vigente del payload de cada tipo (debe coincidir con esquema.json)
+var versiones = map[string]int{
+	EventoAlertaSeguridad:       1,
+	EventoRecordatorioSeguridad: 1,
+	EventoLiteraturaLista:       1,
+	EventoReunionIniciada:       1,
+	EventoReunionFinalizada:     1,
+	EventoPresencia:             1,
+	EventoConectado:             1,
+	EventoSuscripcion:           1,
+	EventoError:                 1,
+}
+
+// Evento: Sobre común de todo lo que envía el servidor. El hub completa el id
+// (número de secuencia con Redis, "<instancia>-<n>" sin él).
+type Evento struct {
+	Tipo    string      `json:"type"`
+	Version int         `json:"version"`
+	ID      string      `json:"id,omitempty"`
+	Fecha   time.Time   `json:"timestamp"`
+	Datos   interface{} `json:"payload"`
+}
+
+// NuevoEvento arma el sobre con la versión vigente del tipo y la hora actual
+func NuevoEvento(tipo string, datos interface{}) Evento {
+	version, ok := versiones[tipo]
+	if !ok {
+		version = 1
+	}
+	return Evento{Tipo: tipo, Version: version, Fecha: time.Now().UTC(), Datos: datos}
+}
+
+// Payloads
+
+// AlertaSeguridad: Se publicó un boletín de seguridad
+type AlertaSeguridad struct {
+	Titulo  string `json:"titulo"`
+	Mensaje string `json:"mensaje"`
+}
+
+// RecordatorioSeguridad: Un boletín sigue sin confirmarse
+type RecordatorioSeguridad struct {
+	BoletinID int    `json:"boletin_id"`
+	Titulo    string `json:"titulo"`
+}
+
+// LiteraturaLista: Un pedido quedó apartado para retirar
+type LiteraturaLista struct {
+	PedidoID    int    `json:"pedido_id"`
+	Publicacion string `json:"publicacion"`
+	Cantidad    int    `json:"cantidad"`
+}
+
+// Reunion: Una reunión empezó (los miembros entran a su sala con subscribe) o terminó
+type Reunion struct {
+	ReunionID int    `json:"reunion_id"`
+	Tipo      string `json:"tipo"`
+	Sala      string `json:"sala"`
+	Presentes *int   `json:"presentes,omitempty"` // Asistencia registrada al finalizar
+}
+
+// Presencia: Personas en la sala de reunión (solo a los ancianos de la congregación)
+type Presencia struct {
+	CongregacionID string `json:"congregacion_id"`
+	Presentes      int    `json:"presentes"`
+}
+
+// Conectado: Primer evento de cada conexión
+type Conectado struct {
+	Conexion   string   `json:"conexion"` // Identifica la conexión en POST /api/eventos/{conexion}
+	Transporte string   `json:"transporte"`
+	Protocolo  int      `json:"protocolo"`
+	Salas      []string `json:"salas"`
+}
+
+// Suscripcion: Salas de la conexión después de un subscribe/unsubscribe
+type Suscripcion struct {
+	Salas      []string `json:"salas"`
+	Rechazadas []string `json:"rechazadas,omitempty"` // Pedidas sin permiso
+}
+
+// ErrorProtocolo: Mensaje del cliente que no se pudo procesar
+type ErrorProtocolo struct {
+	Mensaje string `json:"mensaje"`
+}
+
+// Mensajes del cliente
+const (
+	MensajeSuscribir   = "subscribe"
+	MensajeDesuscribir = "unsubscribe"
+	MensajeConfirmar   = "ack"
+)
+
+// MensajeCliente: subscribe/unsubscribe llevan 'salas'; ack lleva el 'id' del
+// último evento procesado (desde ahí se repone si se reconecta sin last_seen_id)
+type MensajeCliente struct {
+	Tipo  string   `json:"type"`
+	Salas []string `json:"salas,omitempty"`
+	ID    string   `json:"id,omitempty"`
+}
+
+var (
+	ErrMensajeInvalido     = errors.New("mensaje de tiempo real inválido")
+	ErrConexionDesconocida = errors.New("la conexión no existe o no es suya")
+)
+
+//go:embed esquema.json
+var esquema []byte
+
+// Esquema devuelve el JSON Schema del protocolo
+func Esquema() []byte { return esquema }
+
+// SalaReunion: Nombre de la sala a la que se entra para contar como presente
+func SalaReunion(congID string) string { return salaReunion(congID) }
+
+// idConexion: Aleatorio, para que no se pueda adivinar el de otra conexión
+func idConexion() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
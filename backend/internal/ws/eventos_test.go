@@ -0,0 +1,56 @@
+/**
+ * ARCHIVO: eventos_test.go
+ * UBICACIÓN: backend/internal/ws/eventos_test.go
+ * DESCRIPCIÓN: El esquema publicado debe describir cada tipo de evento con la
+ * misma versión que usa el código.
+ */
+
+package ws
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestEsquemaCubreLosTipos(t *testing.T) {
+	var esq struct {
+		Protocolo int `json:"x-protocolo"`
+		Defs      map[string]struct {
+			Version int `json:"x-version"`
+		} `json:"$defs"`
+	}
+	if err := json.Unmarshal(Esquema(), &esq); err != nil {
+		t.Fatalf("esquema.json no es JSON válido: %v", err)
+	}
+	if esq.Protocolo != VersionProtocolo {
+		t.Errorf("Protocolo %d en el esquema, %d en el código", esq.Protocolo, VersionProtocolo)
+	}
+	for tipo, version := range versiones {
+		def, ok := esq.Defs[tipo]
+		if !ok {
+			t.Errorf("Falta %s en el esquema", tipo)
+			continue
+		}
+		if def.Version != version {
+			t.Errorf("%s: versión %d en el esquema, %d en el código", tipo, def.Version, version)
+		}
+	}
+}
+
+func TestNuevoEvento(t *testing.T) {
+	ev := NuevoEvento(EventoAlertaSeguridad, AlertaSeguridad{Titulo: "T", Mensaje: "M"})
+	datos, err := conID(ev, "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]json.RawMessage
+	json.Unmarshal(datos, &m)
+	for _, campo := range []string{"type", "version", "id", "timestamp", "payload"} {
+		if _, ok := m[campo]; !ok {
+			t.Errorf("Falta %q en el sobre: %s", campo, datos)
+		}
+	}
+	if string(m["id"]) != `"7"` || string(m["payload"]) != `{"titulo":"T","mensaje":"M"}` {
+		t.Errorf("Sobre inesperado: %s", datos)
+	}
+}
@@ -3,45 +3,627 @@
  * UBICACIÓN: Backend/internal/ws/hub.go
  * DESCRIPCIÓN: Gestiona las conexiones activas de WebSockets.
  * Permite enviar notificaciones 'Push' desde el servidor al cliente.
+ * Solo se aceptan conexiones autenticadas (el handler valida la sesión antes de
+ * llamar a Serve y el hub la revalida mientras la conexión sigue abierta) y desde
+ * los orígenes de ALLOWED_ORIGINS. Cada conexión queda en
+ * las salas de su usuario, su persona, su congregación y los módulos que tiene
+ * permitidos, así cada aviso llega solo a quien corresponde.
+ * Cada conexión tiene su cola y sus dos goroutines (lectura y escritura con
+ * ping/pong); emitir un aviso nunca espera a la red. Con Redis (UsarRedis) los
+ * avisos pasan por pub/sub y cada instancia del backend reparte a sus conectados;
+ * quien se reconecta con ?last_seen_id= recibe antes lo que se perdió.
+ * El cliente puede salir y volver a entrar a sus salas (subscribe/unsubscribe) y
+ * confirmar lo procesado (ack); donde no hay WebSocket, el mismo hub sirve SSE.
  */
 
 package ws
 
 import (
-	"github.com/gorilla/websocket"
+	"encoding/json"
+	"log"
 	"net/http"
-	"sync"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Tiempo máximo para escribir un mensaje antes de dar la conexión por caída
+	esperaEscritura = 10 * time.Second
+	// Sin un pong en este plazo la conexión se da por muerta
+	esperaPong = 60 * time.Second
+	// Ping antes de que venza la espera del pong
+	pingCada = esperaPong * 9 / 10
+	// Los clientes no mandan más que avisos de control: mensajes chicos
+	maxMensajeEntrante = 4096
+	// Mensajes pendientes por conexión; si se llena, el cliente es lento y se desaloja
+	tamColaCliente = 64
+	// Envíos pendientes de repartir; si se llena, el evento se descarta (nunca se bloquea al emisor)
+	tamColaHub = 1024
+	// Cada cuánto se revalida la sesión de una conexión abierta
+	revisarSesionCada = time.Minute
 )
 
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true }, // Permitir React
+// Cliente: Identidad de una conexión ya autenticada
+type Cliente struct {
+	UsuarioID      string
+	PersonaID      int
+	CongregacionID string
+	Modulos        []string // Módulos con permiso en su congregación (core_permisos_modulos)
+	Anciano        bool     // Ve la presencia en las reuniones de su congregación
+	// Vigente revalida la sesión mientras dura la conexión (nil: no se revisa). Si la
+	// sesión se revocó o la cuenta se suspendió, la conexión se cierra.
+	Vigente func() bool
 }
 
-// Clients guarda los punteros a las conexiones activas
-var clients = make(map[*websocket.Conn]bool)
-var mutex = &sync.Mutex{}
+// conexion: Un cliente conectado (un usuario puede tener varios, uno por dispositivo).
+// Solo su writePump escribe en el transporte; el hub le pasa los mensajes por 'cola'.
+// 'salas' son las actuales (las toca solo run); 'autorizadas', a las que puede volver.
+type conexion struct {
+	id          string
+	t           transporte
+	tipo        string // "websocket" o "sse"
+	cliente     Cliente
+	autorizadas []string
+	salas       []string
+	cola        chan envio
+}
+
+// transporte: Cómo llegan los eventos al cliente (WebSocket o SSE)
+type transporte interface {
+	escribir(datos []byte) error
+	latido() error
+	despedir() // El hub la dio de baja (desalojo)
+	expirar()  // La sesión dejó de ser válida
+	cerrar()
+}
+
+// comando: subscribe/unsubscribe para run. Por WebSocket llega la conexión; por
+// HTTP (SSE) llega su id y el cliente autenticado, que debe ser el dueño.
+type comando struct {
+	c    *conexion
+	id   string
+	cli  Cliente
+	msg  MensajeCliente
+	resp chan error
+}
 
-// WsHandler eleva la conexión HTTP a WebSocket
-func WsHandler(w http.ResponseWriter, r *http.Request) {
-	conn, err := upgrader.Upgrade(w, r, nil)
+// envio: Mensaje ya serializado para una sala ("" = todas las conexiones).
+// 'seq' es su número en el historial de Redis (0 si no pasó por Redis).
+type envio struct {
+	sala  string
+	seq   uint64
+	datos []byte
+}
+
+// Metricas: Estado del hub para el panel de administración
+type Metricas struct {
+	Conectados  int64  `json:"conectados"`
+	Salas       int64  `json:"salas"`
+	Enviados    uint64 `json:"enviados"`    // Mensajes entregados a la cola de cada conexión
+	Descartados uint64 `json:"descartados"` // Eventos perdidos con la cola del hub llena
+	Desalojados uint64 `json:"desalojados"` // Conexiones cortadas por no leer a tiempo
+}
+
+// Hub reparte los mensajes desde una sola goroutine (run), dueña de los mapas de
+// conexiones y salas. Emitir nunca bloquea: si un cliente no vacía su cola se lo
+// desaloja, y si el hub está saturado el evento se descarta y se cuenta.
+type Hub struct {
+	conexiones map[*conexion]struct{}
+	salas      map[string]map[*conexion]struct{}
+	porID      map[string]*conexion
+	altas      chan *conexion
+	bajas      chan *conexion
+	envios     chan envio
+	comandos   chan comando
+	upgrader   websocket.Upgrader
+
+	// Plazos y tamaños (las pruebas los acortan)
+	pingCada        time.Duration
+	esperaPong      time.Duration
+	esperaEscritura time.Duration
+	tamCola         int
+	revisarSesion   time.Duration
+
+	// Redis (UsarRedis): con suscripción activa los envíos salen por pub/sub
+	rdb               atomic.Pointer[redis.Client]
+	publicaciones     chan envio
+	maxEventos        int // Historial por sala para reponer a quien se reconecta
+	reintentoRedis    time.Duration
+	maxReintentoRedis time.Duration
+
+	// Presencia en las salas de reunión (presencia.go)
+	pres              presencia
+	presencias        chan cambioPresencia
+	vigenciaPresencia time.Duration
+	renovarPresencia  time.Duration
+
+	conectados  atomic.Int64
+	numSalas    atomic.Int64
+	enviados    atomic.Uint64
+	descartados atomic.Uint64
+	desalojados atomic.Uint64
+}
+
+// NewHub crea un hub que acepta los orígenes de ALLOWED_ORIGINS y lo pone a repartir
+func NewHub() *Hub {
+	return nuevoHub(pingCada, esperaPong, esperaEscritura, tamColaCliente)
+}
+
+func nuevoHub(ping, pong, escritura time.Duration, tamCola int) *Hub {
+	h := &Hub{
+		conexiones:      make(map[*conexion]struct{}),
+		salas:           make(map[string]map[*conexion]struct{}),
+		porID:           make(map[string]*conexion),
+		altas:           make(chan *conexion),
+		bajas:           make(chan *conexion),
+		envios:          make(chan envio, tamColaHub),
+		comandos:        make(chan comando),
+		publicaciones:   make(chan envio, tamColaHub),
+		pingCada:        ping,
+		esperaPong:      pong,
+		esperaEscritura: escritura,
+		tamCola:         tamCola,
+		revisarSesion:   revisarSesionCada,
+		maxEventos:      maxEventosSala,
+
+		reintentoRedis:    reintentoRedis,
+		maxReintentoRedis: maxReintentoRedis,
+
+		presencias:        make(chan cambioPresencia, tamColaPresencia),
+		vigenciaPresencia: vigenciaPresencia,
+		renovarPresencia:  renovarPresencia,
+	}
+	h.upgrader = websocket.Upgrader{CheckOrigin: origenPermitido}
+	go h.run()
+	go h.seguirPresencia()
+	return h
+}
+
+// Default: Hub del proceso que usan las funciones del paquete
+var Default = NewHub()
+
+// origenPermitido: Misma lista que CORS. Sin cabecera Origin no es un navegador
+// (y sin navegador no hay riesgo de que otra web use la cookie del usuario).
+func origenPermitido(r *http.Request) bool {
+	origen := r.Header.Get("Origin")
+	if origen == "" {
+		return true
+	}
+	permitidos := os.Getenv("ALLOWED_ORIGINS")
+	if permitidos == "" {
+		permitidos = "https://gestion-congregacion.vercel.app"
+	}
+	for _, p := range strings.Split(permitidos, ",") {
+		if strings.EqualFold(strings.TrimSpace(p), origen) {
+			return true
+		}
+	}
+	return false
+}
+
+// Nombres de sala
+func salaUsuario(usuarioID string) string     { return "usuario:" + usuarioID }
+func salaPersona(personaID int) string        { return "persona:" + strconv.Itoa(personaID) }
+func salaCongregacion(congID string) string   { return "congregacion:" + congID }
+func salaModulo(congID, modulo string) string { return "modulo:" + congID + ":" + modulo }
+
+// salasDe: Salas a las que pertenece un cliente
+func salasDe(c Cliente) []string {
+	var salas []string
+	if c.UsuarioID != "" {
+		salas = append(salas, salaUsuario(c.UsuarioID))
+	}
+	if c.PersonaID != 0 {
+		salas = append(salas, salaPersona(c.PersonaID))
+	}
+	if c.CongregacionID != "" {
+		salas = append(salas, salaCongregacion(c.CongregacionID))
+		for _, m := range c.Modulos {
+			salas = append(salas, salaModulo(c.CongregacionID, m))
+		}
+		if c.Anciano {
+			salas = append(salas, salaAncianos(c.CongregacionID))
+		}
+	}
+	return salas
+}
+
+// autorizadasDe: Sus salas más las que puede pedir con subscribe (la de la reunión)
+func autorizadasDe(c Cliente) []string {
+	salas := salasDe(c)
+	if c.CongregacionID != "" {
+		salas = append(salas, salaReunion(c.CongregacionID))
+	}
+	return salas
+}
+
+// run es la única goroutine que toca los mapas
+func (h *Hub) run() {
+	for {
+		select {
+		case c := <-h.altas:
+			h.conexiones[c] = struct{}{}
+			h.porID[c.id] = c
+			for _, s := range c.salas {
+				h.unir(c, s)
+			}
+			h.actualizarTotales()
+
+		case c := <-h.bajas:
+			h.eliminar(c)
+
+		case cmd := <-h.comandos:
+			cmd.resp <- h.aplicar(cmd)
+
+		case e := <-h.envios:
+			destinos := h.conexiones
+			if e.sala != "" {
+				destinos = h.salas[e.sala]
+			}
+			for c := range destinos {
+				select {
+				case c.cola <- e:
+					h.enviados.Add(1)
+				default:
+					// Cola llena: el cliente no lee (red lenta o pestaña colgada)
+					h.eliminar(c)
+					h.desalojados.Add(1)
+				}
+			}
+		}
+	}
+}
+
+func (h *Hub) unir(c *conexion, sala string) {
+	if h.salas[sala] == nil {
+		h.salas[sala] = make(map[*conexion]struct{})
+	}
+	h.salas[sala][c] = struct{}{}
+	h.registrarPresencia(c, sala, true)
+}
+
+func (h *Hub) separar(c *conexion, sala string) {
+	h.registrarPresencia(c, sala, false)
+	delete(h.salas[sala], c)
+	if len(h.salas[sala]) == 0 {
+		delete(h.salas, sala)
+	}
+}
+
+// eliminar saca la conexión de sus salas y cierra su cola; el writePump cierra el transporte.
+// Puede llegar dos veces (desalojo y luego la baja del readPump): la segunda no hace nada.
+func (h *Hub) eliminar(c *conexion) {
+	if _, ok := h.conexiones[c]; !ok {
+		return
+	}
+	delete(h.conexiones, c)
+	delete(h.porID, c.id)
+	for _, s := range c.salas {
+		h.separar(c, s)
+	}
+	close(c.cola)
+	h.actualizarTotales()
+}
+
+// aplicar procesa un subscribe/unsubscribe y le contesta a la conexión con sus salas
+// (o con un ERROR). Solo se puede entrar a las salas que el cliente ya tenía permitidas.
+func (h *Hub) aplicar(cmd comando) error {
+	c := cmd.c
+	if c == nil {
+		c = h.porID[cmd.id]
+		if c == nil || c.cliente.UsuarioID != cmd.cli.UsuarioID || c.cliente.PersonaID != cmd.cli.PersonaID {
+			return ErrConexionDesconocida
+		}
+	}
+	if _, ok := h.conexiones[c]; !ok {
+		return ErrConexionDesconocida
+	}
+
+	var rechazadas []string
+	switch cmd.msg.Tipo {
+	case MensajeSuscribir:
+		for _, s := range cmd.msg.Salas {
+			switch {
+			case !contiene(c.autorizadas, s):
+				rechazadas = append(rechazadas, s)
+			case !contiene(c.salas, s):
+				c.salas = append(c.salas, s)
+				h.unir(c, s)
+			}
+		}
+	case MensajeDesuscribir:
+		for _, s := range cmd.msg.Salas {
+			if i := indice(c.salas, s); i >= 0 {
+				c.salas = append(c.salas[:i:i], c.salas[i+1:]...)
+				h.separar(c, s)
+			}
+		}
+	default:
+		h.avisar(c, NuevoEvento(EventoError, ErrorProtocolo{Mensaje: ErrMensajeInvalido.Error()}))
+		return ErrMensajeInvalido
+	}
+	h.actualizarTotales()
+	h.avisar(c, NuevoEvento(EventoSuscripcion, Suscripcion{Salas: append([]string{}, c.salas...), Rechazadas: rechazadas}))
+	return nil
+}
+
+// avisar: Respuesta de control solo para esta conexión (si su cola está llena, se pierde)
+func (h *Hub) avisar(c *conexion, ev Evento) {
+	datos, err := conID(ev, nuevoID())
 	if err != nil {
 		return
 	}
+	select {
+	case c.cola <- envio{datos: datos}:
+	default:
+	}
+}
+
+func indice(lista []string, s string) int {
+	for i, v := range lista {
+		if v == s {
+			return i
+		}
+	}
+	return -1
+}
+
+func contiene(lista []string, s string) bool { return indice(lista, s) >= 0 }
+
+func (h *Hub) actualizarTotales() {
+	h.conectados.Store(int64(len(h.conexiones)))
+	h.numSalas.Store(int64(len(h.salas)))
+}
+
+// Metricas devuelve una foto de los contadores del hub
+func (h *Hub) Metricas() Metricas {
+	return Metricas{
+		Conectados:  h.conectados.Load(),
+		Salas:       h.numSalas.Load(),
+		Enviados:    h.enviados.Load(),
+		Descartados: h.descartados.Load(),
+		Desalojados: h.desalojados.Load(),
+	}
+}
+
+// transporteWS: Escribe frames de texto y pings sobre el socket
+type transporteWS struct {
+	conn   *websocket.Conn
+	espera time.Duration
+}
+
+func (t *transporteWS) escribir(datos []byte) error {
+	t.conn.SetWriteDeadline(time.Now().Add(t.espera))
+	return t.conn.WriteMessage(websocket.TextMessage, datos)
+}
+
+func (t *transporteWS) latido() error {
+	t.conn.SetWriteDeadline(time.Now().Add(t.espera))
+	return t.conn.WriteMessage(websocket.PingMessage, nil)
+}
+
+func (t *transporteWS) despedir() {
+	t.conn.SetWriteDeadline(time.Now().Add(t.espera))
+	t.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "cliente lento"))
+}
 
-	mutex.Lock()
-	clients[conn] = true
-	mutex.Unlock()
+func (t *transporteWS) expirar() {
+	t.conn.SetWriteDeadline(time.Now().Add(t.espera))
+	t.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.ClosePolicyViolation, "sesión cerrada"))
 }
 
-// Broadcast envía un mensaje a todos los usuarios conectados
-func Broadcast(message interface{}) {
-	mutex.Lock()
-	defer mutex.Unlock()
-	for client := range clients {
-		err := client.WriteJSON(message)
+func (t *transporteWS) cerrar() { t.conn.Close() }
+
+func (h *Hub) nuevaConexion(cli Cliente, t transporte, tipo string) *conexion {
+	return &conexion{
+		id: idConexion(), t: t, tipo: tipo, cliente: cli,
+		autorizadas: autorizadasDe(cli), salas: salasDe(cli),
+		cola: make(chan envio, h.tamCola),
+	}
+}
+
+// abrir registra la conexión y arma lo primero que recibe: el evento CONECTADO y lo
+// que se perdió desde ?last_seen_id= (o Last-Event-ID, o su último ack). Ya
+// registrada, los avisos en vivo esperan en la cola mientras se leen los perdidos.
+func (h *Hub) abrir(r *http.Request, c *conexion) []envio {
+	h.altas <- c
+
+	hola, _ := conID(NuevoEvento(EventoConectado, Conectado{
+		Conexion: c.id, Transporte: c.tipo, Protocolo: VersionProtocolo, Salas: c.salas,
+	}), nuevoID())
+	desde := ultimoVisto(r)
+	if desde == 0 {
+		desde = h.ultimoConfirmado(r.Context(), c.cliente)
+	}
+	return append([]envio{{datos: hola}}, h.perdidos(r.Context(), c, desde)...)
+}
+
+// Serve eleva la conexión HTTP a WebSocket para un cliente ya autenticado y la
+// mantiene abierta hasta que se cierra o deja de responder a los pings
+func (h *Hub) Serve(w http.ResponseWriter, r *http.Request, cli Cliente) {
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return // Upgrade ya respondió el error al cliente
+	}
+	c := h.nuevaConexion(cli, &transporteWS{conn: conn, espera: h.esperaEscritura}, "websocket")
+	inicio := h.abrir(r, c)
+	go h.writePump(c, inicio)
+	h.readPump(c, conn)
+}
+
+// readPump detecta el cierre del otro lado, renueva el plazo con cada pong y
+// atiende los mensajes del cliente (subscribe, unsubscribe, ack)
+func (h *Hub) readPump(c *conexion, conn *websocket.Conn) {
+	defer func() {
+		h.bajas <- c
+		conn.Close()
+	}()
+	conn.SetReadLimit(maxMensajeEntrante)
+	conn.SetReadDeadline(time.Now().Add(h.esperaPong))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(h.esperaPong))
+	})
+	for {
+		_, datos, err := conn.ReadMessage()
 		if err != nil {
-			client.Close()
-			delete(clients, client)
+			return
 		}
+		var msg MensajeCliente
+		json.Unmarshal(datos, &msg) // Si no es JSON, el tipo vacío vuelve como ERROR
+		// subscribe/unsubscribe ya contestan por la cola; un ack inválido se ignora
+		h.atender(comando{c: c, cli: c.cliente, msg: msg})
 	}
 }
+
+// atender: El ack se guarda sin pasar por run; lo demás lo aplica run
+func (h *Hub) atender(cmd comando) error {
+	if cmd.msg.Tipo == MensajeConfirmar {
+		return h.confirmar(cmd.cli, cmd.msg.ID)
+	}
+	cmd.resp = make(chan error, 1)
+	h.comandos <- cmd
+	return <-cmd.resp
+}
+
+// Comando aplica un mensaje recibido por HTTP a la conexión SSE 'conexionID' del
+// cliente. Solo la encuentra la instancia que sirve ese stream.
+func (h *Hub) Comando(cli Cliente, conexionID string, msg MensajeCliente) error {
+	return h.atender(comando{id: conexionID, cli: cli, msg: msg})
+}
+
+// writePump es el único escritor del transporte: primero lo inicial (CONECTADO y
+// los eventos perdidos), luego la cola (salteando lo que ya salió en la reposición),
+// latidos periódicos y la revisión de la sesión
+func (h *Hub) writePump(c *conexion, inicio []envio) {
+	ping := time.NewTicker(h.pingCada)
+	defer func() {
+		ping.Stop()
+		c.t.cerrar()
+	}()
+
+	// La sesión solo se validó al conectarse: se vuelve a validar mientras siga abierta
+	var revisar <-chan time.Time
+	if c.cliente.Vigente != nil {
+		sesion := time.NewTicker(h.revisarSesion)
+		defer sesion.Stop()
+		revisar = sesion.C
+	}
+
+	var hasta uint64
+	for _, e := range inicio {
+		if err := c.t.escribir(e.datos); err != nil {
+			return
+		}
+		hasta = max(hasta, e.seq)
+	}
+
+	for {
+		select {
+		case e, ok := <-c.cola:
+			if !ok {
+				c.t.despedir()
+				return
+			}
+			if e.seq != 0 && e.seq <= hasta {
+				continue // Ya salió con los perdidos
+			}
+			if err := c.t.escribir(e.datos); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := c.t.latido(); err != nil {
+				return
+			}
+		case <-revisar:
+			if !c.cliente.Vigente() {
+				c.t.expirar()
+				return
+			}
+		}
+	}
+}
+
+// enviar serializa una sola vez y lo deja en cola sin esperar: a Redis si hay
+// suscripción activa (allí recibe su número de secuencia como id y lo reparten todas
+// las instancias) o directo a este hub con un id local
+func (h *Hub) enviar(sala string, ev Evento) {
+	if ev.Fecha.IsZero() {
+		ev.Fecha = time.Now().UTC()
+	}
+	datos, err := json.Marshal(ev)
+	if err != nil {
+		log.Println("❌ Mensaje WebSocket inválido:", err)
+		return
+	}
+	cola := h.publicaciones
+	if h.rdb.Load() == nil {
+		cola = h.envios
+		if datos, err = conID(json.RawMessage(datos), nuevoID()); err != nil {
+			return
+		}
+	}
+	select {
+	case cola <- envio{sala: sala, datos: datos}:
+	default:
+		h.descartados.Add(1)
+		log.Println("⚠️ Hub WebSocket saturado: se descartó un evento para", sala)
+	}
+}
+
+// repartir entrega a las conexiones de esta instancia
+func (h *Hub) repartir(e envio) {
+	select {
+	case h.envios <- e:
+	default:
+		h.descartados.Add(1)
+	}
+}
+
+// Broadcast envía un evento a todos los usuarios conectados
+func (h *Hub) Broadcast(ev Evento) { h.enviar("", ev) }
+
+// SendToUser envía a todas las conexiones de un usuario (core_usuarios.id)
+func (h *Hub) SendToUser(usuarioID string, ev Evento) {
+	if usuarioID != "" {
+		h.enviar(salaUsuario(usuarioID), ev)
+	}
+}
+
+// SendToPersona envía un evento solo a las conexiones de esa persona (todos sus dispositivos)
+func (h *Hub) SendToPersona(personaID int, ev Evento) {
+	if personaID != 0 {
+		h.enviar(salaPersona(personaID), ev)
+	}
+}
+
+// SendToCongregation envía a los conectados de una congregación
+func (h *Hub) SendToCongregation(congID string, ev Evento) {
+	if congID != "" {
+		h.enviar(salaCongregacion(congID), ev)
+	}
+}
+
+// SendToModule envía a quienes tienen permiso sobre el módulo en esa congregación
+func (h *Hub) SendToModule(congID, modulo string, ev Evento) {
+	if congID != "" && modulo != "" {
+		h.enviar(salaModulo(congID, modulo), ev)
+	}
+}
+
+// Atajos sobre el hub por defecto
+func Serve(w http.ResponseWriter, r *http.Request, cli Cliente)    { Default.Serve(w, r, cli) }
+func ServeSSE(w http.ResponseWriter, r *http.Request, cli Cliente) { Default.ServeSSE(w, r, cli) }
+func Comando(cli Cliente, conexionID string, msg MensajeCliente) error {
+	return Default.Comando(cli, conexionID, msg)
+}
+func Broadcast(ev Evento)                           { Default.Broadcast(ev) }
+func SendToUser(usuarioID string, ev Evento)        { Default.SendToUser(usuarioID, ev) }
+func SendToPersona(personaID int, ev Evento)        { Default.SendToPersona(personaID, ev) }
+func SendToCongregation(congID string, ev Evento)   { Default.SendToCongregation(congID, ev) }
+func SendToModule(congID, modulo string, ev Evento) { Default.SendToModule(congID, modulo, ev) }
@@ -0,0 +1,312 @@
+/**
+ * ARCHIVO: hub_test.go
+ * UBICACIÓN: backend/internal/ws/hub_test.go
+ * DESCRIPCIÓN: Pruebas de salas, origen permitido, reparto, heartbeats, desalojo
+ * de clientes lentos y cierre por sesión revocada, con servidores httptest y
+ * clientes WebSocket reales.
+ */
+
+package ws
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// servidorPrueba: Cada conexión se identifica con ?persona=&cong=&anciano= (en producción lo hace AuthMiddleware)
+func servidorPrueba(t *testing.T, h *Hub) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		persona, _ := strconv.Atoi(q.Get("persona"))
+		h.Serve(w, r, Cliente{PersonaID: persona, CongregacionID: q.Get("cong"), Anciano: q.Get("anciano") == "1"})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func conectar(t *testing.T, srv *httptest.Server, query string) *websocket.Conn {
+	t.Helper()
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/?"+query, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	if got := leer(conn, time.Second); got != EventoConectado {
+		t.Fatalf("El primer evento debía ser %s, llegó %q", EventoConectado, got)
+	}
+	return conn
+}
+
+// esperar reintenta la condición hasta un segundo (el registro en el hub es asíncrono)
+func esperar(t *testing.T, que string, cond func() bool) {
+	t.Helper()
+	limite := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(limite) {
+			t.Fatalf("No se cumplió a tiempo: %s", que)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// leer devuelve el tipo del próximo evento ("" si no llegó nada en el plazo)
+func leer(conn *websocket.Conn, plazo time.Duration) string {
+	conn.SetReadDeadline(time.Now().Add(plazo))
+	var ev Evento
+	if err := conn.ReadJSON(&ev); err != nil {
+		return ""
+	}
+	return ev.Tipo
+}
+
+func TestSalasDelCliente(t *testing.T) {
+	salas := salasDe(Cliente{UsuarioID: "u1", PersonaID: 7, CongregacionID: "c1", Modulos: []string{"pubs"}})
+	esperadas := []string{"usuario:u1", "persona:7", "congregacion:c1", "modulo:c1:pubs"}
+	if !reflect.DeepEqual(salas, esperadas) {
+		t.Errorf("Salas inesperadas: %v", salas)
+	}
+	// Sin congregación no hay salas de módulo (el permiso es por congregación)
+	if salas := salasDe(Cliente{UsuarioID: "u1", Modulos: []string{"pubs"}}); !reflect.DeepEqual(salas, []string{"usuario:u1"}) {
+		t.Errorf("Salas inesperadas sin congregación: %v", salas)
+	}
+}
+
+func TestOrigenPermitido(t *testing.T) {
+	t.Setenv("ALLOWED_ORIGINS", "https://app.example.org, http://localhost:5173")
+	casos := map[string]bool{
+		"https://app.example.org": true,
+		"http://localhost:5173":   true,
+		"https://evil.example":    false,
+		"":                        true, // Cliente que no es navegador
+	}
+	for origen, esperado := range casos {
+		r := httptest.NewRequest("GET", "/ws", nil)
+		if origen != "" {
+			r.Header.Set("Origin", origen)
+		}
+		if got := origenPermitido(r); got != esperado {
+			t.Errorf("Origen %q: se esperaba %v", origen, esperado)
+		}
+	}
+}
+
+func TestRepartoPorSala(t *testing.T) {
+	h := NewHub()
+	srv := servidorPrueba(t, h)
+	ana := conectar(t, srv, "persona=1&cong=c1")
+	beto := conectar(t, srv, "persona=2&cong=c1")
+	carla := conectar(t, srv, "persona=3&cong=c2")
+	esperar(t, "3 conectados", func() bool { return h.Metricas().Conectados == 3 })
+
+	h.SendToPersona(1, NuevoEvento("PERSONAL", nil))
+	h.SendToCongregation("c1", NuevoEvento("CONGREGACION", nil))
+	h.SendToCongregation("c2", NuevoEvento("OTRA", nil))
+
+	if got := leer(ana, time.Second); got != "PERSONAL" {
+		t.Errorf("Ana debía recibir primero su aviso personal, recibió %q", got)
+	}
+	if got := leer(ana, time.Second); got != "CONGREGACION" {
+		t.Errorf("Ana debía recibir el aviso de su congregación, recibió %q", got)
+	}
+	if got := leer(beto, time.Second); got != "CONGREGACION" {
+		t.Errorf("Beto no debía recibir el aviso personal de Ana, recibió %q", got)
+	}
+	if got := leer(carla, time.Second); got != "OTRA" {
+		t.Errorf("Carla solo debía recibir lo de su congregación, recibió %q", got)
+	}
+
+	h.Broadcast(NuevoEvento("TODOS", nil))
+	for _, c := range []*websocket.Conn{ana, beto, carla} {
+		if got := leer(c, time.Second); got != "TODOS" {
+			t.Errorf("El broadcast debía llegar a todos, llegó %q", got)
+		}
+	}
+	if m := h.Metricas(); m.Enviados != 7 || m.Salas != 5 {
+		t.Errorf("Métricas inesperadas: %+v", m)
+	}
+
+	carla.Close()
+	esperar(t, "baja al cerrar", func() bool { return h.Metricas().Conectados == 2 })
+}
+
+func TestHeartbeat(t *testing.T) {
+	h := nuevoHub(20*time.Millisecond, 100*time.Millisecond, time.Second, tamColaCliente)
+	srv := servidorPrueba(t, h)
+
+	// Un cliente que lee responde los pings solo (gorilla contesta con pong) y sigue conectado
+	vivo := conectar(t, srv, "persona=1")
+	var pings atomic.Int32
+	vivo.SetPingHandler(func(datos string) error {
+		pings.Add(1)
+		return vivo.WriteControl(websocket.PongMessage, []byte(datos), time.Now().Add(time.Second))
+	})
+	go func() {
+		for {
+			if _, _, err := vivo.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	// Uno que nunca lee tampoco contesta los pings: vence la espera del pong y se lo da de baja
+	conectar(t, srv, "persona=2")
+	esperar(t, "2 conectados", func() bool { return h.Metricas().Conectados == 2 })
+	esperar(t, "baja del cliente mudo", func() bool { return h.Metricas().Conectados == 1 })
+
+	time.Sleep(200 * time.Millisecond)
+	if pings.Load() < 3 || h.Metricas().Conectados != 1 {
+		t.Errorf("El cliente que responde debía seguir conectado (pings: %d, conectados: %d)", pings.Load(), h.Metricas().Conectados)
+	}
+}
+
+func TestDesalojoDeClienteLento(t *testing.T) {
+	h := nuevoHub(time.Hour, time.Hour, time.Second, 1)
+
+	// Conexión sin writePump: nadie vacía su cola
+	lento := &conexion{salas: salasDe(Cliente{PersonaID: 9, CongregacionID: "c1"}), cola: make(chan envio, 1)}
+	h.altas <- lento
+	srv := servidorPrueba(t, h)
+	rapido := conectar(t, srv, "persona=1&cong=c1")
+	esperar(t, "2 conectados", func() bool { return h.Metricas().Conectados == 2 })
+
+	inicio := time.Now()
+	for i := 0; i < 3; i++ {
+		h.SendToCongregation("c1", NuevoEvento("AVISO_"+strconv.Itoa(i), nil))
+		if got := leer(rapido, time.Second); got != "AVISO_"+strconv.Itoa(i) {
+			t.Fatalf("El cliente rápido no debía verse afectado, recibió %q", got)
+		}
+	}
+	if time.Since(inicio) > 500*time.Millisecond {
+		t.Errorf("Emitir no debía esperar al cliente lento")
+	}
+
+	esperar(t, "desalojo", func() bool { return h.Metricas().Desalojados == 1 })
+	if _, abierta := <-lento.cola; !abierta {
+		t.Fatalf("La cola debía conservar el mensaje que alcanzó a entrar")
+	}
+	if _, abierta := <-lento.cola; abierta {
+		t.Errorf("La cola del cliente desalojado debía quedar cerrada")
+	}
+	if m := h.Metricas(); m.Conectados != 1 {
+		t.Errorf("Solo debía quedar el cliente rápido: %+v", m)
+	}
+}
+
+func TestMensajeLlegaComoSobre(t *testing.T) {
+	h := NewHub()
+	srv := servidorPrueba(t, h)
+	a := conectar(t, srv, "persona=1")
+	esperar(t, "conectado", func() bool { return h.Metricas().Conectados == 1 })
+
+	h.SendToPersona(1, NuevoEvento(EventoLiteraturaLista, LiteraturaLista{PedidoID: 5, Publicacion: "Biblia", Cantidad: 2}))
+	a.SetReadDeadline(time.Now().Add(time.Second))
+	_, datos, err := a.ReadMessage()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m struct {
+		Tipo    string          `json:"type"`
+		Version int             `json:"version"`
+		ID      string          `json:"id"`
+		Fecha   time.Time       `json:"timestamp"`
+		Datos   LiteraturaLista `json:"payload"`
+	}
+	if err := json.Unmarshal(datos, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Tipo != EventoLiteraturaLista || m.Version != 1 || m.ID == "" || m.Fecha.IsZero() || m.Datos.Cantidad != 2 {
+		t.Errorf("Sobre inesperado: %s", datos)
+	}
+}
+
+func TestSuscripcionDelCliente(t *testing.T) {
+	h := NewHub()
+	srv := servidorPrueba(t, h)
+	a := conectar(t, srv, "persona=1&cong=c1")
+	esperar(t, "conectado", func() bool { return h.Metricas().Conectados == 1 })
+
+	respuesta := func() Suscripcion {
+		t.Helper()
+		var ev struct {
+			Tipo  string      `json:"type"`
+			Datos Suscripcion `json:"payload"`
+		}
+		a.SetReadDeadline(time.Now().Add(time.Second))
+		if err := a.ReadJSON(&ev); err != nil || ev.Tipo != EventoSuscripcion {
+			t.Fatalf("Se esperaba %s: %v %v", EventoSuscripcion, ev.Tipo, err)
+		}
+		return ev.Datos
+	}
+
+	// Sale de la sala de la congregación: deja de recibir sus avisos, no los personales
+	a.WriteJSON(MensajeCliente{Tipo: MensajeDesuscribir, Salas: []string{"congregacion:c1"}})
+	if s := respuesta(); !reflect.DeepEqual(s.Salas, []string{"persona:1"}) {
+		t.Errorf("Salas inesperadas: %v", s.Salas)
+	}
+	h.SendToCongregation("c1", NuevoEvento("CONGREGACION", nil))
+	h.SendToPersona(1, NuevoEvento("PERSONAL", nil))
+	if got := leer(a, time.Second); got != "PERSONAL" {
+		t.Errorf("Fuera de la sala no debía llegar el aviso de la congregación, llegó %q", got)
+	}
+
+	// Puede volver a su sala, pero no entrar a la de otra congregación
+	a.WriteJSON(MensajeCliente{Tipo: MensajeSuscribir, Salas: []string{"congregacion:c1", "congregacion:c2"}})
+	if s := respuesta(); len(s.Salas) != 2 || !reflect.DeepEqual(s.Rechazadas, []string{"congregacion:c2"}) {
+		t.Errorf("Suscripción inesperada: %+v", s)
+	}
+	h.SendToCongregation("c2", NuevoEvento("AJENO", nil))
+	h.SendToCongregation("c1", NuevoEvento("CONGREGACION", nil))
+	if got := leer(a, time.Second); got != "CONGREGACION" {
+		t.Errorf("De vuelta en su sala debía recibir solo lo suyo, llegó %q", got)
+	}
+
+	a.WriteMessage(websocket.TextMessage, []byte("no es json"))
+	if got := leer(a, time.Second); got != EventoError {
+		t.Errorf("Un mensaje inválido debía responderse con %s, llegó %q", EventoError, got)
+	}
+}
+
+func TestSesionRevocadaCierraConexiones(t *testing.T) {
+	h := NewHub()
+	h.revisarSesion = 20 * time.Millisecond
+	var vigente atomic.Bool
+	vigente.Store(true)
+	cli := Cliente{PersonaID: 1, Vigente: vigente.Load}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("sse") == "1" {
+			h.ServeSSE(w, r, cli)
+			return
+		}
+		h.Serve(w, r, cli)
+	}))
+	t.Cleanup(srv.Close)
+	conn := conectar(t, srv, "")
+	abrirSSE(t, srv, "sse=1", "")
+	esperar(t, "2 conectados", func() bool { return h.Metricas().Conectados == 2 })
+
+	// Mientras la sesión es válida, las revisiones no cortan nada
+	time.Sleep(100 * time.Millisecond)
+	if h.Metricas().Conectados != 2 {
+		t.Fatalf("Una sesión vigente no debía desconectarse: %+v", h.Metricas())
+	}
+
+	// Revocada (o suspendida la cuenta), ambas conexiones se cierran en la próxima revisión
+	vigente.Store(false)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	_, _, err := conn.ReadMessage()
+	if !websocket.IsCloseError(err, websocket.ClosePolicyViolation) {
+		t.Errorf("El WebSocket debía cerrarse por sesión inválida: %v", err)
+	}
+	esperar(t, "baja de ambas conexiones", func() bool { return h.Metricas().Conectados == 0 })
+}
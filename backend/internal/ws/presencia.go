@@ -0,0 +1,215 @@
+/**
+ * ARCHIVO: presencia.go
+ * UBICACIÓN: internal/ws/presencia.go
+ * DESCRIPCIÓN: Presencia en las reuniones. Cada congregación tiene su sala
+ * "reunion:<congregación>": sus miembros pueden entrar con subscribe (no se entra
+ * solo al conectarse) y el hub cuenta personas distintas, no dispositivos. Cada
+ * cambio avisa el total a la sala "ancianos:<congregación>". Con Redis la cuenta es
+ * de todas las instancias (ws_presencia:<sala>, un ZSET persona → vencimiento que
+ * cada instancia renueva): si una instancia se cae, sus presentes vencen solos, y
+ * quien tiene dispositivos en dos instancias puede faltar hasta la próxima renovación.
+ */
+
+package ws
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	prefijoReunion   = "reunion:"
+	prefijoPresencia = "ws_presencia:"
+	// Una persona sigue presente mientras su instancia la renueve dentro de este plazo
+	vigenciaPresencia = 2 * time.Minute
+	renovarPresencia  = vigenciaPresencia / 4
+	// Cambios de presencia pendientes de registrar; si se llena, la renovación corrige
+	tamColaPresencia = 256
+)
+
+func salaReunion(congID string) string  { return prefijoReunion + congID }
+func salaAncianos(congID string) string { return "ancianos:" + congID }
+
+// cambioPresencia: Una persona entró a (o salió de) la sala de reunión en esta instancia
+type cambioPresencia struct {
+	sala      string
+	personaID int
+	presente  bool
+}
+
+// presencia: Conexiones de cada persona en cada sala de reunión de esta instancia
+type presencia struct {
+	mu    sync.Mutex
+	salas map[string]map[int]int
+}
+
+// entrar suma una conexión; true si es la primera de esa persona en la sala
+func (p *presencia) entrar(sala string, personaID int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.salas == nil {
+		p.salas = make(map[string]map[int]int)
+	}
+	if p.salas[sala] == nil {
+		p.salas[sala] = make(map[int]int)
+	}
+	p.salas[sala][personaID]++
+	return p.salas[sala][personaID] == 1
+}
+
+// salir resta una conexión; true si era la última de esa persona en la sala
+func (p *presencia) salir(sala string, personaID int) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n, ok := p.salas[sala][personaID]
+	if !ok {
+		return false
+	}
+	if n > 1 {
+		p.salas[sala][personaID] = n - 1
+		return false
+	}
+	delete(p.salas[sala], personaID)
+	if len(p.salas[sala]) == 0 {
+		delete(p.salas, sala)
+	}
+	return true
+}
+
+// personas: Presentes en la sala en esta instancia
+func (p *presencia) personas(sala string) []int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	lista := make([]int, 0, len(p.salas[sala]))
+	for id := range p.salas[sala] {
+		lista = append(lista, id)
+	}
+	return lista
+}
+
+// todas: Copia de las salas con sus presentes (para renovar en Redis)
+func (p *presencia) todas() map[string][]int {
+	p.mu.Lock()
+	salas := make([]string, 0, len(p.salas))
+	for s := range p.salas {
+		salas = append(salas, s)
+	}
+	p.mu.Unlock()
+
+	copia := make(map[string][]int, len(salas))
+	for _, s := range salas {
+		copia[s] = p.personas(s)
+	}
+	return copia
+}
+
+// registrarPresencia lo llama run al unir o separar una conexión de una sala
+func (h *Hub) registrarPresencia(c *conexion, sala string, presente bool) {
+	if !strings.HasPrefix(sala, prefijoReunion) || c.cliente.PersonaID == 0 {
+		return
+	}
+	cambio := h.pres.salir
+	if presente {
+		cambio = h.pres.entrar
+	}
+	if !cambio(sala, c.cliente.PersonaID) {
+		return // Otro dispositivo de la misma persona ya la tenía (o la sigue teniendo) presente
+	}
+	select {
+	case h.presencias <- cambioPresencia{sala: sala, personaID: c.cliente.PersonaID, presente: presente}:
+	default:
+		log.Println("⚠️ Cola de presencia llena: se corrige en la próxima renovación")
+	}
+}
+
+// seguirPresencia registra en Redis cada cambio, avisa el nuevo total a los ancianos
+// y renueva periódicamente a los presentes de esta instancia
+func (h *Hub) seguirPresencia() {
+	renovar := time.NewTicker(h.renovarPresencia)
+	defer renovar.Stop()
+	for {
+		select {
+		case cp := <-h.presencias:
+			ctx, cancel := context.WithTimeout(context.Background(), esperaPublicacion)
+			if rdb := h.rdb.Load(); rdb != nil {
+				var err error
+				if cp.presente {
+					err = rdb.ZAdd(ctx, prefijoPresencia+cp.sala, redis.Z{Score: h.vencimientoPresencia(), Member: cp.personaID}).Err()
+				} else {
+					err = rdb.ZRem(ctx, prefijoPresencia+cp.sala, cp.personaID).Err()
+				}
+				if err != nil {
+					log.Println("⚠️ No se pudo registrar la presencia en Redis:", err)
+				}
+			}
+			h.avisarTotal(ctx, strings.TrimPrefix(cp.sala, prefijoReunion))
+			cancel()
+
+		case <-renovar.C:
+			rdb := h.rdb.Load()
+			if rdb == nil {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), esperaPublicacion)
+			vence := h.vencimientoPresencia()
+			for sala, personas := range h.pres.todas() {
+				miembros := make([]redis.Z, len(personas))
+				for i, id := range personas {
+					miembros[i] = redis.Z{Score: vence, Member: id}
+				}
+				clave := prefijoPresencia + sala
+				rdb.ZAdd(ctx, clave, miembros...)
+				rdb.ZRemRangeByScore(ctx, clave, "-inf", strconv.FormatInt(time.Now().UnixMilli(), 10))
+				rdb.Expire(ctx, clave, 2*h.vigenciaPresencia)
+			}
+			cancel()
+		}
+	}
+}
+
+func (h *Hub) vencimientoPresencia() float64 {
+	return float64(time.Now().Add(h.vigenciaPresencia).UnixMilli())
+}
+
+// avisarTotal manda a los ancianos de la congregación cuántos hay en su reunión
+func (h *Hub) avisarTotal(ctx context.Context, congID string) {
+	h.enviar(salaAncianos(congID), NuevoEvento(EventoPresencia, Presencia{
+		CongregacionID: congID,
+		Presentes:      len(h.Presentes(ctx, congID)),
+	}))
+}
+
+// Presentes devuelve las personas (ordenadas) que están en la sala de reunión de la
+// congregación: de todas las instancias con Redis, de esta sin él o si Redis falla
+func (h *Hub) Presentes(ctx context.Context, congID string) []int {
+	sala := salaReunion(congID)
+	if rdb := h.rdb.Load(); rdb != nil {
+		ids, err := rdb.ZRangeByScore(ctx, prefijoPresencia+sala, &redis.ZRangeBy{
+			Min: strconv.FormatInt(time.Now().UnixMilli(), 10), Max: "+inf",
+		}).Result()
+		if err == nil {
+			lista := make([]int, 0, len(ids))
+			for _, s := range ids {
+				if id, err := strconv.Atoi(s); err == nil {
+					lista = append(lista, id)
+				}
+			}
+			sort.Ints(lista)
+			return lista
+		}
+		log.Println("⚠️ Presencia sin Redis, cuenta local:", err)
+	}
+	lista := h.pres.personas(sala)
+	sort.Ints(lista)
+	return lista
+}
+
+// Presentes consulta el hub por defecto (ver Hub.Presentes)
+func Presentes(ctx context.Context, congID string) []int { return Default.Presentes(ctx, congID) }
@@ -0,0 +1,111 @@
+/**
+ * ARCHIVO: presencia_test.go
+ * UBICACIÓN: backend/internal/ws/presencia_test.go
+ * DESCRIPCIÓN: Presencia en la sala de reunión: personas distintas (no dispositivos),
+ * aviso del total a los ancianos y cuenta entre instancias con Redis.
+ */
+
+package ws
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gorilla/websocket"
+)
+
+// entrarReunion suscribe la conexión a la sala de reunión y consume la respuesta
+func entrarReunion(t *testing.T, c *websocket.Conn, congID string) {
+	t.Helper()
+	c.WriteJSON(MensajeCliente{Tipo: MensajeSuscribir, Salas: []string{SalaReunion(congID)}})
+	if got := leer(c, time.Second); got != EventoSuscripcion {
+		t.Fatalf("Se esperaba %s, llegó %q", EventoSuscripcion, got)
+	}
+}
+
+// leerPresentes devuelve el total del próximo evento PRESENCIA
+func leerPresentes(t *testing.T, c *websocket.Conn) int {
+	t.Helper()
+	var ev struct {
+		Tipo  string    `json:"type"`
+		Datos Presencia `json:"payload"`
+	}
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	if err := c.ReadJSON(&ev); err != nil || ev.Tipo != EventoPresencia {
+		t.Fatalf("Se esperaba %s: %q %v", EventoPresencia, ev.Tipo, err)
+	}
+	return ev.Datos.Presentes
+}
+
+func TestPresenciaEnReunion(t *testing.T) {
+	h := NewHub()
+	srv := servidorPrueba(t, h)
+	anciano := conectar(t, srv, "persona=9&cong=c1&anciano=1")
+	celular := conectar(t, srv, "persona=1&cong=c1")
+	tablet := conectar(t, srv, "persona=1&cong=c1")
+	otro := conectar(t, srv, "persona=2&cong=c1")
+	esperar(t, "4 conectados", func() bool { return h.Metricas().Conectados == 4 })
+
+	// Conectarse no es estar presente: hay que entrar a la sala
+	if p := h.Presentes(context.Background(), "c1"); len(p) != 0 {
+		t.Fatalf("Nadie debía estar presente todavía: %v", p)
+	}
+
+	entrarReunion(t, celular, "c1")
+	if n := leerPresentes(t, anciano); n != 1 {
+		t.Errorf("Debía haber 1 presente, hay %d", n)
+	}
+	entrarReunion(t, tablet, "c1") // Misma persona: no cambia el total ni se avisa
+	entrarReunion(t, otro, "c1")
+	if n := leerPresentes(t, anciano); n != 2 {
+		t.Errorf("Dos dispositivos de la misma persona cuentan una vez: %d", n)
+	}
+	if p := h.Presentes(context.Background(), "c1"); !reflect.DeepEqual(p, []int{1, 2}) {
+		t.Errorf("Presentes inesperados: %v", p)
+	}
+
+	// Cerrar uno de sus dispositivos no la saca; irse de la sala sí
+	celular.Close()
+	otro.WriteJSON(MensajeCliente{Tipo: MensajeDesuscribir, Salas: []string{SalaReunion("c1")}})
+	if got := leer(otro, time.Second); got != EventoSuscripcion {
+		t.Fatalf("Se esperaba %s, llegó %q", EventoSuscripcion, got)
+	}
+	if n := leerPresentes(t, anciano); n != 1 {
+		t.Errorf("Debía quedar 1 presente, hay %d", n)
+	}
+	tablet.Close()
+	if n := leerPresentes(t, anciano); n != 0 {
+		t.Errorf("Sin conexiones no queda nadie presente: %d", n)
+	}
+
+	// Solo se entra a la sala de la propia congregación, y los miembros no reciben el total
+	otro.WriteJSON(MensajeCliente{Tipo: MensajeSuscribir, Salas: []string{SalaReunion("c2")}})
+	if got := leer(otro, time.Second); got != EventoSuscripcion {
+		t.Fatalf("Se esperaba %s, llegó %q", EventoSuscripcion, got)
+	}
+	if p := h.Presentes(context.Background(), "c2"); len(p) != 0 {
+		t.Errorf("No debía poder entrar a otra congregación: %v", p)
+	}
+}
+
+func TestPresenciaEntreInstancias(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, b := NewHub(), NewHub()
+	conRedis(t, a, mr)
+	conRedis(t, b, mr)
+
+	entrarReunion(t, conectar(t, servidorPrueba(t, a), "persona=1&cong=c1"), "c1")
+	entrarReunion(t, conectar(t, servidorPrueba(t, b), "persona=2&cong=c1"), "c1")
+	esperar(t, "presentes de ambas instancias", func() bool {
+		return reflect.DeepEqual(a.Presentes(context.Background(), "c1"), []int{1, 2})
+	})
+
+	// Quien quedó de una instancia caída (sin renovar) ya venció y no cuenta
+	mr.ZAdd(prefijoPresencia+SalaReunion("c1"), float64(time.Now().Add(-time.Minute).UnixMilli()), "3")
+	if p := b.Presentes(context.Background(), "c1"); !reflect.DeepEqual(p, []int{1, 2}) {
+		t.Errorf("Un presente vencido no debía contarse: %v", p)
+	}
+}
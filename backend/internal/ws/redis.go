@@ -0,0 +1,316 @@
+/**
+ * ARCHIVO: redis.go
+ * UBICACIÓN: internal/ws/redis.go
+ * DESCRIPCIÓN: Reparto entre instancias. Cada sala publica en su canal de Redis
+ * (ws:<sala>; el broadcast en ws:todos) y cada instancia, suscrita a ws:*, entrega a
+ * sus propias conexiones. Cada evento lleva un "id" único para que el cliente
+ * descarte duplicados (por ejemplo, tras reconectarse a otra instancia).
+ * Con Redis ese id es un número de secuencia creciente (ws_seq) y el evento queda
+ * además en un stream acotado por sala (ws_eventos:<sala>); al reconectarse con
+ * ?last_seen_id=<n> el cliente recibe primero lo que se perdió y luego lo nuevo.
+ * El último id confirmado con ack (ws_ack:<usuario>) sirve de punto de partida
+ * cuando el cliente no indica desde dónde seguir.
+ */
+
+package ws
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	prefijoCanal = "ws:"
+	canalTodos   = prefijoCanal + "todos"
+	// Plazo de cada PUBLISH antes de repartir solo en esta instancia
+	esperaPublicacion = 2 * time.Second
+
+	claveSecuencia = "ws_seq"
+	prefijoEventos = "ws_eventos:"
+	prefijoAck     = "ws_ack:"
+	// Eventos que se guardan por sala (y tope de la reposición por sala)
+	maxEventosSala = 200
+	// Una sala sin eventos nuevos en este plazo pierde su historial
+	retencionEventos = 7 * 24 * time.Hour
+	// Plazo para leer los eventos perdidos antes de seguir solo con los en vivo
+	esperaReposicion = 2 * time.Second
+	// Espera antes de volver a suscribirse si Redis no responde (se duplica hasta el tope)
+	reintentoRedis    = time.Second
+	maxReintentoRedis = time.Minute
+)
+
+// errSuscripcionCerrada: go-redis cerró el canal de la suscripción
+var errSuscripcionCerrada = errors.New("la suscripción a Redis se cerró")
+
+// registrarEvento numera el evento y lo agrega al stream de su sala en un solo paso
+// (el número es también el id de la entrada, así XRANGE lee "desde n" directamente)
+var registrarEvento = redis.NewScript(`
+local n = redis.call('INCR', KEYS[1])
+redis.call('XADD', KEYS[2], 'MAXLEN', ARGV[2], n .. '-0', 'datos', ARGV[1])
+redis.call('EXPIRE', KEYS[2], ARGV[3])
+return n
+`)
+
+// guardarAck solo avanza: un ack viejo que llega tarde no hace retroceder la reposición
+var guardarAck = redis.NewScript(`
+local v = tonumber(redis.call('GET', KEYS[1]) or '0')
+if tonumber(ARGV[1]) > v then
+	redis.call('SET', KEYS[1], ARGV[1], 'EX', ARGV[2])
+end
+return 1
+`)
+
+// instancia: Prefijo aleatorio de los ids de este proceso; el contador los hace únicos
+var (
+	instancia = func() string {
+		b := make([]byte, 6)
+		rand.Read(b)
+		return hex.EncodeToString(b)
+	}()
+	secuencia atomic.Uint64
+)
+
+// nuevoID: Único entre instancias ("<instancia>-<n>")
+func nuevoID() string {
+	return instancia + "-" + strconv.FormatUint(secuencia.Add(1), 10)
+}
+
+// conID serializa el mensaje agregándole el "id" (si ya trae uno, se respeta).
+// Lo que no es un objeto JSON viaja como {"id": ..., "datos": ...}.
+func conID(message interface{}, id string) ([]byte, error) {
+	datos, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	var obj map[string]json.RawMessage
+	if err := json.Unmarshal(datos, &obj); err != nil || obj == nil {
+		return json.Marshal(map[string]interface{}{"id": id, "datos": json.RawMessage(datos)})
+	}
+	if _, ok := obj["id"]; !ok {
+		obj["id"], _ = json.Marshal(id)
+	}
+	return json.Marshal(obj)
+}
+
+func canalDe(sala string) string {
+	if sala == "" {
+		return canalTodos
+	}
+	return prefijoCanal + sala
+}
+
+func salaDeCanal(canal string) string {
+	if canal == canalTodos {
+		return ""
+	}
+	return strings.TrimPrefix(canal, prefijoCanal)
+}
+
+// eventosDe: Stream con el historial de la sala ("" = broadcast)
+func eventosDe(sala string) string {
+	if sala == "" {
+		return prefijoEventos + "todos"
+	}
+	return prefijoEventos + sala
+}
+
+// idDe lee el "id" de un evento ya serializado
+func idDe(datos []byte) string {
+	var ev struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(datos, &ev)
+	return ev.ID
+}
+
+// secuenciaDe lee el número de un evento ya publicado (0 si su id no es numérico)
+func secuenciaDe(datos []byte) uint64 {
+	n, _ := strconv.ParseUint(idDe(datos), 10, 64)
+	return n
+}
+
+// ultimoVisto: ?last_seen_id= con el que se reconecta el cliente, o la cabecera
+// Last-Event-ID que manda EventSource al reconectarse (0 = no lo indicó)
+func ultimoVisto(r *http.Request) uint64 {
+	id := r.URL.Query().Get("last_seen_id")
+	if id == "" {
+		id = r.Header.Get("Last-Event-ID")
+	}
+	n, _ := strconv.ParseUint(id, 10, 64)
+	return n
+}
+
+// claveAck: Un punto de confirmación por usuario (o por persona si no hay usuario)
+func claveAck(cli Cliente) string {
+	if cli.UsuarioID != "" {
+		return prefijoAck + salaUsuario(cli.UsuarioID)
+	}
+	return prefijoAck + salaPersona(cli.PersonaID)
+}
+
+// confirmar guarda el último evento que el cliente dice haber procesado. Los ids
+// locales (sin Redis) no son reponibles y se ignoran.
+func (h *Hub) confirmar(cli Cliente, id string) error {
+	n, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || n == 0 {
+		return ErrMensajeInvalido
+	}
+	rdb := h.rdb.Load()
+	if rdb == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), esperaPublicacion)
+	defer cancel()
+	return guardarAck.Run(ctx, rdb, []string{claveAck(cli)}, n, int(retencionEventos.Seconds())).Err()
+}
+
+// ultimoConfirmado: Último ack del cliente (0 si nunca confirmó o no hay Redis)
+func (h *Hub) ultimoConfirmado(ctx context.Context, cli Cliente) uint64 {
+	rdb := h.rdb.Load()
+	if rdb == nil {
+		return 0
+	}
+	ctx, cancel := context.WithTimeout(ctx, esperaReposicion)
+	defer cancel()
+	n, _ := rdb.Get(ctx, claveAck(cli)).Uint64()
+	return n
+}
+
+// UsarRedis mantiene el hub suscrito a ws:* y, mientras la suscripción está activa,
+// publica por Redis. Bloquea hasta que se cancele el contexto. Si Redis no responde
+// (al arrancar o más tarde) el hub reparte solo en esta instancia y vuelve a
+// intentarlo con una espera que se duplica hasta maxReintentoRedis.
+func (h *Hub) UsarRedis(ctx context.Context, rdb *redis.Client) error {
+	espera := h.reintentoRedis
+	for {
+		conectada, err := h.suscribir(ctx, rdb)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if conectada {
+			espera = h.reintentoRedis // Se cortó una suscripción que funcionaba: reintento rápido
+		}
+		log.Printf("❌ WebSocket sin Redis, reparto solo local (reintento en %s): %v", espera, err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(espera):
+		}
+		espera = min(espera*2, h.maxReintentoRedis)
+	}
+}
+
+// suscribir hace un intento: se suscribe y, una vez confirmado, publica por Redis
+// hasta que se cancele el contexto o se cierre la suscripción. 'conectada' indica si
+// la suscripción llegó a confirmarse.
+func (h *Hub) suscribir(ctx context.Context, rdb *redis.Client) (conectada bool, err error) {
+	ps := rdb.PSubscribe(ctx, prefijoCanal+"*")
+	defer ps.Close()
+	if _, err := ps.Receive(ctx); err != nil {
+		return false, err
+	}
+
+	// El publicador vive lo que esta suscripción: el próximo intento arranca el suyo
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go h.publicar(ctx, rdb)
+	h.rdb.Store(rdb)
+	defer h.rdb.Store(nil)
+	log.Println("✅ WebSocket suscrito a Redis")
+
+	// go-redis reconecta la suscripción solo; el canal se cierra al cerrar ps
+	mensajes := ps.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return true, ctx.Err()
+		case m, ok := <-mensajes:
+			if !ok {
+				return true, errSuscripcionCerrada
+			}
+			datos := []byte(m.Payload)
+			h.repartir(envio{sala: salaDeCanal(m.Channel), seq: secuenciaDe(datos), datos: datos})
+		}
+	}
+}
+
+// publicar saca los envíos de la cola, los numera y guarda en el historial de su
+// sala y los publica; si Redis falla, reparte local con un id de esta instancia
+func (h *Hub) publicar(ctx context.Context, rdb *redis.Client) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-h.publicaciones:
+			pctx, cancel := context.WithTimeout(ctx, esperaPublicacion)
+			err := h.publicarEvento(pctx, rdb, e)
+			cancel()
+			if err != nil {
+				log.Println("⚠️ No se pudo publicar en Redis, reparto local:", err)
+				if e.datos, err = conID(json.RawMessage(e.datos), nuevoID()); err == nil {
+					h.repartir(e)
+				}
+			}
+		}
+	}
+}
+
+func (h *Hub) publicarEvento(ctx context.Context, rdb *redis.Client, e envio) error {
+	n, err := registrarEvento.Run(ctx, rdb, []string{claveSecuencia, eventosDe(e.sala)},
+		e.datos, h.maxEventos, int(retencionEventos.Seconds())).Uint64()
+	if err != nil {
+		return err
+	}
+	datos, err := conID(json.RawMessage(e.datos), strconv.FormatUint(n, 10))
+	if err != nil {
+		return err
+	}
+	return rdb.Publish(ctx, canalDe(e.sala), datos).Err()
+}
+
+// perdidos lee del historial de las salas de la conexión (y del broadcast) los
+// eventos posteriores a 'desde', en orden. Sin Redis o sin 'desde' no hay reposición.
+func (h *Hub) perdidos(ctx context.Context, c *conexion, desde uint64) []envio {
+	rdb := h.rdb.Load()
+	if rdb == nil || desde == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, esperaReposicion)
+	defer cancel()
+
+	var lista []envio
+	inicio := strconv.FormatUint(desde+1, 10)
+	for _, sala := range append([]string{""}, c.salas...) {
+		entradas, err := rdb.XRangeN(ctx, eventosDe(sala), inicio, "+", int64(h.maxEventos)).Result()
+		if err != nil {
+			log.Println("⚠️ No se pudieron reponer los eventos de", eventosDe(sala), err)
+			continue
+		}
+		for _, en := range entradas {
+			raw, _ := en.Values["datos"].(string)
+			n, _ := strconv.ParseUint(strings.TrimSuffix(en.ID, "-0"), 10, 64)
+			datos, err := conID(json.RawMessage(raw), strconv.FormatUint(n, 10))
+			if err != nil {
+				continue
+			}
+			lista = append(lista, envio{sala: sala, seq: n, datos: datos})
+		}
+	}
+	sort.Slice(lista, func(i, j int) bool { return lista[i].seq < lista[j].seq })
+	return lista
+}
+
+// UsarRedis conecta el hub por defecto (ver Hub.UsarRedis)
+func UsarRedis(ctx context.Context, rdb *redis.Client) error { return Default.UsarRedis(ctx, rdb) }
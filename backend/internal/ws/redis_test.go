@@ -0,0 +1,240 @@
+/**
+ * ARCHIVO: redis_test.go
+ * UBICACIÓN: backend/internal/ws/redis_test.go
+ * DESCRIPCIÓN: Reparto entre dos instancias del hub a través de Redis (miniredis),
+ * reposición de eventos perdidos al reconectarse, resuscripción cuando Redis vuelve
+ * y formato de los ids de evento.
+ */
+
+package ws
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gorilla/websocket"
+	"github.com/redis/go-redis/v9"
+)
+
+// conRedis conecta el hub a Redis y espera a que la suscripción esté activa
+func conRedis(t *testing.T, h *Hub, mr *miniredis.Miniredis) {
+	t.Helper()
+	// RESP2: miniredis no confirma PSUBSCRIBE por RESP3 (Redis real sí)
+	rdb := redis.NewClient(&redis.Options{Addr: mr.Addr(), Protocol: 2})
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		rdb.Close()
+	})
+	go h.UsarRedis(ctx, rdb)
+	esperar(t, "suscripción a Redis", func() bool { return h.rdb.Load() != nil })
+}
+
+func TestRepartoEntreInstancias(t *testing.T) {
+	mr := miniredis.RunT(t)
+	a, b := NewHub(), NewHub()
+	conRedis(t, a, mr)
+	conRedis(t, b, mr)
+
+	enA := conectar(t, servidorPrueba(t, a), "persona=1&cong=c1")
+	enB := conectar(t, servidorPrueba(t, b), "persona=2&cong=c1")
+	esperar(t, "conectados en ambas instancias", func() bool {
+		return a.Metricas().Conectados == 1 && b.Metricas().Conectados == 1
+	})
+
+	// Se emite en la instancia A; el destinatario está conectado a la B
+	a.SendToPersona(2, NuevoEvento("PERSONAL", nil))
+	if got := leer(enB, time.Second); got != "PERSONAL" {
+		t.Fatalf("El aviso debía cruzar de instancia, llegó %q", got)
+	}
+
+	// El broadcast llega una sola vez a cada uno, con el mismo id en ambas instancias
+	b.Broadcast(NuevoEvento("TODOS", nil))
+	tipo, id1 := leerID(t, enA)
+	_, id2 := leerID(t, enB)
+	if tipo != "TODOS" || id1 == "" || id1 != id2 {
+		t.Errorf("El mismo evento debía llevar el mismo id: %s / %s", id1, id2)
+	}
+	if got := leer(enA, 100*time.Millisecond); got != "" {
+		t.Errorf("No debía haber duplicados, llegó %q", got)
+	}
+}
+
+func TestSinRedisRepartoLocal(t *testing.T) {
+	mr := miniredis.RunT(t)
+	h := NewHub()
+	conRedis(t, h, mr)
+	c := conectar(t, servidorPrueba(t, h), "persona=1")
+	esperar(t, "conectado", func() bool { return h.Metricas().Conectados == 1 })
+
+	// Redis se cae después de suscribirse: el aviso igual llega a los conectados locales
+	mr.Close()
+	h.SendToPersona(1, NuevoEvento("SIN_REDIS", nil))
+	if got := leer(c, 5*time.Second); got != "SIN_REDIS" {
+		t.Errorf("Sin Redis debía repartirse en la instancia, llegó %q", got)
+	}
+}
+
+// leerID devuelve tipo e id del próximo evento
+func leerID(t *testing.T, c *websocket.Conn) (string, string) {
+	t.Helper()
+	var ev Evento
+	c.SetReadDeadline(time.Now().Add(time.Second))
+	if err := c.ReadJSON(&ev); err != nil {
+		t.Fatal(err)
+	}
+	return ev.Tipo, ev.ID
+}
+
+func TestReposicionAlReconectar(t *testing.T) {
+	mr := miniredis.RunT(t)
+	h := NewHub()
+	conRedis(t, h, mr)
+	srv := servidorPrueba(t, h)
+
+	c := conectar(t, srv, "persona=1&cong=c1")
+	esperar(t, "conectado", func() bool { return h.Metricas().Conectados == 1 })
+	h.SendToPersona(1, NuevoEvento("VISTO", nil))
+	_, ultimo := leerID(t, c)
+	c.Close()
+	esperar(t, "desconectado", func() bool { return h.Metricas().Conectados == 0 })
+
+	// Mientras estuvo desconectado: uno suyo, uno de la congregación, uno general y uno ajeno
+	h.SendToPersona(1, NuevoEvento("P1", nil))
+	h.SendToCongregation("c1", NuevoEvento("C1", nil))
+	h.Broadcast(NuevoEvento("TODOS", nil))
+	h.SendToPersona(2, NuevoEvento("AJENO", nil))
+	esperar(t, "eventos guardados", func() bool {
+		n, _ := mr.Get(claveSecuencia)
+		return n == "5"
+	})
+
+	c = conectar(t, srv, "persona=1&cong=c1&last_seen_id="+ultimo)
+	anterior, _ := strconv.Atoi(ultimo)
+	for _, quiero := range []string{"P1", "C1", "TODOS"} {
+		tipo, id := leerID(t, c)
+		n, _ := strconv.Atoi(id)
+		if tipo != quiero || n <= anterior {
+			t.Fatalf("Reposición fuera de orden: quería %s, llegó %s (id %s)", quiero, tipo, id)
+		}
+		anterior = n
+	}
+
+	// Terminada la reposición sigue el tiempo real, sin repetir nada
+	h.SendToPersona(1, NuevoEvento("VIVO", nil))
+	if tipo, _ := leerID(t, c); tipo != "VIVO" {
+		t.Errorf("Después de la reposición debía llegar el evento en vivo, llegó %q", tipo)
+	}
+	if got := leer(c, 100*time.Millisecond); got != "" {
+		t.Errorf("No debía haber duplicados, llegó %q", got)
+	}
+}
+
+func TestHistorialAcotado(t *testing.T) {
+	mr := miniredis.RunT(t)
+	h := NewHub()
+	h.maxEventos = 3
+	conRedis(t, h, mr)
+
+	for i := 0; i < 10; i++ {
+		h.SendToPersona(1, NuevoEvento("E"+strconv.Itoa(i), nil))
+	}
+	esperar(t, "eventos guardados", func() bool {
+		n, _ := mr.Get(claveSecuencia)
+		return n == "10"
+	})
+
+	// Sin historial completo se repone lo que queda (los más nuevos), en orden
+	perdidos := h.perdidos(context.Background(), &conexion{salas: salasDe(Cliente{PersonaID: 1})}, 1)
+	if len(perdidos) == 0 || len(perdidos) > 3 {
+		t.Fatalf("El historial debía quedar acotado a 3, hay %d", len(perdidos))
+	}
+	if ultimo := perdidos[len(perdidos)-1]; ultimo.seq != 10 || secuenciaDe(ultimo.datos) != 10 {
+		t.Errorf("El último evento debía ser el 10: %s", ultimo.datos)
+	}
+	for i := 1; i < len(perdidos); i++ {
+		if perdidos[i].seq <= perdidos[i-1].seq {
+			t.Errorf("Los ids debían ser crecientes: %d, %d", perdidos[i-1].seq, perdidos[i].seq)
+		}
+	}
+	if h.perdidos(context.Background(), &conexion{}, 0) != nil {
+		t.Errorf("Sin last_seen_id no hay reposición")
+	}
+}
+
+func TestConID(t *testing.T) {
+	datos, err := conID(map[string]string{"tipo": "X"}, "abc-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m map[string]string
+	json.Unmarshal(datos, &m)
+	if m["id"] != "abc-1" || m["tipo"] != "X" {
+		t.Errorf("Debía agregarse el id: %s", datos)
+	}
+
+	// Un id propio del mensaje se respeta; lo que no es objeto se envuelve
+	if datos, _ := conID(map[string]string{"id": "propio"}, "abc-2"); string(datos) != `{"id":"propio"}` {
+		t.Errorf("No debía pisarse el id: %s", datos)
+	}
+	if datos, _ := conID([]int{1}, "abc-3"); string(datos) != `{"datos":[1],"id":"abc-3"}` {
+		t.Errorf("Envoltura inesperada: %s", datos)
+	}
+	if nuevoID() == nuevoID() {
+		t.Errorf("Los ids deben ser únicos")
+	}
+}
+
+func TestReconexionARedis(t *testing.T) {
+	// Redis caído al arrancar: el hub reparte local y sigue reintentando
+	mr := miniredis.RunT(t)
+	addr := mr.Addr()
+	mr.Close()
+	h := NewHub()
+	h.reintentoRedis, h.maxReintentoRedis = 10*time.Millisecond, 40*time.Millisecond
+	conRedisCaido(t, h, addr)
+
+	time.Sleep(100 * time.Millisecond)
+	if h.rdb.Load() != nil {
+		t.Fatal("Sin Redis el hub no debía publicar por pub/sub")
+	}
+	enA := conectar(t, servidorPrueba(t, h), "persona=1")
+	h.SendToPersona(1, NuevoEvento("LOCAL", nil))
+	if got := leer(enA, time.Second); got != "LOCAL" {
+		t.Errorf("Sin Redis el aviso debía repartirse local, llegó %q", got)
+	}
+
+	// Cuando Redis vuelve, el hub se suscribe solo y los avisos pasan por pub/sub
+	if err := mr.Restart(); err != nil {
+		t.Fatal(err)
+	}
+	esperar(t, "resuscripción a Redis", func() bool { return h.rdb.Load() != nil })
+	h.SendToPersona(1, NuevoEvento("REDIS", nil))
+	if got := leer(enA, time.Second); got != "REDIS" {
+		t.Errorf("Con Redis de vuelta debía llegar el aviso, llegó %q", got)
+	}
+	if n, _ := mr.Get(claveSecuencia); n != "1" {
+		t.Errorf("El aviso debía numerarse en Redis: ws_seq=%q", n)
+	}
+}
+
+// conRedisCaido: Como conRedis, pero sin esperar a que la suscripción se confirme
+func conRedisCaido(t *testing.T, h *Hub, addr string) {
+	t.Helper()
+	rdb := redis.NewClient(&redis.Options{Addr: addr, Protocol: 2, MaxRetries: -1})
+	ctx, cancel := context.WithCancel(context.Background())
+	fin := make(chan struct{})
+	t.Cleanup(func() {
+		cancel()
+		<-fin
+		rdb.Close()
+	})
+	go func() {
+		defer close(fin)
+		h.UsarRedis(ctx, rdb)
+	}()
+}
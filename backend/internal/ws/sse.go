@@ -0,0 +1,77 @@
+/**
+ * ARCHIVO: sse.go
+ * UBICACIÓN: internal/ws/sse.go
+ * DESCRIPCIÓN: Server-Sent Events como alternativa al WebSocket (proxies o redes que
+ * lo bloquean). Es la misma conexión del hub con otro transporte: mismos eventos,
+ * salas y reposición; cada evento sale como "id: <id>" + "data: <sobre JSON>", así
+ * EventSource reenvía Last-Event-ID al reconectarse. Como SSE es de una sola vía,
+ * subscribe/unsubscribe/ack llegan por POST /api/eventos/{conexion}.
+ */
+
+package ws
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Espera que sugiere el servidor a EventSource antes de reconectarse
+const reintentoSSE = 3 * time.Second
+
+// transporteSSE: Escribe en la respuesta HTTP abierta y la vacía en cada evento
+type transporteSSE struct {
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	espera time.Duration
+}
+
+func (t *transporteSSE) enviar(texto string) error {
+	// El WriteTimeout del servidor cortaría el stream: el plazo se renueva en cada escritura
+	t.rc.SetWriteDeadline(time.Now().Add(t.espera))
+	if _, err := fmt.Fprint(t.w, texto); err != nil {
+		return err
+	}
+	return t.rc.Flush()
+}
+
+func (t *transporteSSE) escribir(datos []byte) error {
+	return t.enviar("id: " + idDe(datos) + "\ndata: " + string(datos) + "\n\n")
+}
+
+// latido: Un comentario mantiene viva la conexión en los proxies y detecta al que se fue
+func (t *transporteSSE) latido() error { return t.enviar(": ping\n\n") }
+
+// despedir: Al terminar la respuesta, EventSource se reconecta solo
+func (t *transporteSSE) despedir() {}
+
+// expirar: EventSource se reconecta y AuthMiddleware lo rechaza con 401, que lo detiene
+func (t *transporteSSE) expirar() {}
+
+func (t *transporteSSE) cerrar() {}
+
+// ServeSSE abre el stream de eventos de un cliente ya autenticado y lo mantiene hasta
+// que el cliente se va, el hub lo desaloja o su sesión deja de ser válida
+func (h *Hub) ServeSSE(w http.ResponseWriter, r *http.Request, cli Cliente) {
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // nginx no debe acumular el stream
+	w.WriteHeader(http.StatusOK)
+
+	t := &transporteSSE{w: w, rc: rc, espera: h.esperaEscritura}
+	if err := t.enviar(fmt.Sprintf("retry: %d\n\n", reintentoSSE.Milliseconds())); err != nil {
+		return // Sin Flush no hay streaming posible
+	}
+
+	c := h.nuevaConexion(cli, t, "sse")
+	inicio := h.abrir(r, c)
+	// La respuesta solo se puede escribir desde este handler: el writePump corre aquí
+	// y la baja llega cuando el cliente cierra (o al terminar, si fue un desalojo)
+	go func() {
+		<-r.Context().Done()
+		h.bajas <- c
+	}()
+	h.writePump(c, inicio)
+}
@@ -0,0 +1,171 @@
+/**
+ * ARCHIVO: sse_test.go
+ * UBICACIÓN: backend/internal/ws/sse_test.go
+ * DESCRIPCIÓN: Transporte SSE: formato del stream, comandos por HTTP y reposición
+ * con Last-Event-ID.
+ */
+
+package ws
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+// eventoSSE: Un evento ya leído del stream
+type eventoSSE struct {
+	id     string
+	evento Evento
+}
+
+// abrirSSE conecta al stream y devuelve un canal con los eventos que llegan
+func abrirSSE(t *testing.T, srv *httptest.Server, query string, ultimo string) <-chan eventoSSE {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/?"+query, nil)
+	if ultimo != "" {
+		req.Header.Set("Last-Event-ID", ultimo)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type inesperado: %q", ct)
+	}
+
+	eventos := make(chan eventoSSE, 16)
+	go func() {
+		defer resp.Body.Close()
+		var actual eventoSSE
+		sc := bufio.NewScanner(resp.Body)
+		for sc.Scan() {
+			linea := sc.Text()
+			switch {
+			case strings.HasPrefix(linea, "id: "):
+				actual.id = strings.TrimPrefix(linea, "id: ")
+			case strings.HasPrefix(linea, "data: "):
+				json.Unmarshal([]byte(strings.TrimPrefix(linea, "data: ")), &actual.evento)
+			case linea == "" && actual.evento.Tipo != "":
+				eventos <- actual
+				actual = eventoSSE{}
+			}
+		}
+	}()
+	return eventos
+}
+
+func siguiente(t *testing.T, eventos <-chan eventoSSE) eventoSSE {
+	t.Helper()
+	select {
+	case e := <-eventos:
+		return e
+	case <-time.After(time.Second):
+		t.Fatal("No llegó ningún evento por SSE")
+		return eventoSSE{}
+	}
+}
+
+// servidorSSE: GET abre el stream; POST /{conexion} aplica un comando (?persona= identifica)
+func servidorSSE(t *testing.T, h *Hub) *httptest.Server {
+	t.Helper()
+	cliente := func(r *http.Request) Cliente {
+		persona, _ := strconv.Atoi(r.URL.Query().Get("persona"))
+		return Cliente{PersonaID: persona, CongregacionID: r.URL.Query().Get("cong")}
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			h.ServeSSE(w, r, cliente(r))
+			return
+		}
+		var msg MensajeCliente
+		json.NewDecoder(r.Body).Decode(&msg)
+		if err := h.Comando(cliente(r), strings.TrimPrefix(r.URL.Path, "/"), msg); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStreamSSE(t *testing.T) {
+	h := NewHub()
+	srv := servidorSSE(t, h)
+	eventos := abrirSSE(t, srv, "persona=1&cong=c1", "")
+
+	hola := siguiente(t, eventos)
+	if hola.evento.Tipo != EventoConectado {
+		t.Fatalf("El primer evento debía ser %s: %+v", EventoConectado, hola.evento)
+	}
+	datos, _ := json.Marshal(hola.evento.Datos)
+	var c Conectado
+	json.Unmarshal(datos, &c)
+	if c.Transporte != "sse" || c.Conexion == "" {
+		t.Fatalf("Datos de conexión inesperados: %s", datos)
+	}
+
+	h.SendToPersona(1, NuevoEvento("PERSONAL", nil))
+	if e := siguiente(t, eventos); e.evento.Tipo != "PERSONAL" || e.id == "" || e.id != e.evento.ID {
+		t.Errorf("El id del stream debía ser el del evento: %+v", e)
+	}
+
+	// Los comandos llegan por POST y solo el dueño puede usar la conexión
+	cuerpo := `{"type":"unsubscribe","salas":["persona:1"]}`
+	if resp, _ := http.Post(srv.URL+"/"+c.Conexion+"?persona=2", "application/json", strings.NewReader(cuerpo)); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("Otra persona no debía poder usar la conexión: %d", resp.StatusCode)
+	}
+	if resp, _ := http.Post(srv.URL+"/"+c.Conexion+"?persona=1", "application/json", strings.NewReader(cuerpo)); resp.StatusCode != http.StatusOK {
+		t.Fatalf("El comando del dueño debía aplicarse: %d", resp.StatusCode)
+	}
+	if e := siguiente(t, eventos); e.evento.Tipo != EventoSuscripcion {
+		t.Fatalf("Se esperaba %s, llegó %s", EventoSuscripcion, e.evento.Tipo)
+	}
+	h.SendToPersona(1, NuevoEvento("PERSONAL", nil))
+	h.SendToCongregation("c1", NuevoEvento("CONGREGACION", nil))
+	if e := siguiente(t, eventos); e.evento.Tipo != "CONGREGACION" {
+		t.Errorf("Fuera de su sala personal no debía llegar el aviso, llegó %s", e.evento.Tipo)
+	}
+}
+
+func TestReposicionSSEConAck(t *testing.T) {
+	mr := miniredis.RunT(t)
+	h := NewHub()
+	conRedis(t, h, mr)
+	srv := servidorSSE(t, h)
+
+	eventos := abrirSSE(t, srv, "persona=1", "")
+	siguiente(t, eventos)
+	h.SendToPersona(1, NuevoEvento("VISTO", nil))
+	visto := siguiente(t, eventos)
+	if err := h.Comando(Cliente{PersonaID: 1}, "", MensajeCliente{Tipo: MensajeConfirmar, ID: visto.id}); err != nil {
+		t.Fatal(err)
+	}
+
+	h.SendToPersona(1, NuevoEvento("PERDIDO", nil))
+	esperar(t, "evento guardado", func() bool {
+		n, _ := mr.Get(claveSecuencia)
+		return n == "2"
+	})
+
+	// Sin Last-Event-ID se repone desde el último ack; con él, desde ahí
+	eventos = abrirSSE(t, srv, "persona=1", "")
+	siguiente(t, eventos)
+	if e := siguiente(t, eventos); e.evento.Tipo != "PERDIDO" {
+		t.Errorf("Debía reponerse desde el ack, llegó %s", e.evento.Tipo)
+	}
+	eventos = abrirSSE(t, srv, "persona=1", "1")
+	siguiente(t, eventos)
+	if e := siguiente(t, eventos); e.evento.Tipo != "PERDIDO" || e.id != "2" {
+		t.Errorf("Debía reponerse desde Last-Event-ID: %+v", e)
+	}
+}
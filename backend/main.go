@@ -9,6 +9,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -16,10 +17,14 @@ import (
 	"strings"
 	"time"
 
+	"gestion-congregacion/backend/internal/captcha"
+	"gestion-congregacion/backend/internal/correo"
 	"gestion-congregacion/backend/internal/handlers"
+	"gestion-congregacion/backend/internal/mensajeria"
 	"gestion-congregacion/backend/internal/repository"
 	"gestion-congregacion/backend/internal/routes"
 	"gestion-congregacion/backend/internal/service"
+	"gestion-congregacion/backend/internal/ws"
 
 	"github.com/joho/godotenv"
 	"github.com/redis/go-redis/v9"
@@ -33,12 +38,19 @@ func main() {
 	godotenv.Load()
 
 	// 1. VALIDACIÓN DE SECRETOS (Alerta 1)
+	// Rotación JWT: JWT_KEY_ID nombra a JWT_SECRET (kid) y JWT_PREVIOUS_KEYS ("kid:secreto,...")
+	// mantiene válidas las llaves anteriores hasta que expiren sus sesiones.
+	// UNSUBSCRIBE_SECRET (opcional) firma los enlaces de baja, que no vencen; sin ella se usa JWT_SECRET.
+	// LIMITES_SECRET (opcional, no rotar) indexa los bloqueos por cuenta; sin ella se usa JWT_SECRET.
 	requiredEnvs := []string{"JWT_SECRET", "DB_PASSWORD", "ALLOWED_ORIGINS", "REDIS_URL"}
 	for _, env := range requiredEnvs {
 		if os.Getenv(env) == "" {
 			log.Fatalf("CRÍTICO: Variable de entorno %s no definida.", env)
 		}
 	}
+	if os.Getenv("LIMITES_SECRET") == "" {
+		log.Println("⚠️ LIMITES_SECRET no definida: rotar JWT_SECRET reiniciará los bloqueos por cuenta")
+	}
 
 	// 2. Conexión a DB con Pool de alto rendimiento
 	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=require TimeZone=UTC",
@@ -80,7 +92,15 @@ func main() {
 
 	// 2. Inyección de Dependencias (Repository -> Service con Redis)
 	repo := repository.NewRepository(db)
-	svc := service.NewService(repo, rdb) // <--- Ahora le pasamos rdb al servicio
+	// Redis + verificador de CAPTCHA (CAPTCHA_PROVIDER) + canales SMS/WhatsApp (SMS_PROVIDER, WHATSAPP_PROVIDER)
+	// + proveedor de correo del outbox (EMAIL_PROVIDER: resend, smtp o fake)
+	svc := service.NewService(repo, rdb, captcha.NewFromEnv(), mensajeria.NewFromEnv(), correo.NewFromEnv())
+
+	// Worker del outbox: entrega los correos encolados con reintentos y dead-letter
+	go svc.StartEmailWorker(context.Background())
+	// WebSocket entre instancias: los avisos viajan por Redis pub/sub (ws:<sala>).
+	// Si Redis cae, el hub reparte solo local y se vuelve a suscribir cuando responde.
+	go ws.UsarRedis(context.Background(), rdb)
 	// 3. Registro de Rutas
 	routes.RegisterRoutes(mux, svc)
 
@@ -114,7 +134,7 @@ func main() {
 	finalHandler := cors.New(cors.Options{
 		AllowedOrigins:   originsList,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Authorization", "Content-Type", "Accept", "X-Requested-With"},
+		AllowedHeaders:   []string{"Authorization", "Content-Type", "Accept", "X-Requested-With", "Last-Event-ID"},
 		AllowCredentials: true,
 		MaxAge:           86400,
 	}).Handler(securityLayer)
@@ -36,13 +36,3 @@ func TestSecurityGenericErrors(t *testing.T) {
 		t.Errorf("FALLO DE SEGURIDAD: Se esperaba error genérico, se obtuvo: %s", response["error"])
 	}
 }
-
-func TestIdentifyUserResponse(t *testing.T) {
-	// Objetivo: El endpoint debe responder 404 si el usuario no existe
-	rr := httptest.NewRecorder()
-	rr.WriteHeader(http.StatusNotFound)
-
-	if rr.Code != 404 {
-		t.Errorf("ERROR DE CONTRATO: Identificar usuario inexistente debería dar 404")
-	}
-}
\ No newline at end of file
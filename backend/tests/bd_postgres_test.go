@@ -0,0 +1,68 @@
+//go:build integracion
+
+/**
+ * ARCHIVO: bd_postgres_test.go
+ * UBICACIÓN: backend/tests/bd_postgres_test.go
+ * DESCRIPCIÓN: Pruebas contra PostgreSQL real (go test -tags integracion ./tests/).
+ * TEST_DATABASE_URL apunta a una base con SCHEMA.sql aplicado (ej: Supabase local);
+ * cada prueba corre dentro de una transacción que se deshace al terminar.
+ */
+
+package tests
+
+import (
+	"os"
+	"testing"
+
+	"gestion-congregacion/backend/internal/repository"
+	"gestion-congregacion/backend/internal/service"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// baseDePrueba abre TEST_DATABASE_URL y devuelve una transacción descartable
+func baseDePrueba(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL no definida")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatal(tx.Error)
+	}
+	t.Cleanup(func() {
+		tx.Rollback()
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return tx
+}
+
+// servicioConBase arma el Service sobre la transacción de prueba y un Redis en memoria
+func servicioConBase(t *testing.T, tx *gorm.DB) *service.Service {
+	t.Helper()
+	t.Setenv("JWT_SECRET", "secreto-de-prueba")
+	rdb := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	t.Cleanup(func() { rdb.Close() })
+	return service.NewService(repository.NewRepository(tx), rdb, nil, nil, nil)
+}
+
+// ejecutar corre una sentencia de preparación de datos y devuelve la primera columna
+func ejecutar(t *testing.T, tx *gorm.DB, sql string, args ...interface{}) string {
+	t.Helper()
+	var valor string
+	if err := tx.Raw(sql, args...).Scan(&valor).Error; err != nil {
+		t.Fatalf("%s: %v", sql, err)
+	}
+	return valor
+}
@@ -0,0 +1,59 @@
+//go:build integracion
+
+/**
+ * ARCHIVO: informes_test.go
+ * UBICACIÓN: backend/tests/informes_test.go
+ * DESCRIPCIÓN: Los informes de servicio de la congregación solo los consultan los
+ * ancianos, los admins locales y quienes tienen el módulo de informes.
+ */
+
+package tests
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+
+	"gestion-congregacion/backend/internal/repository"
+	"gestion-congregacion/backend/internal/service"
+
+	"gorm.io/gorm"
+)
+
+// publicadorConModulo: Persona que no es anciana ni admin, con solo el módulo indicado
+func publicadorConModulo(t *testing.T, tx *gorm.DB, modulo string, nivel int) int {
+	t.Helper()
+	congID := ejecutar(t, tx, `INSERT INTO core_congregaciones (nombre, pais, provincia_estado, ciudad)
+		VALUES ('Prueba', 'Argentina', 'Buenos Aires', 'La Plata') RETURNING id`)
+	personaID := ejecutar(t, tx, `INSERT INTO core_personas (congregacion_id, apellido_nombre, estado, situacion_1)
+		VALUES (?, 'Prueba, Publicador', 'ALTA', 'Publicador') RETURNING id`, congID)
+	usuarioID := ejecutar(t, tx, `INSERT INTO auth.users (id) VALUES (gen_random_uuid()) RETURNING id`)
+	ejecutar(t, tx, `INSERT INTO core_usuarios (id, persona_id, congregacion_id) VALUES (?, ?, ?) RETURNING id`, usuarioID, personaID, congID)
+	ejecutar(t, tx, `INSERT INTO core_modulos (id, nombre) VALUES (?, ?) ON CONFLICT (id) DO NOTHING RETURNING id`, modulo, modulo)
+	ejecutar(t, tx, `INSERT INTO core_permisos_modulos (usuario_id, modulo_id, congregacion_id, nivel_acceso)
+		VALUES (?, ?, ?, ?) RETURNING modulo_id`, usuarioID, modulo, congID, nivel)
+	id, err := strconv.Atoi(personaID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestInformesNoSeAbrenConElModuloDeReuniones(t *testing.T) {
+	tx := baseDePrueba(t)
+	svc := servicioConBase(t, tx)
+
+	reuniones := publicadorConModulo(t, tx, repository.ModuloReuniones, 3)
+	if _, err := svc.GetReportTotals(reuniones, ""); !errors.Is(err, service.ErrSinPermiso) {
+		t.Errorf("FALLO DE SEGURIDAD: El módulo de reuniones dio acceso a los totales: %v", err)
+	}
+	grupo := 1
+	if _, err := svc.GetMissingReports(reuniones, "", &grupo); !errors.Is(err, service.ErrSinPermiso) {
+		t.Errorf("FALLO DE SEGURIDAD: El módulo de reuniones dio acceso a los pendientes: %v", err)
+	}
+
+	informes := publicadorConModulo(t, tx, repository.ModuloInformes, 1)
+	if _, err := svc.GetReportTotals(informes, ""); err != nil {
+		t.Errorf("Con el módulo de informes debía ver los totales: %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+/**
+ * ARCHIVO: token_test.go
+ * UBICACIÓN: backend/tests/token_test.go
+ * DESCRIPCIÓN: Valida los claims tipados, el anclaje de algoritmo y la rotación de llaves JWT.
+ */
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"gestion-congregacion/backend/internal/auth"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var identidadPrueba = auth.Identidad{UsuarioID: "u-1", SesionID: "s-1", PersonaID: 42, CongregacionID: "c-1"}
+
+func TestRefreshTokenNoSirveComoAccess(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secreto-de-prueba")
+
+	refresh, err := auth.GenerarRefreshToken(identidadPrueba)
+	if err != nil {
+		t.Fatalf("FALLO: No se pudo firmar la llave de refresco: %v", err)
+	}
+
+	if _, err := auth.ValidarAccessToken(refresh); err == nil {
+		t.Errorf("FALLO DE SEGURIDAD: Una llave de refresco fue aceptada como auth_token")
+	}
+
+	claims, err := auth.ValidarRefreshToken(refresh)
+	if err != nil {
+		t.Fatalf("FALLO: La llave de refresco debería ser válida: %v", err)
+	}
+	if claims.PersonaID != 42 || claims.CongregacionID != "c-1" || claims.SesionID != "s-1" {
+		t.Errorf("FALLO DE CONTRATO: Claims inesperados: %+v", claims)
+	}
+}
+
+func TestAlgoritmoAnclado(t *testing.T) {
+	t.Setenv("JWT_SECRET", "secreto-de-prueba")
+
+	// Una llave sin firma ('none') jamás debe ser aceptada
+	claims := auth.Claims{
+		Tipo:      auth.TipoAccess,
+		PersonaID: 42,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    auth.Issuer,
+			Audience:  jwt.ClaimStrings{auth.Audience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute)),
+		},
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+	token.Header["kid"] = "principal"
+	unsigned, _ := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+
+	if _, err := auth.ValidarAccessToken(unsigned); err == nil {
+		t.Errorf("FALLO DE SEGURIDAD: Se aceptó una llave con alg=none")
+	}
+}
+
+func TestRotacionDeLlaves(t *testing.T) {
+	// 1. Emitimos con la llave vieja
+	t.Setenv("JWT_KEY_ID", "2026-06")
+	t.Setenv("JWT_SECRET", "secreto-viejo")
+	vieja, err := auth.GenerarAccessToken(identidadPrueba)
+	if err != nil {
+		t.Fatalf("FALLO: No se pudo firmar con la llave vieja: %v", err)
+	}
+
+	// 2. Rotamos: nueva llave activa, la vieja queda solo para validar
+	t.Setenv("JWT_KEY_ID", "2026-07")
+	t.Setenv("JWT_SECRET", "secreto-nuevo")
+	t.Setenv("JWT_PREVIOUS_KEYS", "2026-06:secreto-viejo")
+
+	if _, err := auth.ValidarAccessToken(vieja); err != nil {
+		t.Errorf("FALLO DE ROTACIÓN: La llave vieja debería seguir siendo válida: %v", err)
+	}
+
+	// 3. Retiramos la llave vieja: las sesiones firmadas con ella dejan de valer
+	t.Setenv("JWT_PREVIOUS_KEYS", "")
+	if _, err := auth.ValidarAccessToken(vieja); err == nil {
+		t.Errorf("FALLO DE ROTACIÓN: Se aceptó una llave con 'kid' retirado")
+	}
+}
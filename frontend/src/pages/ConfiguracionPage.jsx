@@ -15,6 +15,7 @@
 
 import React, { useContext, useState, useEffect, useMemo } from "react";
 import { AppContext } from "../context/AppContext";
+import axios from "axios";
 import { themePalettes } from "../config/themeConfig"; // <--- Importamos desde la nueva ubicación
 import { useNavigate } from "react-router-dom";
 // --- NUEVA IMPORTACIÓN PARA TRADUCCIONES ---
@@ -103,13 +104,34 @@ const supportedLanguages = [
 ];
 
 function ConfiguracionPage() {
-  const { activeTheme, userTheme, setUserTheme, fontSize, setFontSize } =
-    useContext(AppContext);
+  const {
+    activeTheme,
+    userTheme,
+    setUserTheme,
+    fontSize,
+    setFontSize,
+    user,
+    login,
+  } = useContext(AppContext);
   const navigate = useNavigate();
 
   // --- HOOK DE TRADUCCIÓN ---
   const { t, i18n } = useTranslation(); // <-- El hook que nos da la función 't'
 
+  // Con sesión iniciada, el idioma también se guarda en el perfil para que los correos lleguen traducidos
+  const guardarIdioma = async (codigo) => {
+    if (!user?.persona_id || user.idioma === codigo) return;
+    try {
+      await axios.post("/api/update-profile", {
+        campo: "idioma",
+        valor: codigo,
+      });
+      login({ ...user, idioma: codigo });
+    } catch {
+      // El cambio visual ya se aplicó; el correo seguirá en el idioma anterior
+    }
+  };
+
   // Detección de idioma de derecha a izquierda (RTL)
   const isRtl = i18n.dir
     ? i18n.dir() === "rtl"
@@ -680,6 +702,7 @@ function ConfiguracionPage() {
                       onClick={() => {
                         i18n.changeLanguage(lang.code);
                         setSearchTerm("");
+                        guardarIdioma(lang.code);
                       }}
                       className={`p-3 rounded-lg border-2 transition-all flex flex-col items-center justify-center text-center h-full ${isActive ? "bg-jw-accent text-jw-text-light border-transparent shadow-lg" : "bg-transparent text-jw-text-main/80 border-jw-border hover:border-jw-accent hover:text-jw-accent"}`}
                     >
@@ -44,7 +44,8 @@ function LoginPage() {
   const [errorMsg, setErrorMsg] = useState("");
   const [showPass, setShowPass] = useState(false);
   const [showConfirm, setShowConfirm] = useState(false);
-  const [tempEmail, setTempEmail] = useState(""); // Email enmascarado para confirmación visual
+  const [tempEmail, setTempEmail] = useState(""); // Destino (correo o teléfono) ya enmascarado por el servidor
+  const [recoveryId, setRecoveryId] = useState(""); // Ticket de recuperación emitido por el servidor
 
   // --- 3. GESTIÓN DE ENTRADAS DE DATOS ---
   const [inputs, setInputs] = useState({
@@ -153,20 +154,10 @@ function LoginPage() {
   // canSendPin: Verifica que los campos de identidad tengan el formato mínimo correcto
   const canSendPin =
     (inputs.persona_id && inputs.numero_cong.length >= 4) ||
-    inputs.telefono.length === 8;
+    inputs.telefono.length >= 10;
 
   // --- 5. FUNCIONES AUXILIARES ---
 
-  /**
-   * maskEmail: Protege la privacidad del usuario al mostrar el destino del PIN.
-   * @example "j****n@dominio.com"
-   */
-  const maskEmail = (email) => {
-    if (!email) return "";
-    const [name, domain] = email.split("@");
-    return `${name[0]}${"*".repeat(name.length - 2)}${name.slice(-1)}@${domain}`;
-  };
-
   /**
    * resetAll: Limpia el estado completo para reiniciar cualquier flujo de acceso.
    */
@@ -175,6 +166,8 @@ function LoginPage() {
     setErrorMsg("");
     setLoading(false);
     setShowPass(false);
+    setRecoveryId("");
+    setTempEmail("");
     setInputs({
       username: "",
       password: "",
@@ -217,6 +210,9 @@ function LoginPage() {
       if (errorStr.includes("CAPTCHA")) {
         setErrorMsg("Seguridad activada: Por favor, resuelva el CAPTCHA inferior.");
         // Aquí podrías forzar el renderizado del widget si estuviera oculto
+      } else if ([403, 429].includes(err.response?.status) && errorStr) {
+        // Cuenta en espera por intentos fallidos o cuenta suspendida: el servidor explica el motivo
+        setErrorMsg(errorStr.replace("SISTEMA: ", ""));
       } else {
         setErrorMsg("El usuario y/o contraseña no es correcto.");
       }
@@ -226,50 +222,51 @@ function LoginPage() {
   };
 
   /**
-   * startRecovery: Inicia el protocolo de recuperación enviando un PIN al correo.
+   * startRecovery: Inicia el protocolo de recuperación enviando un PIN al correo registrado.
+   * El servidor responde igual exista o no la cuenta (no revela qué datos son correctos).
    */
   const startRecovery = async (type) => {
     setLoading(true);
     setErrorMsg("");
-    const metodo = inputs.telefono.length === 8 ? "phone" : "id_cong";
+    const metodo = inputs.telefono.length >= 10 ? "phone" : "id_cong";
     try {
-      // Si es pass, validamos usuario antes de seguir
-      if (type === "pass") {
-        await axios.post("/api/identify-user", { username: inputs.username });
-      }
-
-      const res = await axios.post("/api/recover-user-id", {
+      const res = await axios.post("/api/recovery/start", {
+        tipo: type,
+        username: type === "pass" ? inputs.username : "",
         metodo,
         persona_id: inputs.persona_id,
         numero_congregacion: inputs.numero_cong,
         telefono: inputs.telefono,
       });
-      setTempEmail(res.data.email);
+      setRecoveryId(res.data.recovery_id);
+      setTempEmail(res.data.destino);
       setRecoveryType(type);
-      await axios.post("/api/request-pin", {
-        email: res.data.email,
-        username: inputs.username || "Hermano",
-        congregacion: inputs.numero_cong || "SISTEMA",
-      });
       setStep("verify_pin");
-    } catch {
-      setErrorMsg("Los datos ingresados no existen en el sistema.");
+    } catch (err) {
+      const errorStr = err.response?.data?.error || "";
+      setErrorMsg(
+        err.response?.status === 429 && errorStr
+          ? errorStr.replace("SISTEMA: ", "")
+          : "No se pudo iniciar la recuperación. Intente más tarde.",
+      );
     } finally {
       setLoading(false);
     }
   };
 
   /**
-   * handleVerifyCode: Valida que el PIN ingresado por el usuario coincida con el generado.
+   * handleVerifyCode: Valida el PIN contra el ticket de recuperación.
    */
   const handleVerifyCode = async () => {
     setLoading(true);
     setErrorMsg("");
     try {
-      await axios.post("/api/verify-pin", { pin: inputs.pin });
+      await axios.post("/api/recovery/verify", {
+        recovery_id: recoveryId,
+        pin: inputs.pin,
+      });
       if (recoveryType === "user") {
-        // Si recupera usuario, se envía el alias por correo
-        await axios.post("/api/send-username-real", { email: tempEmail });
+        // El servidor ya envió el alias al correo registrado
         setStep("success");
       } else {
         // Si recupera contraseña, habilita la vista de cambio de clave
@@ -289,7 +286,7 @@ function LoginPage() {
     setLoading(true);
     try {
       await axios.post("/api/reset-password", {
-        username: inputs.username,
+        recovery_id: recoveryId,
         new_password: inputs.password,
       });
       setStep("success");
@@ -452,14 +449,14 @@ function LoginPage() {
 
               <div className="space-y-1">
                 <label className="text-[9px] font-bold text-gray-400 uppercase block text-center italic">
-                  O ingrese los últimos 8 números del celular
+                  O ingrese su celular completo (con código de área)
                 </label>
                 <input
                   type="text"
                   value={inputs.telefono}
                   className="w-full p-3 bg-jw-body rounded-xl text-center font-bold outline-none focus:ring-2 focus:ring-jw-blue text-sm"
-                  placeholder="00000000"
-                  onChange={(e) => handleNumericInput(e, "telefono", 8)}
+                  placeholder="1112345678"
+                  onChange={(e) => handleNumericInput(e, "telefono", 15)}
                 />
               </div>
 
@@ -491,9 +488,9 @@ function LoginPage() {
           {step === "verify_pin" && (
             <div className="text-center space-y-4 animate-in zoom-in">
               <p className="text-xs text-gray-500 italic">
-                PIN enviado al correo:
+                PIN enviado a:
                 <br />
-                <b>{maskEmail(tempEmail)}</b>
+                <b>{tempEmail}</b>
               </p>
               <input
                 maxLength="6"
@@ -49,6 +49,10 @@ import {
   ChevronLeft,
   ChevronRight,
   UserRoundPlus,
+  Smartphone,
+  LogOut,
+  MessageSquare,
+  Bell,
 } from "lucide-react";
 
 // Motor de animaciones (Alias 'Motion' para cumplir reglas de calidad de código)
@@ -199,6 +203,8 @@ function ProfilePage() {
   const [showAdminBroadcast, setShowAdminBroadcast] = useState(false);
   const [avatarGender, setAvatarGender] = useState(null);
   const [showGallery, setShowGallery] = useState(true);
+  const [sesiones, setSesiones] = useState([]); // Dispositivos con sesión activa
+  const [preferencias, setPreferencias] = useState([]); // Avisos por categoría y canal
 
   // --- BLOQUE 3: FUNCIONES DE CARGA Y SEGURIDAD ---
 
@@ -229,6 +235,22 @@ function ProfilePage() {
     }
   }, [i18n.language, t]);
 
+  // Carga los dispositivos conectados (core_sesiones) del usuario actual
+  useEffect(() => {
+    axios
+      .get("/api/sesiones")
+      .then((res) => setSesiones(res.data || []))
+      .catch(() => setSesiones([]));
+  }, []);
+
+  // Carga la matriz de preferencias de notificación (categoría × canal)
+  useEffect(() => {
+    axios
+      .get("/api/preferencias")
+      .then((res) => setPreferencias(res.data || []))
+      .catch(() => setPreferencias([]));
+  }, []);
+
   // Revisa si el nombre de usuario ya está ocupado mientras escribes
   useEffect(() => {
     if (editingField === "username" && formValues.newValue.length > 2) {
@@ -282,7 +304,7 @@ function ProfilePage() {
     setReqs({ length: false, upper: false, number: false, symbol: false });
     let initialVal = "";
     if (field === "contacto")
-      initialVal = (user.contacto || "").replace(/\D/g, "");
+      initialVal = (user.contacto || "").replace(/[^\d+]/g, "");
     if (field === "email") initialVal = user.email || "";
     setFormValues({
       ...formValues,
@@ -296,11 +318,8 @@ function ProfilePage() {
   const handleSendCode = async () => {
     setLoading(true);
     try {
-      await axios.post("/api/request-pin", {
-        email: user.email,
-        username: user.username,
-        congregacion: user.congregacion_nombre,
-      });
+      // El servidor envía el PIN al correo registrado de la sesión activa
+      await axios.post("/api/request-pin");
       setToast(true);
       setTimeout(() => setToast(false), 3000);
       setVerificationStep(3);
@@ -447,19 +466,15 @@ function ProfilePage() {
     try {
       const cleanValue =
         editingField === "contacto"
-          ? formValues.newValue.replace(/\D/g, "")
+          ? formValues.newValue.replace(/[^\d+]/g, "")
           : formValues.newValue;
       if (editingField === "password") {
-        await axios.post("/api/reset-password", {
-          username: user.username,
+        await axios.post("/api/change-password", {
           current_password: formValues.currentPass,
           new_password: formValues.newValue,
-          persona_id: String(user.persona_id),
         });
       } else {
         await axios.post("/api/update-profile", {
-          persona_id: String(user.persona_id),
-          usuario_id: user.id || "",
           campo: editingField,
           valor: cleanValue,
         });
@@ -559,13 +574,72 @@ function ProfilePage() {
     });
   };
 
+  // Canal por el que llegan los PINs y la recuperación (email, SMS o WhatsApp)
+  const handleCanalChange = async (canal) => {
+    try {
+      await axios.post("/api/update-profile", {
+        campo: "canal_preferido",
+        valor: canal,
+      });
+      login({ ...user, canal_preferido: canal });
+    } catch {
+      setModal({
+        show: true,
+        type: "error",
+        title: t("error"),
+        message: t("profile_error_save"),
+      });
+    }
+  };
+
+  // Activa o desactiva una categoría de avisos en un canal
+  const handlePreferenciaChange = async (categoria, canal, activo) => {
+    try {
+      await axios.post("/api/preferencias", { categoria, canal, activo });
+      setPreferencias((prev) =>
+        prev.map((p) =>
+          p.categoria === categoria && p.canal === canal ? { ...p, activo } : p,
+        ),
+      );
+    } catch {
+      setModal({
+        show: true,
+        type: "error",
+        title: t("error"),
+        message: t("profile_error_save"),
+      });
+    }
+  };
+
+  // Cierra la sesión de otro dispositivo (ej: teléfono perdido)
+  const handleRevokeSesion = (sesionId) => {
+    setModal({
+      show: true,
+      type: "confirm",
+      title: t("profile_sessions_revoke_title"),
+      message: t("profile_sessions_revoke_msg"),
+      onConfirm: async () => {
+        setModal({ ...modal, show: false });
+        try {
+          await axios.post("/api/sesiones/revocar", { sesion_id: sesionId });
+          setSesiones((prev) => prev.filter((s) => s.id !== sesionId));
+        } catch {
+          setModal({
+            show: true,
+            type: "error",
+            title: t("error"),
+            message: t("profile_sessions_revoke_error"),
+          });
+        }
+      },
+    });
+  };
+
   const processDeleteAccountFinal = async () => {
     setLoading(true);
     try {
-      await axios.post("/api/suspender-cuenta", {
-        persona_id: String(user.persona_id),
-        usuario_id: user.id || "",
-      });
+      // El backend toma la persona de la sesión
+      await axios.post("/api/suspender-cuenta", {});
       logout();
       navigate("/login");
     } catch {
@@ -693,7 +767,7 @@ function ProfilePage() {
                   ...formValues,
                   newValue:
                     editingField === "contacto"
-                      ? e.target.value.replace(/\D/g, "")
+                      ? e.target.value.replace(/[^\d+]/g, "")
                       : e.target.value,
                 })
               }
@@ -928,6 +1002,91 @@ function ProfilePage() {
                 verificationStep === 4 &&
                 renderEditForm()}
             </div>
+            <div className="flex flex-col sm:flex-row sm:justify-between sm:items-center border-b border-gray-200 pb-4 gap-3">
+              <div className="flex items-center gap-4 text-start">
+                <div className="p-2.5 bg-slate-300 rounded-xl text-gray-600 shrink-0">
+                  <MessageSquare size={25} />
+                </div>
+                <div className="min-w-0">
+                  <p className="text-[11px] font-medium text-gray-600 uppercase tracking-widest mb-0.5">
+                    {t("profile_channel_label")}
+                  </p>
+                  <p className="text-[11px] text-gray-400 italic">
+                    {t("profile_channel_hint")}
+                  </p>
+                </div>
+              </div>
+              <select
+                value={user?.canal_preferido || "email"}
+                onChange={(e) => handleCanalChange(e.target.value)}
+                className="w-full sm:w-auto bg-slate-200 px-4 py-2 rounded-xl text-jw-blue font-bold text-[11px] uppercase tracking-widest outline-none focus:ring-2 focus:ring-jw-blue"
+              >
+                <option value="email">{t("profile_channel_email")}</option>
+                <option value="sms">{t("profile_channel_sms")}</option>
+                <option value="whatsapp">{t("profile_channel_whatsapp")}</option>
+              </select>
+            </div>
+            {preferencias.length > 0 && (
+              <div className="border-b border-gray-200 pb-4">
+                <div className="flex items-center gap-4 text-start mb-3">
+                  <div className="p-2.5 bg-slate-300 rounded-xl text-gray-600 shrink-0">
+                    <Bell size={25} />
+                  </div>
+                  <div className="min-w-0">
+                    <p className="text-[11px] font-medium text-gray-600 uppercase tracking-widest mb-0.5">
+                      {t("profile_notif_label")}
+                    </p>
+                    <p className="text-[11px] text-gray-400 italic">
+                      {t("profile_notif_hint")}
+                    </p>
+                  </div>
+                </div>
+                <div className="overflow-x-auto">
+                  <table className="w-full text-[11px] text-gray-600">
+                    <thead>
+                      <tr>
+                        <th />
+                        {["email", "push", "sms", "whatsapp"].map((canal) => (
+                          <th
+                            key={canal}
+                            className="px-2 py-1 font-bold uppercase tracking-widest"
+                          >
+                            {t(`profile_notif_canal_${canal}`)}
+                          </th>
+                        ))}
+                      </tr>
+                    </thead>
+                    <tbody>
+                      {["seguridad", "anuncios", "literatura"].map((cat) => (
+                        <tr key={cat}>
+                          <td className="py-1 text-start font-medium">
+                            {t(`profile_notif_cat_${cat}`)}
+                          </td>
+                          {preferencias
+                            .filter((p) => p.categoria === cat)
+                            .map((p) => (
+                              <td key={p.canal} className="px-2 py-1 text-center">
+                                <input
+                                  type="checkbox"
+                                  checked={p.activo}
+                                  onChange={(e) =>
+                                    handlePreferenciaChange(
+                                      cat,
+                                      p.canal,
+                                      e.target.checked,
+                                    )
+                                  }
+                                  className="accent-jw-blue"
+                                />
+                              </td>
+                            ))}
+                        </tr>
+                      ))}
+                    </tbody>
+                  </table>
+                </div>
+              </div>
+            )}
             <div className="group">
               <EditableRow
                 label={t("profile_password_label")}
@@ -1043,6 +1202,56 @@ function ProfilePage() {
           </div>
         </section>
 
+        {/* SECCIÓN 2B: DISPOSITIVOS CONECTADOS */}
+        <section className="bg-white rounded-xl shadow-sm border border-jw-border overflow-hidden text-jw-navy">
+          <div className="p-5 bg-jw-navy text-white border-b-3 border-jw-blue text-start">
+            <h2 className="text-lg font-normal italic flex items-center gap-3">
+              <Smartphone className="w-9 h-9 text-slate-400" />{" "}
+              {t("profile_sessions_title")}
+            </h2>
+          </div>
+          <div className="p-8 space-y-4 text-start">
+            {sesiones.map((s) => (
+              <div
+                key={s.id}
+                className="flex flex-col sm:flex-row sm:justify-between sm:items-center border-b border-gray-200 pb-4 gap-3"
+              >
+                <div className="min-w-0">
+                  <p className="text-sm text-jw-navy font-semibold truncate">
+                    <bdi>{s.user_agent || t("profile_sessions_unknown")}</bdi>
+                  </p>
+                  <p className="text-[11px] text-gray-500 uppercase tracking-widest">
+                    IP <bdi>{s.ip}</bdi> · {t("profile_sessions_last_used")}{" "}
+                    {new Date(s.ultimo_uso_at).toLocaleString(i18n.language)}
+                  </p>
+                  <p className="text-[10px] text-gray-400 italic">
+                    {t("profile_sessions_created")}{" "}
+                    {new Date(s.creado_at).toLocaleString(i18n.language)}
+                  </p>
+                </div>
+                {s.actual ? (
+                  <span className="text-[10px] font-black uppercase tracking-widest text-green-600 flex items-center gap-2">
+                    <CheckCircle2 size={14} /> {t("profile_sessions_current")}
+                  </span>
+                ) : (
+                  <button
+                    onClick={() => handleRevokeSesion(s.id)}
+                    className="w-full sm:w-auto bg-red-50 text-red-600 px-5 py-2.5 rounded-xl text-[11px] font-bold uppercase tracking-widest border border-red-200 transition-all duration-300 hover:scale-105 active:scale-95 hover:bg-red-600 hover:text-white flex items-center justify-center gap-2"
+                  >
+                    <LogOut size={14} /> {t("profile_sessions_btn_revoke")}
+                  </button>
+                )}
+              </div>
+            ))}
+            {/* La lista siempre incluye este dispositivo: el aviso va solo si es el único */}
+            {sesiones.length === 1 && sesiones[0].actual && (
+              <p className="text-xs text-gray-400 italic">
+                {t("profile_sessions_only_current")}
+              </p>
+            )}
+          </div>
+        </section>
+
         {/* SECCIÓN 3: GALERÍA DE AVATARES */}
         <section className="bg-white rounded-xl shadow-sm border border-jw-border overflow-hidden text-jw-navy">
           <div className="p-5 bg-jw-navy text-white border-b-3 border-jw-blue flex justify-between items-center">
@@ -188,7 +188,12 @@ const SecurityTipCard = ({ tip, index }) => {
 function SecurityTipsPage() {
   const { t, i18n } = useTranslation();
   const navigate = useNavigate();
-  const [dbInfo, setDbInfo] = useState({ contenido: "", updated_at: "" });
+  const [dbInfo, setDbInfo] = useState({
+    titulo: "",
+    descripcion_larga: "",
+    descripcion_html: "",
+    updated_at: "",
+  });
 
   // Función para formatear la fecha dinámicamente según el idioma
   const formatDate = (dateStr) => {
@@ -215,10 +220,27 @@ function SecurityTipsPage() {
     window.scrollTo(0, 0);
     axios
       .get("/api/seguridad-info")
-      .then((res) => setDbInfo(res.data))
+      .then((res) => {
+        setDbInfo(res.data);
+        // Acuse de lectura (si no hay sesión el backend responde 401 y se ignora)
+        if (res.data?.id) {
+          axios
+            .post(`/api/boletines/${res.data.id}/acuse`, { confirmado: false })
+            .catch(() => {});
+        }
+      })
       .catch((err) => console.error(err));
   }, []);
 
+  // "Entendido": confirma que el miembro leyó el boletín
+  const [confirmado, setConfirmado] = useState(false);
+  const confirmarLectura = () => {
+    axios
+      .post(`/api/boletines/${dbInfo.id}/acuse`, { confirmado: true })
+      .then(() => setConfirmado(true))
+      .catch((err) => console.error(err));
+  };
+
   const handleBack = () => navigate("/perfil#seguridad");
 
   const tips = useMemo(
@@ -469,7 +491,7 @@ function SecurityTipsPage() {
 
         {/* DB INFO (GLASS) */}
         <AnimatePresence>
-          {dbInfo.contenido && (
+          {dbInfo.titulo && (
             <Motion.div
               initial={{ opacity: 0 }}
               whileInView={{ opacity: 1 }}
@@ -481,8 +503,32 @@ function SecurityTipsPage() {
                   {t("security.db_alert", "Aviso de Último Minuto")}
                 </h4>
                 <p className="text-lg font-bold italic text-jw-navy">
-                  "{dbInfo.contenido}"
+                  "{dbInfo.titulo}"
                 </p>
+                {/* HTML ya saneado en el servidor (internal/markdown) */}
+                {dbInfo.descripcion_html ? (
+                  <div
+                    className="mt-2 text-sm text-jw-navy/80 space-y-2 [&_a]:underline [&_ul]:list-disc [&_ol]:list-decimal [&_ul]:pl-5 [&_ol]:pl-5 [&_h1]:font-black [&_h2]:font-black [&_h3]:font-bold"
+                    dangerouslySetInnerHTML={{ __html: dbInfo.descripcion_html }}
+                  />
+                ) : (
+                  dbInfo.descripcion_larga && (
+                    <p className="mt-2 text-sm text-jw-navy/80 whitespace-pre-line">
+                      {dbInfo.descripcion_larga}
+                    </p>
+                  )
+                )}
+                <button
+                  type="button"
+                  onClick={confirmarLectura}
+                  disabled={confirmado}
+                  className="mt-4 inline-flex items-center gap-2 rounded-full bg-jw-navy px-4 py-2 text-xs font-black uppercase tracking-widest text-white disabled:opacity-60"
+                >
+                  <CheckCircle size={14} />
+                  {confirmado
+                    ? t("security.db_confirmed", "Lectura confirmada")
+                    : t("security.db_confirm", "Entendido")}
+                </button>
               </GlassContainer>
             </Motion.div>
           )}